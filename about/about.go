@@ -0,0 +1,156 @@
+// Copyright 2021 Harran Ali <harran.m@gmail.com>. All rights reserved.
+// Use of this source code is governed by MIT-style
+// license that can be found in the LICENSE file.
+
+package about
+
+import (
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/gocondor/core/database"
+	corerouting "github.com/gocondor/core/routing"
+	"github.com/gocondor/gocondor/commands"
+	"github.com/gocondor/gocondor/config"
+	"github.com/gocondor/gocondor/http/routing"
+	"github.com/gocondor/gocondor/listeners"
+	"github.com/gocondor/gocondor/modules"
+)
+
+// Summary describes the configuration of the running app
+type Summary struct {
+	Name        string          `json:"name"`
+	Mode        string          `json:"mode"`
+	GoVersion   string          `json:"goVersion"`
+	HTTP        string          `json:"http"`
+	HTTPS       bool            `json:"https"`
+	Database    string          `json:"database"`
+	Features    map[string]bool `json:"features"`
+	Routes      int             `json:"routes"`
+	Middlewares []string        `json:"middlewares"`
+	Modules     []string        `json:"modules"`
+	Listeners   []string        `json:"listeners"`
+	StartedAt   time.Time       `json:"startedAt"`
+}
+
+var startedAt = time.Now()
+
+// Info collects the summary of the app, the database is pinged so its status is current,
+// the app exposes it as app.Info()
+func Info() Summary {
+	features := config.Features
+	summary := Summary{
+		Name:      os.Getenv("APP_NAME"),
+		Mode:      os.Getenv("APP_MODE"),
+		GoVersion: runtime.Version(),
+		HTTP:      net.JoinHostPort(os.Getenv("APP_HTTP_HOST"), os.Getenv("APP_HTTP_PORT")),
+		HTTPS:     os.Getenv("APP_HTTPS_ON") == "true",
+		Database:  "off",
+		Features: map[string]bool{
+			"database":       features.Database,
+			"cache":          features.Cache,
+			"grpc":           features.GRPC,
+			"sessions":       features.Sessions,
+			"authentication": features.Authentication,
+			"metrics":        os.Getenv("APP_METRICS_ON") == "true",
+			"pprof":          os.Getenv("APP_PPROF_ON") == "true",
+		},
+		Middlewares: []string{},
+		Modules:     []string{},
+		Listeners:   []string{},
+		StartedAt:   startedAt,
+	}
+
+	if features.Database {
+		summary.Database = databaseStatus()
+	}
+	summary.Routes = countRoutes()
+	for _, name := range strings.Split(os.Getenv("APP_MIDDLEWARES"), ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			summary.Middlewares = append(summary.Middlewares, name)
+		}
+	}
+	for _, m := range modules.Registered() {
+		summary.Modules = append(summary.Modules, m.Name())
+	}
+	for _, l := range listeners.All() {
+		summary.Listeners = append(summary.Listeners, fmt.Sprintf("%s %s %s", l.Name, l.Network, l.Addr))
+	}
+	return summary
+}
+
+// Print writes the summary as the boot banner
+func Print(w io.Writer, s Summary) {
+	var enabled []string
+	for _, name := range []string{"database", "cache", "grpc", "sessions", "authentication", "metrics", "pprof"} {
+		if s.Features[name] {
+			enabled = append(enabled, name)
+		}
+	}
+
+	fmt.Fprintf(w, "%s (%s, %s)\n", s.Name, s.Mode, s.GoVersion)
+	fmt.Fprintf(w, "  http:        %s\n", s.HTTP)
+	fmt.Fprintf(w, "  https:       %t\n", s.HTTPS)
+	fmt.Fprintf(w, "  database:    %s\n", s.Database)
+	fmt.Fprintf(w, "  routes:      %d\n", s.Routes)
+	fmt.Fprintf(w, "  features:    %s\n", list(enabled))
+	fmt.Fprintf(w, "  middlewares: %s\n", list(s.Middlewares))
+	fmt.Fprintf(w, "  modules:     %s\n", list(s.Modules))
+	fmt.Fprintf(w, "  listeners:   %s\n", list(s.Listeners))
+}
+
+// RegisterCommands registers the about command
+func RegisterCommands() {
+	commands.Register("about", commands.Command{
+		Description: "show the configuration summary printed on boot with APP_BANNER",
+		Run: func(args []string) error {
+			Print(os.Stdout, Info())
+			return nil
+		},
+	})
+}
+
+// countRoutes counts the routes of core's router and of its groups, and the routes declared
+// with http/routing, the groups' routes are counted without reading them with GetRoutes
+// as it joins their prefix to their paths again
+func countRoutes() int {
+	count := 0
+	if router := corerouting.Resolve(); router != nil {
+		count += len(router.GetRoutes())
+	}
+	if groups := corerouting.ResolveGroupsHolder(); groups != nil {
+		for _, group := range groups.GroupsRouters {
+			count += len(group.Routes)
+		}
+	}
+	return count + len(routing.Routes())
+}
+
+// databaseStatus returns the driver and whether it answers a ping
+func databaseStatus() string {
+	driver := os.Getenv("DB_DRIVER")
+	db := database.Resolve()
+	if db == nil {
+		return driver + ": not connected"
+	}
+	sqlDB, err := db.DB()
+	if err == nil {
+		err = sqlDB.Ping()
+	}
+	if err != nil {
+		return fmt.Sprintf("%s: %v", driver, err)
+	}
+	return driver + ": connected"
+}
+
+func list(items []string) string {
+	if len(items) == 0 {
+		return "none"
+	}
+	return strings.Join(items, ", ")
+}
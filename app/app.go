@@ -0,0 +1,146 @@
+// Copyright 2021 Harran Ali <harran.m@gmail.com>. All rights reserved.
+// Use of this source code is governed by MIT-style
+// license that can be found in the LICENSE file.
+
+package app
+
+import (
+	"net"
+	"net/http"
+	"os"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gocondor/core"
+	"github.com/gocondor/core/auth"
+	"github.com/gocondor/core/jwt"
+	"github.com/gocondor/core/middlewares"
+	corerouting "github.com/gocondor/core/routing"
+	"github.com/gocondor/core/sessions"
+	"github.com/gocondor/gocondor/about"
+	"github.com/gocondor/gocondor/http/routing"
+	"github.com/gocondor/gocondor/scrubber"
+)
+
+// App is core's app with the engine built by the skeleton, so the app can be
+// mounted in other servers and tested without binding ports
+type App struct {
+	*core.App
+
+	mu           sync.Mutex
+	integrations []integration
+	configure    []func(*gin.Engine)
+	hooks        hooks
+	shutdownOnce sync.Once
+	routesOnce   sync.Once
+	routes       []corerouting.Route
+	sessions     gin.HandlerFunc
+	servers      []*http.Server
+	connState    func(net.Conn, http.ConnState)
+	stopped      bool
+	mounts       []mount
+	sub          bool
+}
+
+// New initiates the app
+func New() *App {
+	return &App{App: core.New()}
+}
+
+// Engine builds the gin engine of the app without starting listeners, every engine of the app
+// is built by it so they all run the logger, the apps of Mount, the sessions, the integrations of
+// Integrate, the global middlewares attached to core's middlewares engine, the functions of
+// ConfigureEngine, then the routes of core's router and the ones declared with http/routing, in
+// this order, the apps of NewSub only get their integrations and the functions of ConfigureEngine
+func (a *App) Engine() *gin.Engine {
+	engine := gin.New()
+	trustProxies(engine)
+	if a.sub {
+		engine = a.IntegratePackages(a.integrationHandlers(), engine)
+		for _, configure := range a.configurers() {
+			configure(engine)
+		}
+		return engine
+	}
+
+	// the request logs and the recovered panics go through the scrubber, see SCRUB_FIELDS
+	logs := scrubber.Resolve()
+	engine.Use(gin.LoggerWithWriter(logs.Writer(gin.DefaultWriter)), gin.RecoveryWithWriter(logs.Writer(gin.DefaultErrorWriter)))
+	a.mountAll(engine)
+	if a.Features.Sessions {
+		engine.Use(a.sessionsMiddleware())
+	}
+	auth.New(sessions.Resolve(), jwt.Resolve())
+
+	engine = a.IntegratePackages(a.integrationHandlers(), engine)
+	engine = a.UseMiddlewares(middlewares.Resolve().GetMiddlewares(), engine)
+	for _, configure := range a.configurers() {
+		configure(engine)
+	}
+	engine = a.RegisterRoutes(a.allRoutes(), engine)
+	engine = a.RegisterRoutes(routing.CoreRoutes(), engine)
+	return engine
+}
+
+// ConfigureEngine registers a function customizing the gin engine before the routes are registered,
+// for the engine's settings the app doesn't wrap, e.g:
+//
+//	app.ConfigureEngine(func(engine *gin.Engine) {
+//		engine.MaxMultipartMemory = 32 << 20
+//		engine.LoadHTMLGlob("templates/**/*")
+//		engine.HTMLRender = menus.Render(engine.HTMLRender)
+//		engine.NoRoute(handlers.NotFound)
+//	})
+func (a *App) ConfigureEngine(configure func(engine *gin.Engine)) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.configure = append(a.configure, configure)
+}
+
+// configurers returns the functions of ConfigureEngine in the order they were registered
+func (a *App) configurers() []func(*gin.Engine) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return append([]func(*gin.Engine){}, a.configure...)
+}
+
+// Handler returns the app as an http.Handler, to mount it inside another server
+// or to serve it with httptest, e.g:
+//
+//	mux.Handle("/", http.StripPrefix("/app", app.Handler()))
+func (a *App) Handler() http.Handler {
+	return a.Engine()
+}
+
+// Info returns the summary of the app printed on boot with APP_BANNER
+func (a *App) Info() about.Summary {
+	return about.Info()
+}
+
+// allRoutes returns the routes of core's router and of its groups, they're read once as
+// the groups join their prefix to the paths of their routes each time they're read
+func (a *App) allRoutes() []corerouting.Route {
+	a.routesOnce.Do(func() {
+		a.routes = append(a.routes, corerouting.Resolve().GetRoutes()...)
+		a.routes = append(a.routes, corerouting.ResolveGroupsHolder().GetGroupsRoutes()...)
+	})
+	return a.routes
+}
+
+// sessionsMiddleware returns the sessions middleware of SESSION_DRIVER like core's Run does,
+// the store is created once so the engines of the app share the sessions
+func (a *App) sessionsMiddleware() gin.HandlerFunc {
+	if a.sessions != nil {
+		return a.sessions
+	}
+	ses := sessions.Resolve()
+	switch os.Getenv("SESSION_DRIVER") {
+	case "redis":
+		a.sessions = ses.InitiateRedistore("mysecret", "mysession")
+	case "cookie":
+		a.sessions = ses.InitiateCookieStore("mysecret", "mysession")
+	default:
+		a.sessions = ses.InitiateMemstoreStore("mysecret", "mysession")
+	}
+	return a.sessions
+}
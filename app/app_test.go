@@ -0,0 +1,293 @@
+// Copyright 2021 Harran Ali <harran.m@gmail.com>. All rights reserved.
+// Use of this source code is governed by MIT-style
+// license that can be found in the LICENSE file.
+
+package app_test
+
+import (
+	"context"
+	"crypto/tls"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gocondor/core"
+		"github.com/gocondor/core/routing"
+	"github.com/gocondor/gocondor/app"
+	"github.com/gocondor/gocondor/app/apptest"
+	"golang.org/x/net/http2"
+)
+
+func TestConfigureEngine(t *testing.T) {
+	a := apptest.New(nil, nil)
+	a.ConfigureEngine(func(engine *gin.Engine) {
+		engine.NoRoute(func(c *gin.Context) {
+			c.JSON(http.StatusNotFound, gin.H{"message": "no route"})
+		})
+	})
+
+	server := apptest.NewServer(a)
+	defer server.Close()
+
+	res := server.JSON(t, http.MethodGet, "/missing", nil)
+	res.AssertStatus(t, http.StatusNotFound)
+	res.AssertJSON(t, gin.H{"message": "no route"})
+}
+
+func TestTrustedProxies(t *testing.T) {
+	tests := []struct {
+		name    string
+		proxies string
+		want    string
+	}{
+		{"trusted", "127.0.0.1", "203.0.113.7"},
+		{"trusted cidr", "10.0.0.0/8,127.0.0.0/8", "203.0.113.7"},
+		{"untrusted", "10.0.0.1", "127.0.0.1"},
+		{"none", "", "127.0.0.1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := apptest.New(map[string]string{"APP_TRUSTED_PROXIES": tt.proxies, "APP_CLIENT_IP_HEADER": "X-Real-IP"}, nil)
+			a.ConfigureEngine(func(engine *gin.Engine) {
+				engine.GET("/ip", func(c *gin.Context) {
+					c.JSON(http.StatusOK, gin.H{"ip": c.ClientIP()})
+				})
+			})
+			server := apptest.NewServer(a)
+			defer server.Close()
+
+			req, _ := http.NewRequest(http.MethodGet, "/ip", nil)
+			req.Header.Set("X-Real-IP", "203.0.113.7")
+			req.Header.Set("X-Forwarded-For", "198.51.100.1")
+			res := server.Do(t, req)
+			res.AssertJSON(t, gin.H{"ip": tt.want})
+		})
+	}
+}
+
+func TestHooks(t *testing.T) {
+	var calls []string
+	a := app.New()
+	a.OnBoot(func() { calls = append(calls, "boot") })
+	a.BeforeRun(func(engine *gin.Engine) {
+		calls = append(calls, "before run")
+		engine.GET("/hooked", func(c *gin.Context) {
+			c.String(http.StatusOK, "hooked")
+		})
+	})
+	a.OnShutdown(func(ctx context.Context) error {
+		calls = append(calls, "shutdown")
+		return nil
+	})
+	a.SetEnv(map[string]string{"APP_SERVERLESS": "true", "APP_HTTP_HOST": "127.0.0.1"})
+	a.SetAppMode(gin.TestMode)
+	a.SetEnabledFeatures(&core.Features{})
+	a.Bootstrap()
+	port := freePort(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	errs := make(chan error, 1)
+	go func() {
+		errs <- a.RunWithContext(ctx, port)
+	}()
+
+	res := getWhenUp(t, http.DefaultClient, "http://127.0.0.1:"+port+"/hooked")
+	if res.StatusCode != http.StatusOK {
+		t.Errorf("GET /hooked = %d, want %d", res.StatusCode, http.StatusOK)
+	}
+	cancel()
+	if err := <-errs; err != nil {
+		t.Errorf("RunWithContext() = %v, want nil", err)
+	}
+	a.Shutdown(context.Background())
+
+	want := []string{"boot", "before run", "shutdown"}
+	if !reflect.DeepEqual(calls, want) {
+		t.Errorf("hooks ran %v, want %v", calls, want)
+	}
+}
+
+func TestMount(t *testing.T) {
+	a := apptest.New(nil, nil)
+	a.ConfigureEngine(func(engine *gin.Engine) {
+		engine.Use(func(c *gin.Context) {
+			c.Header("X-Parent", "on")
+		})
+		engine.GET("/home", func(c *gin.Context) {
+			c.JSON(http.StatusOK, gin.H{"app": "parent"})
+		})
+	})
+	admin := app.NewSub()
+	admin.ConfigureEngine(func(engine *gin.Engine) {
+		engine.Use(func(c *gin.Context) {
+			c.Header("X-Admin", "on")
+		})
+		engine.GET("/", func(c *gin.Context) {
+			c.JSON(http.StatusOK, gin.H{"app": "admin", "path": c.Request.URL.Path})
+		})
+		engine.GET("/stats/:id", func(c *gin.Context) {
+			c.JSON(http.StatusOK, gin.H{"app": "admin", "path": c.FullPath(), "id": c.Param("id")})
+		})
+	})
+	a.Mount("/admin", admin)
+
+	server := apptest.NewServer(a)
+	defer server.Close()
+
+	res := server.JSON(t, http.MethodGet, "/admin/stats/7", nil)
+	res.AssertStatus(t, http.StatusOK)
+	res.AssertJSON(t, gin.H{"app": "admin", "path": "/stats/:id", "id": "7"})
+	res.AssertHeader(t, "X-Admin", "on")
+	res.AssertHeader(t, "X-Parent", "")
+
+	res = server.JSON(t, http.MethodGet, "/admin", nil)
+	res.AssertJSON(t, gin.H{"app": "admin", "path": "/"})
+
+	res = server.JSON(t, http.MethodGet, "/home", nil)
+	res.AssertJSON(t, gin.H{"app": "parent"})
+	res.AssertHeader(t, "X-Parent", "on")
+	res.AssertHeader(t, "X-Admin", "")
+
+	res = server.JSON(t, http.MethodGet, "/admin/missing", nil)
+	res.AssertStatus(t, http.StatusNotFound)
+}
+
+func TestRunWithContext(t *testing.T) {
+	a := apptest.New(map[string]string{"APP_SERVERLESS": "true", "APP_HTTP_HOST": "127.0.0.1"}, nil)
+	routing.Resolve().Get("/run", func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"message": "running"})
+	})
+	port := freePort(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	errs := make(chan error, 1)
+	go func() {
+		errs <- a.RunWithContext(ctx, port)
+	}()
+
+	res := getWhenUp(t, http.DefaultClient, "http://127.0.0.1:"+port+"/run")
+	if res.StatusCode != http.StatusOK {
+		t.Errorf("GET /run = %d, want %d", res.StatusCode, http.StatusOK)
+	}
+
+	cancel()
+	select {
+	case err := <-errs:
+		if err != nil {
+			t.Errorf("RunWithContext() = %v, want nil", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("RunWithContext() didn't return once the context was canceled")
+	}
+	if _, err := http.Get("http://127.0.0.1:" + port + "/run"); err == nil {
+		t.Error("the server still answers once the context was canceled")
+	}
+}
+
+func TestRunOnUnixSocket(t *testing.T) {
+	socket := filepath.Join(t.TempDir(), "app.sock")
+	a := apptest.New(map[string]string{"APP_SERVERLESS": "true", "APP_LISTEN_SOCKET": socket, "APP_LISTEN_SOCKET_MODE": "0600"}, nil)
+	defer os.Unsetenv("APP_LISTEN_SOCKET")
+	routing.Resolve().Get("/socket", func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"message": "socket"})
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	errs := make(chan error, 1)
+	go func() {
+		errs <- a.RunWithContext(ctx, "0")
+	}()
+
+	client := &http.Client{Transport: &http.Transport{
+		DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+			return (&net.Dialer{}).DialContext(ctx, "unix", socket)
+		},
+	}}
+	res := getWhenUp(t, client, "http://app/socket")
+	if res.StatusCode != http.StatusOK {
+		t.Errorf("GET /socket = %d, want %d", res.StatusCode, http.StatusOK)
+	}
+	if info, err := os.Stat(socket); err != nil {
+		t.Error(err)
+	} else if info.Mode().Perm() != 0600 {
+		t.Errorf("socket mode = %v, want 0600", info.Mode().Perm())
+	}
+
+	cancel()
+	if err := <-errs; err != nil {
+		t.Errorf("RunWithContext() = %v, want nil", err)
+	}
+}
+
+func TestRunH2C(t *testing.T) {
+	a := apptest.New(map[string]string{"APP_SERVERLESS": "true", "APP_HTTP_HOST": "127.0.0.1", "APP_H2C": "true"}, nil)
+	defer os.Unsetenv("APP_H2C")
+	routing.Resolve().Get("/h2c", func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"proto": c.Request.Proto})
+	})
+	port := freePort(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	errs := make(chan error, 1)
+	go func() {
+		errs <- a.RunWithContext(ctx, port)
+	}()
+
+	// an http/2 client without tls
+	client := &http.Client{Transport: &http2.Transport{
+		AllowHTTP: true,
+		DialTLS: func(network, addr string, _ *tls.Config) (net.Conn, error) {
+			return net.Dial(network, addr)
+		},
+	}}
+	res := getWhenUp(t, client, "http://127.0.0.1:"+port+"/h2c")
+	if res.ProtoMajor != 2 {
+		t.Errorf("GET /h2c served over %s, want HTTP/2.0", res.Proto)
+	}
+	res = getWhenUp(t, http.DefaultClient, "http://127.0.0.1:"+port+"/h2c")
+	if res.ProtoMajor != 1 {
+		t.Errorf("GET /h2c served over %s, want HTTP/1.1", res.Proto)
+	}
+
+	cancel()
+	if err := <-errs; err != nil {
+		t.Errorf("RunWithContext() = %v, want nil", err)
+	}
+}
+
+// freePort returns a local port nothing listens on
+func freePort(t *testing.T) string {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close()
+	return strconv.Itoa(listener.Addr().(*net.TCPAddr).Port)
+}
+
+// getWhenUp gets url once the server started by the test answers, the body is closed
+func getWhenUp(t *testing.T, client *http.Client, url string) *http.Response {
+	t.Helper()
+	var res *http.Response
+	var err error
+	for i := 0; i < 50; i++ {
+		if res, err = client.Get(url); err == nil {
+			res.Body.Close()
+			return res
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+	t.Fatalf("GET %s: %v", url, err)
+	return nil
+}
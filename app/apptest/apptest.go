@@ -0,0 +1,185 @@
+// Copyright 2021 Harran Ali <harran.m@gmail.com>. All rights reserved.
+// Use of this source code is governed by MIT-style
+// license that can be found in the LICENSE file.
+
+// Package apptest serves the app in the tests without binding its ports, and asserts on the responses
+package apptest
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gocondor/core"
+	"github.com/gocondor/gocondor/app"
+)
+
+// New bootstraps an app in gin's test mode with env set and the given features, the tests
+// then declare the routes and the middlewares they need and serve them with NewServer, e.g:
+//
+//	func TestPostsIndex(t *testing.T) {
+//		a := apptest.New(map[string]string{"APP_LOCALE": "en"}, nil)
+//		http.RegisterRoutes()
+//		server := apptest.NewServer(a)
+//		defer server.Close()
+//
+//		res := server.JSON(t, "GET", "/posts", nil)
+//		res.AssertStatus(t, 200)
+//		res.AssertJSON(t, gin.H{"data": []gin.H{}})
+//	}
+func New(env map[string]string, features *core.Features) *app.App {
+	if features == nil {
+		features = &core.Features{}
+	}
+	a := app.New()
+	a.SetEnv(env)
+	a.SetAppMode(gin.TestMode)
+	a.SetEnabledFeatures(features)
+	a.Bootstrap()
+	return a
+}
+
+// Server is an httptest server of the app's engine with the helpers sending the requests of the tests
+type Server struct {
+	*httptest.Server
+}
+
+// NewServer serves the app's engine on a local port without running Run, close it
+// at the end of the test
+func NewServer(a *app.App) *Server {
+	return &Server{httptest.NewServer(a.Handler())}
+}
+
+// Response is the response to a request of the tests with its body read
+type Response struct {
+	*http.Response
+	Body []byte
+}
+
+// JSON sends a request to the server with body encoded as json, a nil body sends none
+func (server *Server) JSON(t testing.TB, method string, path string, body interface{}) *Response {
+	t.Helper()
+	var reader io.Reader
+	if body != nil {
+		encoded, err := json.Marshal(body)
+		if err != nil {
+			t.Fatalf("encoding the body of %s %s: %v", method, path, err)
+		}
+		reader = bytes.NewReader(encoded)
+	}
+	req, err := http.NewRequest(method, server.URL+path, reader)
+	if err != nil {
+		t.Fatalf("%s %s: %v", method, path, err)
+	}
+	req.Header.Set("Accept", "application/json")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	return server.Do(t, req)
+}
+
+// Do sends a request built by the test to the server, a url without host is relative to the server's
+func (server *Server) Do(t testing.TB, req *http.Request) *Response {
+	t.Helper()
+	if req.URL.Host == "" {
+		full, err := http.NewRequest(req.Method, server.URL+req.URL.String(), req.Body)
+		if err != nil {
+			t.Fatalf("%s %s: %v", req.Method, req.URL, err)
+		}
+		full.Header = req.Header
+		req = full
+	}
+	res, err := server.Client().Do(req)
+	if err != nil {
+		t.Fatalf("%s %s: %v", req.Method, req.URL.Path, err)
+	}
+	defer res.Body.Close()
+	body, err := ioutil.ReadAll(res.Body)
+	if err != nil {
+		t.Fatalf("%s %s: reading the body: %v", req.Method, req.URL.Path, err)
+	}
+	return &Response{Response: res, Body: body}
+}
+
+// AssertStatus fails the test when the response doesn't have the status
+func (res *Response) AssertStatus(t testing.TB, status int) {
+	t.Helper()
+	if res.StatusCode != status {
+		t.Errorf("%s %s: status %d, want %d, body: %s", res.Request.Method, res.Request.URL.Path, res.StatusCode, status, res.Body)
+	}
+}
+
+// AssertHeader fails the test when the header of the response doesn't have the value
+func (res *Response) AssertHeader(t testing.TB, key string, value string) {
+	t.Helper()
+	if got := res.Header.Get(key); got != value {
+		t.Errorf("%s %s: header %s is %q, want %q", res.Request.Method, res.Request.URL.Path, key, got, value)
+	}
+}
+
+// AssertJSON fails the test when the json body doesn't contain want, the keys of the objects
+// missing from want are ignored so the assertions only name the fields they check, e.g:
+//
+//	res.AssertJSON(t, gin.H{"data": gin.H{"title": "hello"}})
+func (res *Response) AssertJSON(t testing.TB, want interface{}) {
+	t.Helper()
+	var got interface{}
+	if err := json.Unmarshal(res.Body, &got); err != nil {
+		t.Errorf("%s %s: the body isn't json: %v, body: %s", res.Request.Method, res.Request.URL.Path, err, res.Body)
+		return
+	}
+	// compare with the json form of want, so structs and maps compare like the decoded body
+	encoded, err := json.Marshal(want)
+	if err != nil {
+		t.Fatalf("encoding the expected json: %v", err)
+	}
+	var expected interface{}
+	json.Unmarshal(encoded, &expected)
+	if !containsJSON(got, expected) {
+		t.Errorf("%s %s: body %s doesn't contain %s", res.Request.Method, res.Request.URL.Path, res.Body, encoded)
+	}
+}
+
+// Decode decodes the json body into v
+func (res *Response) Decode(t testing.TB, v interface{}) {
+	t.Helper()
+	if err := json.Unmarshal(res.Body, v); err != nil {
+		t.Fatalf("%s %s: decoding the body: %v, body: %s", res.Request.Method, res.Request.URL.Path, err, res.Body)
+	}
+}
+
+// containsJSON reports whether the decoded json got has the values of want,
+// the objects may have more keys, the arrays must have the same length
+func containsJSON(got interface{}, want interface{}) bool {
+	switch want := want.(type) {
+	case map[string]interface{}:
+		object, ok := got.(map[string]interface{})
+		if !ok {
+			return false
+		}
+		for key, value := range want {
+			if field, ok := object[key]; !ok || !containsJSON(field, value) {
+				return false
+			}
+		}
+		return true
+	case []interface{}:
+		array, ok := got.([]interface{})
+		if !ok || len(array) != len(want) {
+			return false
+		}
+		for i := range want {
+			if !containsJSON(array[i], want[i]) {
+				return false
+			}
+		}
+		return true
+	}
+	return reflect.DeepEqual(got, want)
+}
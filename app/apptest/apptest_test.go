@@ -0,0 +1,72 @@
+// Copyright 2021 Harran Ali <harran.m@gmail.com>. All rights reserved.
+// Use of this source code is governed by MIT-style
+// license that can be found in the LICENSE file.
+
+package apptest
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gocondor/core/middlewares"
+	"github.com/gocondor/core/routing"
+)
+
+func TestServer(t *testing.T) {
+	a := New(nil, nil)
+	middlewares.Resolve().Attach(func(c *gin.Context) {
+		c.Header("X-Middleware", "on")
+		c.Next()
+	})
+	routing.Resolve().Post("/echo", func(c *gin.Context) {
+		var in map[string]interface{}
+		if err := c.ShouldBindJSON(&in); err != nil {
+			c.AbortWithStatusJSON(http.StatusUnprocessableEntity, gin.H{"message": err.Error()})
+			return
+		}
+		c.JSON(http.StatusCreated, gin.H{"data": in, "count": 2})
+	})
+	routing.Resolve().Group("/api").Get("/ping", func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"message": "pong"})
+	})
+
+	server := NewServer(a)
+	defer server.Close()
+
+	res := server.JSON(t, http.MethodPost, "/echo", gin.H{"title": "hello", "tags": []string{"a"}})
+	res.AssertStatus(t, http.StatusCreated)
+	res.AssertHeader(t, "X-Middleware", "on")
+	res.AssertJSON(t, gin.H{"data": gin.H{"title": "hello", "tags": []string{"a"}}})
+	res.AssertJSON(t, gin.H{"count": 2})
+
+	res = server.JSON(t, http.MethodGet, "/api/ping", nil)
+	res.AssertStatus(t, http.StatusOK)
+	res.AssertJSON(t, gin.H{"message": "pong"})
+
+	res = server.JSON(t, http.MethodGet, "/missing", nil)
+	res.AssertStatus(t, http.StatusNotFound)
+}
+
+func TestContainsJSON(t *testing.T) {
+	got := map[string]interface{}{
+		"data": map[string]interface{}{"id": 1.0, "title": "hello"},
+		"tags": []interface{}{"a", "b"},
+	}
+	tests := []struct {
+		name string
+		want interface{}
+		ok   bool
+	}{
+		{"subset", map[string]interface{}{"data": map[string]interface{}{"title": "hello"}}, true},
+		{"array", map[string]interface{}{"tags": []interface{}{"a", "b"}}, true},
+		{"short array", map[string]interface{}{"tags": []interface{}{"a"}}, false},
+		{"wrong value", map[string]interface{}{"data": map[string]interface{}{"id": 2.0}}, false},
+		{"missing key", map[string]interface{}{"meta": nil}, false},
+	}
+	for _, tt := range tests {
+		if ok := containsJSON(got, tt.want); ok != tt.ok {
+			t.Errorf("%s: containsJSON() = %t, want %t", tt.name, ok, tt.ok)
+		}
+	}
+}
@@ -0,0 +1,78 @@
+// Copyright 2021 Harran Ali <harran.m@gmail.com>. All rights reserved.
+// Use of this source code is governed by MIT-style
+// license that can be found in the LICENSE file.
+
+package app
+
+import (
+	"context"
+
+	"github.com/gin-gonic/gin"
+)
+
+// hooks are the functions run at the points of the app's lifetime, in the order they were registered
+type hooks struct {
+	boot      []func()
+	beforeRun []func(*gin.Engine)
+	shutdown  []func(ctx context.Context) error
+}
+
+// OnBoot registers a function run by Bootstrap once core's packages are initiated, e.g:
+//
+//	app.OnBoot(func() { metrics.RegisterCollector(queueCollector) })
+func (a *App) OnBoot(hook func()) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.hooks.boot = append(a.hooks.boot, hook)
+}
+
+// BeforeRun registers a function run by Run with the engine it built, once the routes are
+// registered and before the servers start
+func (a *App) BeforeRun(hook func(engine *gin.Engine)) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.hooks.beforeRun = append(a.hooks.beforeRun, hook)
+}
+
+// OnShutdown registers a function run once by Shutdown after the servers stopped, ctx is the
+// one given to Shutdown, the errors of the hooks don't stop the next ones from running
+func (a *App) OnShutdown(hook func(ctx context.Context) error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.hooks.shutdown = append(a.hooks.shutdown, hook)
+}
+
+// Bootstrap initiates core's packages, then runs the hooks of OnBoot
+func (a *App) Bootstrap() {
+	a.App.Bootstrap()
+	a.mu.Lock()
+	boot := append([]func(){}, a.hooks.boot...)
+	a.mu.Unlock()
+	for _, hook := range boot {
+		hook()
+	}
+}
+
+// runBeforeRun runs the hooks of BeforeRun with the engine of Run
+func (a *App) runBeforeRun(engine *gin.Engine) {
+	a.mu.Lock()
+	beforeRun := append([]func(*gin.Engine){}, a.hooks.beforeRun...)
+	a.mu.Unlock()
+	for _, hook := range beforeRun {
+		hook(engine)
+	}
+}
+
+// runOnShutdown runs the hooks of OnShutdown, it returns the first of their errors
+func (a *App) runOnShutdown(ctx context.Context) error {
+	a.mu.Lock()
+	shutdown := append([]func(context.Context) error{}, a.hooks.shutdown...)
+	a.mu.Unlock()
+	var first error
+	for _, hook := range shutdown {
+		if err := hook(ctx); err != nil && first == nil {
+			first = err
+		}
+	}
+	return first
+}
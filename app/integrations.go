@@ -0,0 +1,43 @@
+// Copyright 2021 Harran Ali <harran.m@gmail.com>. All rights reserved.
+// Use of this source code is governed by MIT-style
+// license that can be found in the LICENSE file.
+
+package app
+
+import (
+	"github.com/gin-gonic/gin"
+)
+
+// integration attaches a package to the gin context of the requests
+type integration struct {
+	name    string
+	handler gin.HandlerFunc
+}
+
+// Integrate attaches a package to the gin context of every request, integrating a name
+// again does nothing so the integrations are safe to declare from several places,
+// it reports whether the package was integrated, e.g:
+//
+//	app.Integrate(core.GORM, core.GORMIntegrator(database.Resolve()))
+func (a *App) Integrate(name string, handler gin.HandlerFunc) bool {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	for _, existing := range a.integrations {
+		if existing.name == name {
+			return false
+		}
+	}
+	a.integrations = append(a.integrations, integration{name: name, handler: handler})
+	return true
+}
+
+// integrationHandlers returns the handlers of the integrations in the order they were declared
+func (a *App) integrationHandlers() []gin.HandlerFunc {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	handlers := make([]gin.HandlerFunc, len(a.integrations))
+	for i, in := range a.integrations {
+		handlers[i] = in.handler
+	}
+	return handlers
+}
@@ -0,0 +1,17 @@
+// Copyright 2021 Harran Ali <harran.m@gmail.com>. All rights reserved.
+// Use of this source code is governed by MIT-style
+// license that can be found in the LICENSE file.
+
+package app
+
+import (
+	"context"
+
+	"github.com/gocondor/gocondor/lambda"
+)
+
+// RunLambda serves the app as an aws lambda function behind the api gateway or an application
+// load balancer until ctx is done, the engine is built on the first invocation, see lambda.Serve
+func (a *App) RunLambda(ctx context.Context) error {
+	return lambda.Serve(ctx, a.Handler)
+}
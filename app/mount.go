@@ -0,0 +1,68 @@
+// Copyright 2021 Harran Ali <harran.m@gmail.com>. All rights reserved.
+// Use of this source code is governed by MIT-style
+// license that can be found in the LICENSE file.
+
+package app
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gocondor/core"
+)
+
+// mount is a sub app served under a prefix
+type mount struct {
+	prefix string
+	sub    *App
+}
+
+// NewSub returns an app to mount in another one with Mount, it doesn't read the routes and the
+// middlewares declared for the whole process (core's router and middlewares, http/routing), its own
+// are declared with Integrate and ConfigureEngine, e.g:
+//
+//	admin := app.NewSub()
+//	admin.ConfigureEngine(func(engine *gin.Engine) {
+//		engine.Use(middlewares.AdminToken)
+//		engine.GET("/stats", handlers.StatsShow)
+//	})
+//	a.Mount("/admin", admin)
+func NewSub() *App {
+	return &App{App: core.New(), sub: true}
+}
+
+// Mount serves the sub app under prefix, e.g: /admin/stats is served by the /stats route of sub,
+// the requests run the logger of a then the middlewares of sub instead of the global middlewares
+// of a, and the routes of a can't be declared under prefix
+func (a *App) Mount(prefix string, sub *App) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.mounts = append(a.mounts, mount{prefix: "/" + strings.Trim(prefix, "/"), sub: sub})
+}
+
+// mountAll registers the routes serving the mounted apps, they're registered before the global
+// middlewares of a are attached to the engine so they don't run them
+func (a *App) mountAll(engine *gin.Engine) {
+	a.mu.Lock()
+	mounts := append([]mount{}, a.mounts...)
+	a.mu.Unlock()
+	for _, m := range mounts {
+		handler := m.handler(m.sub.Engine())
+		engine.Any(m.prefix, handler)
+		engine.Any(m.prefix+"/*path", handler)
+	}
+}
+
+// handler serves the requests with the sub app's engine, without the prefix in their path
+func (m mount) handler(engine http.Handler) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		req := new(http.Request)
+		*req = *c.Request
+		u := *c.Request.URL
+		u.Path = "/" + strings.TrimLeft(strings.TrimPrefix(u.Path, m.prefix), "/")
+		u.RawPath = ""
+		req.URL = &u
+		engine.ServeHTTP(c.Writer, req)
+	}
+}
@@ -0,0 +1,70 @@
+// Copyright 2021 Harran Ali <harran.m@gmail.com>. All rights reserved.
+// Use of this source code is governed by MIT-style
+// license that can be found in the LICENSE file.
+
+package app
+
+import (
+	"log"
+	"net"
+	"os"
+	"reflect"
+	"strings"
+	"unsafe"
+
+	"github.com/gin-gonic/gin"
+)
+
+// trustProxies makes c.ClientIP() read the client ip from the APP_CLIENT_IP_HEADER header, X-Forwarded-For
+// by default, when the request comes from one of the ips or cidrs of APP_TRUSTED_PROXIES, the headers
+// sent by anyone else are ignored so they can't be spoofed
+func trustProxies(engine *gin.Engine) {
+	engine.TrustedProxies = nil
+	for _, proxy := range strings.Split(os.Getenv("APP_TRUSTED_PROXIES"), ",") {
+		if proxy = strings.TrimSpace(proxy); proxy != "" {
+			engine.TrustedProxies = append(engine.TrustedProxies, proxy)
+		}
+	}
+	header := strings.TrimSpace(os.Getenv("APP_CLIENT_IP_HEADER"))
+	if header == "" {
+		header = "X-Forwarded-For"
+	}
+	engine.ForwardedByClientIP = true
+	engine.RemoteIPHeaders = []string{header}
+
+	// gin 1.7 only parses TrustedProxies in engine.Run, which isn't called as the app serves the
+	// engine with its own servers, so the parsed cidrs are set like Run does
+	cidrs, err := parseCIDRs(engine.TrustedProxies)
+	if err != nil {
+		log.Fatalf("invalid trusted proxy in APP_TRUSTED_PROXIES: %v", err)
+	}
+	field := reflect.ValueOf(engine).Elem().FieldByName("trustedCIDRs")
+	if !field.IsValid() {
+		return
+	}
+	reflect.NewAt(field.Type(), unsafe.Pointer(field.UnsafeAddr())).Elem().Set(reflect.ValueOf(cidrs))
+}
+
+// parseCIDRs parses a list of ips and cidrs, the ips are turned into the cidrs of a single ip
+func parseCIDRs(entries []string) ([]*net.IPNet, error) {
+	var cidrs []*net.IPNet
+	for _, entry := range entries {
+		if !strings.Contains(entry, "/") {
+			ip := net.ParseIP(entry)
+			if ip == nil {
+				return nil, &net.ParseError{Type: "IP address", Text: entry}
+			}
+			if ip.To4() != nil {
+				entry += "/32"
+			} else {
+				entry += "/128"
+			}
+		}
+		_, cidr, err := net.ParseCIDR(entry)
+		if err != nil {
+			return nil, err
+		}
+		cidrs = append(cidrs, cidr)
+	}
+	return cidrs, nil
+}
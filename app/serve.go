@@ -0,0 +1,291 @@
+// Copyright 2021 Harran Ali <harran.m@gmail.com>. All rights reserved.
+// Use of this source code is governed by MIT-style
+// license that can be found in the LICENSE file.
+
+package app
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"golang.org/x/crypto/acme/autocert"
+	"golang.org/x/net/http2"
+	"golang.org/x/net/http2/h2c"
+)
+
+// logs file path
+const logsFilePath = "logs/app.log"
+
+// Run serves the app on portNumber, or on the unix socket of APP_LISTEN_SOCKET, and on APP_HTTPS_PORT when APP_HTTPS_ON is true, the engine is built
+// once and shared by the http and https servers so they serve the same routes, sessions and state,
+// it returns once Shutdown stopped the servers, or with the error of a server that failed
+func (a *App) Run(portNumber string) error {
+	// fallback to port number to 80 if not set
+	if portNumber == "" {
+		portNumber = "80"
+	}
+	httpsOn, _ := strconv.ParseBool(os.Getenv("APP_HTTPS_ON"))
+	redirectToHTTPS, _ := strconv.ParseBool(os.Getenv("APP_REDIRECT_HTTP_TO_HTTPS"))
+	letsencryptOn, _ := strconv.ParseBool(os.Getenv("APP_HTTPS_USE_LETSENCRYPT"))
+	// fallback the https port to 443 if not set
+	httpsPort := os.Getenv("APP_HTTPS_PORT")
+	if httpsPort == "" {
+		httpsPort = "443"
+	}
+
+	// log the requests to the logs file too, the engines' logger scrubs them, serverless
+	// containers only log to stdout as their file system doesn't outlive them
+	if serverless, _ := strconv.ParseBool(os.Getenv("APP_SERVERLESS")); serverless {
+		gin.DefaultWriter = os.Stdout
+	} else {
+		logsFile, err := os.OpenFile(logsFilePath, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+		if err != nil {
+			return err
+		}
+		defer logsFile.Close()
+		gin.DefaultWriter = io.MultiWriter(logsFile, os.Stdout)
+	}
+
+	engine := a.Engine()
+	a.runBeforeRun(engine)
+	var certs *autocert.Manager
+	var serves []func() error
+	if httpsOn {
+		server := a.newServer(fmt.Sprintf("%s:%s", a.GetHTTPSHost(), httpsPort), engine)
+		if letsencryptOn {
+			certs = a.certManager()
+			server.TLSConfig = certs.TLSConfig()
+		}
+		serves = append(serves, func() error {
+			if certs != nil {
+				return server.ListenAndServeTLS("", "")
+			}
+			return server.ListenAndServeTLS(os.Getenv("APP_HTTPS_CERT_FILE_PATH"), os.Getenv("APP_HTTPS_KEY_FILE_PATH"))
+		})
+		if !a.track(server) {
+			return nil
+		}
+	}
+
+	var handler http.Handler
+	if httpsOn && redirectToHTTPS {
+		handler = redirectTo(a.GetHTTPSHost(), httpsPort)
+	} else {
+		handler = engine
+	}
+	// answer the http-01 challenges of let's encrypt on the http port
+	if certs != nil {
+		handler = certs.HTTPHandler(handler)
+	}
+	// serve http/2 without tls next to http/1 with APP_H2C, e.g: for the grpc clients behind a proxy
+	// terminating tls, the https server negotiates http/2 itself
+	if h2cOn, _ := strconv.ParseBool(os.Getenv("APP_H2C")); h2cOn {
+		handler = h2c.NewHandler(handler, &http2.Server{IdleTimeout: envDuration("APP_IDLE_TIMEOUT", 120*time.Second)})
+	}
+	server := a.newServer(fmt.Sprintf("%s:%s", a.GetHTTPHost(), portNumber), handler)
+	if !a.track(server) {
+		return nil
+	}
+	// serve on the unix socket of APP_LISTEN_SOCKET instead of the port when it's set
+	if socket := os.Getenv("APP_LISTEN_SOCKET"); socket != "" {
+		listener, err := listenUnix(socket)
+		if err != nil {
+			a.Shutdown(context.Background())
+			return err
+		}
+		serves = append(serves, func() error {
+			return server.Serve(listener)
+		})
+	} else {
+		serves = append(serves, server.ListenAndServe)
+	}
+
+	errs := make(chan error, len(serves))
+	for _, serve := range serves {
+		go func(serve func() error) {
+			errs <- serve()
+		}(serve)
+	}
+	for range serves {
+		if err := <-errs; err != nil && err != http.ErrServerClosed {
+			a.Shutdown(context.Background())
+			return err
+		}
+	}
+	return nil
+}
+
+// RunWithContext serves the app like Run until ctx is done, then shuts the servers down, the
+// requests being served are drained for up to APP_SHUTDOWN_TIMEOUT, e.g:
+//
+//	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+//	defer stop()
+//	err := app.RunWithContext(ctx, "8000")
+func (a *App) RunWithContext(ctx context.Context, portNumber string) error {
+	done := make(chan struct{})
+	stopped := make(chan error, 1)
+	go func() {
+		select {
+		case <-ctx.Done():
+			drainCtx, cancel := context.WithTimeout(context.Background(), envDuration("APP_SHUTDOWN_TIMEOUT", 30*time.Second))
+			defer cancel()
+			stopped <- a.Shutdown(drainCtx)
+		case <-done:
+			stopped <- nil
+		}
+	}()
+
+	err := a.Run(portNumber)
+	close(done)
+	// the servers return as soon as the shutdown starts, wait for the drain
+	if shutdownErr := <-stopped; err == nil {
+		err = shutdownErr
+	}
+	return err
+}
+
+// Shutdown stops the servers of Run, the requests being served are drained until ctx is done,
+// then the hooks of OnShutdown are run, once even when Shutdown is called again
+func (a *App) Shutdown(ctx context.Context) error {
+	a.mu.Lock()
+	a.stopped = true
+	servers := a.servers
+	a.mu.Unlock()
+
+	var err error
+	for _, server := range servers {
+		if shutdownErr := server.Shutdown(ctx); shutdownErr != nil && err == nil {
+			err = shutdownErr
+		}
+	}
+	a.shutdownOnce.Do(func() {
+		if hooksErr := a.runOnShutdown(ctx); err == nil {
+			err = hooksErr
+		}
+	})
+	return err
+}
+
+// track adds a server to the ones stopped by Shutdown, it's false once the app is stopped
+func (a *App) track(server *http.Server) bool {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.stopped {
+		return false
+	}
+	a.servers = append(a.servers, server)
+	return true
+}
+
+// certManager returns the manager obtaining and renewing the certificates of let's encrypt,
+// only the hosts of APP_HTTPS_AUTOCERT_HOSTS get one, or APP_HTTPS_HOST when it's empty, and
+// they're cached in APP_HTTPS_AUTOCERT_CACHE_DIR so restarts don't request them again
+func (a *App) certManager() *autocert.Manager {
+	var hosts []string
+	for _, host := range strings.Split(os.Getenv("APP_HTTPS_AUTOCERT_HOSTS"), ",") {
+		if host = strings.TrimSpace(host); host != "" {
+			hosts = append(hosts, host)
+		}
+	}
+	if len(hosts) == 0 {
+		hosts = []string{a.GetHTTPSHost()}
+	}
+	cacheDir := os.Getenv("APP_HTTPS_AUTOCERT_CACHE_DIR")
+	if cacheDir == "" {
+		cacheDir = "ssl/autocert"
+	}
+	return &autocert.Manager{
+		Prompt:     autocert.AcceptTOS,
+		HostPolicy: autocert.HostWhitelist(hosts...),
+		Cache:      autocert.DirCache(cacheDir),
+	}
+}
+
+// listenUnix listens on the unix socket at path with the permissions of APP_LISTEN_SOCKET_MODE,
+// 0660 by default, the socket left behind by a previous run is removed first
+func listenUnix(path string) (net.Listener, error) {
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	listener, err := net.Listen("unix", path)
+	if err != nil {
+		return nil, err
+	}
+	mode, err := strconv.ParseUint(os.Getenv("APP_LISTEN_SOCKET_MODE"), 8, 32)
+	if err != nil {
+		mode = 0660
+	}
+	if err := os.Chmod(path, os.FileMode(mode)); err != nil {
+		listener.Close()
+		return nil, err
+	}
+	return listener, nil
+}
+
+// newServer returns a server of handler on addr with the timeouts of APP_READ_HEADER_TIMEOUT,
+// APP_READ_TIMEOUT, APP_WRITE_TIMEOUT and APP_IDLE_TIMEOUT, so slow clients can't hold
+// the connections open, the headers are limited to APP_MAX_HEADER_BYTES, APP_KEEP_ALIVES=false
+// closes the connections after each request, and the hook of SetConnStateHook sees their states
+func (a *App) newServer(addr string, handler http.Handler) *http.Server {
+	maxHeaderBytes, err := strconv.Atoi(os.Getenv("APP_MAX_HEADER_BYTES"))
+	if err != nil || maxHeaderBytes <= 0 {
+		maxHeaderBytes = http.DefaultMaxHeaderBytes
+	}
+	a.mu.Lock()
+	connState := a.connState
+	a.mu.Unlock()
+
+	server := &http.Server{
+		Addr:              addr,
+		Handler:           handler,
+		ReadHeaderTimeout: envDuration("APP_READ_HEADER_TIMEOUT", 10*time.Second),
+		ReadTimeout:       envDuration("APP_READ_TIMEOUT", 30*time.Second),
+		WriteTimeout:      envDuration("APP_WRITE_TIMEOUT", 60*time.Second),
+		IdleTimeout:       envDuration("APP_IDLE_TIMEOUT", 120*time.Second),
+		MaxHeaderBytes:    maxHeaderBytes,
+		ConnState:         connState,
+	}
+	if keepAlives, err := strconv.ParseBool(os.Getenv("APP_KEEP_ALIVES")); err == nil && !keepAlives {
+		server.SetKeepAlivesEnabled(false)
+	}
+	return server
+}
+
+// SetConnStateHook sets the function called when a connection of the servers of Run changes
+// its state, e.g: to export the connections metrics
+//
+//	app.SetConnStateHook(metrics.ConnState)
+func (a *App) SetConnStateHook(hook func(net.Conn, http.ConnState)) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.connState = hook
+}
+
+// envDuration returns the duration of the env key, e.g: 30s, or fallback when it's not set or invalid,
+// a zero duration turns the timeout off
+func envDuration(key string, fallback time.Duration) time.Duration {
+	duration, err := time.ParseDuration(os.Getenv(key))
+	if err != nil || duration < 0 {
+		return fallback
+	}
+	return duration
+}
+
+// redirectTo redirects the requests to their https url on host and port,
+// the port is left out of the url when it's the default one
+func redirectTo(host string, port string) http.Handler {
+	if port != "443" {
+		host = host + ":" + port
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "https://"+host+r.URL.RequestURI(), http.StatusMovedPermanently)
+	})
+}
@@ -0,0 +1,153 @@
+// Copyright 2021 Harran Ali <harran.m@gmail.com>. All rights reserved.
+// Use of this source code is governed by MIT-style
+// license that can be found in the LICENSE file.
+
+package archive
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// ErrVerification is returned when the archived rows don't match the rows read
+var ErrVerification = errors.New("archived rows don't match the source rows")
+
+// Policy moves the rows of a table older than a duration to its archive, e.g:
+//
+//	archive.Register(archive.Policy{Table: "orders", OlderThan: 365 * 24 * time.Hour})
+type Policy struct {
+	Table string
+	// Column is the timestamp compared to OlderThan, defaults to created_at
+	Column    string
+	OlderThan time.Duration
+	BatchSize int
+	// Store receives the batches, defaults to the table's archive table
+	Store Store
+}
+
+// Store keeps archived rows
+type Store interface {
+	// Archive stores the rows with the given ids of the table and returns how many it stored,
+	// it runs in the transaction deleting them from the table
+	Archive(tx *gorm.DB, table string, ids []uint) (int64, error)
+}
+
+// Preparer is implemented by the stores needing to set up before archiving a table
+type Preparer interface {
+	Prepare(db *gorm.DB, table string) error
+}
+
+// Finisher is implemented by the stores keeping the rows outside the database, Finish is called
+// once the transaction of the batch committed, or rolled back, to keep or drop what Archive wrote
+type Finisher interface {
+	Finish(table string, ids []uint, committed bool) error
+}
+
+// defaultBatchSize is used when a policy has no batch size
+const defaultBatchSize = 500
+
+var mu sync.Mutex
+var policies []Policy
+
+// Register registers an archiving policy
+func Register(p Policy) {
+	mu.Lock()
+	defer mu.Unlock()
+	if p.Column == "" {
+		p.Column = "created_at"
+	}
+	if p.BatchSize <= 0 {
+		p.BatchSize = defaultBatchSize
+	}
+	if p.Store == nil {
+		p.Store = TableStore{}
+	}
+	policies = append(policies, p)
+}
+
+// Run archives the rows matching the policies, it returns the archived rows by table
+func Run(db *gorm.DB) (map[string]int64, error) {
+	mu.Lock()
+	registered := make([]Policy, len(policies))
+	copy(registered, policies)
+	mu.Unlock()
+
+	archived := map[string]int64{}
+	for _, p := range registered {
+		rows, err := run(db, p)
+		archived[p.Table] += rows
+		if err != nil {
+			return archived, fmt.Errorf("archive %s: %w", p.Table, err)
+		}
+	}
+	return archived, nil
+}
+
+// run archives a policy's rows batch by batch, every batch is stored, verified and deleted
+// in one transaction so a failing batch leaves the rows in place
+func run(db *gorm.DB, p Policy) (int64, error) {
+	if preparer, ok := p.Store.(Preparer); ok {
+		if err := preparer.Prepare(db, p.Table); err != nil {
+			return 0, err
+		}
+	}
+
+	var total int64
+	for {
+		var ids []uint
+		err := db.Table(p.Table).Where(p.Column+" < ?", time.Now().Add(-p.OlderThan)).
+			Order("id").Limit(p.BatchSize).Pluck("id", &ids).Error
+		if err != nil || len(ids) == 0 {
+			return total, err
+		}
+
+		err = db.Transaction(func(tx *gorm.DB) error {
+			stored, err := p.Store.Archive(tx, p.Table, ids)
+			if err != nil {
+				return err
+			}
+			if stored != int64(len(ids)) {
+				return ErrVerification
+			}
+			deleted := tx.Exec("DELETE FROM "+p.Table+" WHERE id IN ?", ids)
+			if deleted.Error != nil {
+				return deleted.Error
+			}
+			if deleted.RowsAffected != stored {
+				return ErrVerification
+			}
+			return nil
+		})
+		if finisher, ok := p.Store.(Finisher); ok {
+			if finishErr := finisher.Finish(p.Table, ids, err == nil); finishErr != nil && err == nil {
+				err = finishErr
+			}
+		}
+		if err != nil {
+			return total, err
+		}
+		total += int64(len(ids))
+		if len(ids) < p.BatchSize {
+			return total, nil
+		}
+	}
+}
+
+// Schedule archives the rows matching the policies every interval
+func Schedule(db *gorm.DB, interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for range ticker.C {
+			if _, err := Run(db); err != nil {
+				log.Printf("archive: scheduled run failed: %v", err)
+			}
+		}
+	}()
+}
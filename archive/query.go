@@ -0,0 +1,49 @@
+// Copyright 2021 Harran Ali <harran.m@gmail.com>. All rights reserved.
+// Use of this source code is governed by MIT-style
+// license that can be found in the LICENSE file.
+
+package archive
+
+import (
+	"errors"
+
+	"gorm.io/gorm"
+)
+
+// Archived is a scope querying the archive table of a model, e.g:
+//
+//	db.Scopes(archive.Archived(&Order{})).Where("user_id = ?", id).Find(&orders)
+func Archived(model interface{}) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		stmt := &gorm.Statement{DB: db}
+		if err := stmt.Parse(model); err != nil {
+			db.AddError(err)
+			return db
+		}
+		return db.Table(Table(stmt.Schema.Table))
+	}
+}
+
+// First finds a record like db.First, falling back to the model's archive table
+// when the record isn't in the live table anymore, e.g:
+//
+//	err := archive.First(db, &order, id)
+func First(db *gorm.DB, dest interface{}, conds ...interface{}) error {
+	err := db.First(dest, conds...).Error
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return err
+	}
+	if !db.Migrator().HasTable(archiveOf(db, dest)) {
+		return err
+	}
+	return db.Scopes(Archived(dest)).First(dest, conds...).Error
+}
+
+// archiveOf returns the archive table of a model
+func archiveOf(db *gorm.DB, model interface{}) string {
+	stmt := &gorm.Statement{DB: db}
+	if err := stmt.Parse(model); err != nil {
+		return ""
+	}
+	return Table(stmt.Schema.Table)
+}
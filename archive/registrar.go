@@ -0,0 +1,12 @@
+// Copyright 2021 Harran Ali <harran.m@gmail.com>. All rights reserved.
+// Use of this source code is governed by MIT-style
+// license that can be found in the LICENSE file.
+
+package archive
+
+// RegisterPolicies registers the app's archiving policies
+func RegisterPolicies() {
+	// Register your archiving policies here, e.g:
+	// Register(Policy{Table: "orders", OlderThan: 365 * 24 * time.Hour})
+	// Register(Policy{Table: "events", OlderThan: 90 * 24 * time.Hour, Store: CSVStore{Dir: "storage/archive"}})
+}
@@ -0,0 +1,251 @@
+// Copyright 2021 Harran Ali <harran.m@gmail.com>. All rights reserved.
+// Use of this source code is governed by MIT-style
+// license that can be found in the LICENSE file.
+
+package archive
+
+import (
+	"database/sql"
+	"encoding/csv"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// TableStore copies the rows to an archive table with the same columns, named <table>_archive
+type TableStore struct{}
+
+// Table returns the archive table of a table
+func Table(table string) string {
+	return table + "_archive"
+}
+
+// Prepare creates the archive table with the same columns as the table, and adds the columns
+// the table gained since, it runs outside the batches' transactions as mysql commits them on
+// ddl statements
+func (TableStore) Prepare(db *gorm.DB, table string) error {
+	archive := Table(table)
+	if !db.Migrator().HasTable(archive) {
+		if err := createArchive(db, table, archive); err != nil {
+			return err
+		}
+	}
+
+	columns, err := db.Migrator().ColumnTypes(table)
+	if err != nil {
+		return err
+	}
+	archived, err := db.Migrator().ColumnTypes(archive)
+	if err != nil {
+		return err
+	}
+	has := make(map[string]bool, len(archived))
+	for _, column := range archived {
+		has[column.Name()] = true
+	}
+	for _, column := range columns {
+		if has[column.Name()] {
+			continue
+		}
+		definition, err := columnDefinition(db, table, column)
+		if err != nil {
+			return err
+		}
+		// the archived rows have no value for it, so it's added nullable
+		err = db.Exec("ALTER TABLE " + db.Statement.Quote(archive) + " ADD COLUMN " + db.Statement.Quote(column.Name()) + " " + definition).Error
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Archive implements Store, the columns are named so the archive table may have more columns
+// than the table, like the ones the table dropped
+func (TableStore) Archive(tx *gorm.DB, table string, ids []uint) (int64, error) {
+	archive := Table(table)
+	columns, err := tx.Migrator().ColumnTypes(table)
+	if err != nil {
+		return 0, err
+	}
+	names := make([]string, len(columns))
+	for i, column := range columns {
+		names[i] = tx.Statement.Quote(column.Name())
+	}
+	list := strings.Join(names, ", ")
+	err = tx.Exec("INSERT INTO "+tx.Statement.Quote(archive)+" ("+list+") SELECT "+list+" FROM "+tx.Statement.Quote(table)+" WHERE id IN ?", ids).Error
+	if err != nil {
+		return 0, err
+	}
+
+	var stored int64
+	err = tx.Table(archive).Where("id IN ?", ids).Count(&stored).Error
+	return stored, err
+}
+
+// createArchive creates the archive table with the columns of the table
+func createArchive(db *gorm.DB, table string, archive string) error {
+	switch db.Dialector.Name() {
+	case "mysql":
+		return db.Exec("CREATE TABLE IF NOT EXISTS " + archive + " LIKE " + table).Error
+	case "sqlite":
+		// copy the definition, CREATE TABLE AS drops the column types sqlite needs to scan times
+		var ddl string
+		if err := db.Raw("SELECT sql FROM sqlite_master WHERE type = 'table' AND name = ?", table).Scan(&ddl).Error; err != nil {
+			return err
+		}
+		rest, ok := afterTableName(ddl)
+		if !ok {
+			return fmt.Errorf("can't read the definition of %s", table)
+		}
+		return db.Exec("CREATE TABLE `" + archive + "` " + rest).Error
+	default:
+		return db.Exec("CREATE TABLE " + archive + " AS SELECT * FROM " + table + " WHERE 1 = 0").Error
+	}
+}
+
+// columnDefinition returns the sql type of a column of the table
+func columnDefinition(db *gorm.DB, table string, column gorm.ColumnType) (string, error) {
+	if db.Dialector.Name() == "mysql" {
+		// the mysql driver doesn't report the lengths, read the full type
+		var definition string
+		err := db.Raw("SELECT COLUMN_TYPE FROM information_schema.columns WHERE table_schema = DATABASE() AND table_name = ? AND column_name = ?",
+			table, column.Name()).Scan(&definition).Error
+		return definition, err
+	}
+	name := column.DatabaseTypeName()
+	if precision, scale, ok := column.DecimalSize(); ok && precision > 0 {
+		return fmt.Sprintf("%s(%d,%d)", name, precision, scale), nil
+	}
+	if length, ok := column.Length(); ok && length > 0 && !strings.Contains(name, "(") {
+		return fmt.Sprintf("%s(%d)", name, length), nil
+	}
+	return name, nil
+}
+
+// afterTableName returns what follows the table name of a CREATE TABLE statement
+func afterTableName(ddl string) (string, bool) {
+	const prefix = "CREATE TABLE "
+	if !strings.HasPrefix(strings.ToUpper(ddl), prefix) {
+		return "", false
+	}
+	rest := strings.TrimSpace(ddl[len(prefix):])
+	if rest == "" {
+		return "", false
+	}
+	closing := map[byte]byte{'`': '`', '"': '"', '[': ']'}
+	if end, quoted := closing[rest[0]]; quoted {
+		i := strings.IndexByte(rest[1:], end)
+		if i < 0 {
+			return "", false
+		}
+		return strings.TrimSpace(rest[i+2:]), true
+	}
+	i := strings.IndexAny(rest, " (")
+	if i < 0 {
+		return "", false
+	}
+	return strings.TrimSpace(rest[i:]), true
+}
+
+// CSVStore writes every batch to a csv file in Dir, ready to be shipped to object storage, the
+// file is written as .csv.partial and renamed once the batch is deleted from the table, or removed
+// when the batch is rolled back, the .partial files left by a crash may hold archived rows
+type CSVStore struct {
+	Dir string
+}
+
+// Archive implements Store
+func (s CSVStore) Archive(tx *gorm.DB, table string, ids []uint) (int64, error) {
+	if err := os.MkdirAll(s.Dir, 0750); err != nil {
+		return 0, err
+	}
+	rows, err := tx.Table(table).Where("id IN ?", ids).Order("id").Rows()
+	if err != nil {
+		return 0, err
+	}
+	defer rows.Close()
+
+	path := s.partial(table, ids)
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0640)
+	if err != nil {
+		return 0, err
+	}
+	written, err := writeCSV(f, rows)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(path)
+		return 0, err
+	}
+
+	// read the file back to verify what reached the disk
+	f, err = os.Open(path)
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+	records, err := csv.NewReader(f).ReadAll()
+	if err != nil {
+		return 0, err
+	}
+	if int64(len(records)-1) != written {
+		return 0, ErrVerification
+	}
+	return written, nil
+}
+
+// Finish implements Finisher, it names the file of a committed batch <table>-<first id>-<time>.csv
+func (s CSVStore) Finish(table string, ids []uint, committed bool) error {
+	path := s.partial(table, ids)
+	if !committed {
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		return nil
+	}
+	return os.Rename(path, filepath.Join(s.Dir, fmt.Sprintf("%s-%d-%d.csv", table, ids[0], time.Now().UnixNano())))
+}
+
+// partial returns the path of the file of a batch until it's committed
+func (s CSVStore) partial(table string, ids []uint) string {
+	return filepath.Join(s.Dir, fmt.Sprintf("%s-%d-%d.csv.partial", table, ids[0], ids[len(ids)-1]))
+}
+
+// writeCSV writes the header and the rows, returning the number of rows written
+func writeCSV(f *os.File, rows *sql.Rows) (int64, error) {
+	columns, err := rows.Columns()
+	if err != nil {
+		return 0, err
+	}
+	w := csv.NewWriter(f)
+	w.Write(columns)
+
+	var written int64
+	values := make([]sql.NullString, len(columns))
+	dest := make([]interface{}, len(columns))
+	for i := range values {
+		dest[i] = &values[i]
+	}
+	record := make([]string, len(columns))
+	for rows.Next() {
+		if err := rows.Scan(dest...); err != nil {
+			return written, err
+		}
+		for i, v := range values {
+			record[i] = v.String
+		}
+		w.Write(record)
+		written++
+	}
+	if err := rows.Err(); err != nil {
+		return written, err
+	}
+	w.Flush()
+	return written, w.Error()
+}
@@ -0,0 +1,92 @@
+// Copyright 2021 Harran Ali <harran.m@gmail.com>. All rights reserved.
+// Use of this source code is governed by MIT-style
+// license that can be found in the LICENSE file.
+
+package backup
+
+import (
+	"fmt"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// encryptedExt is the extension of encrypted backup files
+const encryptedExt = ".enc"
+
+// Create dumps the configured database into a new file in BACKUP_DIR and returns its path,
+// the file is encrypted if BACKUP_ENCRYPTION_KEY is set
+func Create() (string, error) {
+	dir := os.Getenv("BACKUP_DIR")
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return "", err
+	}
+
+	var dump []byte
+	var err error
+	switch driver() {
+	case "mysql":
+		dump, err = dumpMysql()
+	case "sqlite":
+		dump, err = dumpSqlite()
+	default:
+		return "", fmt.Errorf("backups are not supported for the database driver \"%s\"", driver())
+	}
+	if err != nil {
+		return "", err
+	}
+
+	path := filepath.Join(dir, fmt.Sprintf("backup-%s.%s", time.Now().UTC().Format("20060102-150405"), driver()))
+	if key := os.Getenv("BACKUP_ENCRYPTION_KEY"); key != "" {
+		if dump, err = encrypt(dump, key); err != nil {
+			return "", err
+		}
+		path += encryptedExt
+	}
+
+	return path, ioutil.WriteFile(path, dump, 0600)
+}
+
+// Restore replaces the configured database with the content of the backup file
+func Restore(path string) error {
+	dump, err := ioutil.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	if strings.HasSuffix(path, encryptedExt) {
+		if dump, err = decrypt(dump, os.Getenv("BACKUP_ENCRYPTION_KEY")); err != nil {
+			return err
+		}
+	}
+
+	switch driver() {
+	case "mysql":
+		return restoreMysql(dump)
+	case "sqlite":
+		return restoreSqlite(dump)
+	}
+	return fmt.Errorf("backups are not supported for the database driver \"%s\"", driver())
+}
+
+// Schedule creates a backup every interval in the background
+func Schedule(interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for range ticker.C {
+			if _, err := Create(); err != nil {
+				log.Printf("backup: scheduled backup failed: %v", err)
+			}
+		}
+	}()
+}
+
+// driver returns the configured database driver
+func driver() string {
+	return strings.TrimSpace(os.Getenv("DB_DRIVER"))
+}
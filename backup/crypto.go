@@ -0,0 +1,92 @@
+// Copyright 2021 Harran Ali <harran.m@gmail.com>. All rights reserved.
+// Use of this source code is governed by MIT-style
+// license that can be found in the LICENSE file.
+
+package backup
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"errors"
+	"io"
+
+	"golang.org/x/crypto/scrypt"
+)
+
+// keyedHeader starts the backups whose key is derived with scrypt, it's followed by the salt,
+// the backups without it were encrypted with the sha256 of the passphrase
+var keyedHeader = []byte("gocondor-backup-scrypt\n")
+
+// saltSize is the size of the random salt of the key
+const saltSize = 16
+
+// encrypt seals data with AES-256-GCM using a key derived from the passphrase with scrypt
+func encrypt(data []byte, passphrase string) ([]byte, error) {
+	salt := make([]byte, saltSize)
+	if _, err := io.ReadFull(rand.Reader, salt); err != nil {
+		return nil, err
+	}
+	key, err := deriveKey(passphrase, salt)
+	if err != nil {
+		return nil, err
+	}
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, err
+	}
+	sealed := append(append(append([]byte{}, keyedHeader...), salt...), nonce...)
+	return gcm.Seal(sealed, nonce, data, nil), nil
+}
+
+// decrypt opens data sealed by encrypt
+func decrypt(data []byte, passphrase string) ([]byte, error) {
+	if passphrase == "" {
+		return nil, errors.New("the backup is encrypted but BACKUP_ENCRYPTION_KEY is not set")
+	}
+
+	var key []byte
+	if bytes.HasPrefix(data, keyedHeader) && len(data) >= len(keyedHeader)+saltSize {
+		salt := data[len(keyedHeader) : len(keyedHeader)+saltSize]
+		data = data[len(keyedHeader)+saltSize:]
+		var err error
+		if key, err = deriveKey(passphrase, salt); err != nil {
+			return nil, err
+		}
+	} else {
+		// the backups made before the keys were derived with scrypt
+		sum := sha256.Sum256([]byte(passphrase))
+		key = sum[:]
+	}
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(data) < gcm.NonceSize() {
+		return nil, errors.New("the backup file is corrupted")
+	}
+	nonce, sealed := data[:gcm.NonceSize()], data[gcm.NonceSize():]
+	return gcm.Open(nil, nonce, sealed, nil)
+}
+
+// deriveKey derives the AES-256 key of the passphrase and the salt with scrypt
+func deriveKey(passphrase string, salt []byte) ([]byte, error) {
+	return scrypt.Key([]byte(passphrase), salt, 1<<15, 8, 1, 32)
+}
+
+// newGCM returns the AES-GCM cipher of the key
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
@@ -0,0 +1,84 @@
+// Copyright 2021 Harran Ali <harran.m@gmail.com>. All rights reserved.
+// Use of this source code is governed by MIT-style
+// license that can be found in the LICENSE file.
+
+package backup
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"testing"
+)
+
+func TestEncryptRoundTrip(t *testing.T) {
+	dump := []byte("INSERT INTO users VALUES (1, 'ada');")
+	sealed, err := encrypt(dump, "correct horse battery staple")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Contains(sealed, dump) {
+		t.Error("the encrypted backup contains the dump")
+	}
+	opened, err := decrypt(sealed, "correct horse battery staple")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(opened, dump) {
+		t.Errorf("decrypt() = %q, want %q", opened, dump)
+	}
+
+	// the salt and the nonce are random, the same dump never encrypts the same way
+	again, _ := encrypt(dump, "correct horse battery staple")
+	if bytes.Equal(sealed, again) {
+		t.Error("the dump was encrypted twice to the same bytes")
+	}
+}
+
+func TestDecryptRejects(t *testing.T) {
+	sealed, err := encrypt([]byte("dump"), "passphrase")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tampered := func(i int) []byte {
+		b := append([]byte{}, sealed...)
+		b[i] ^= 1
+		return b
+	}
+	tests := []struct {
+		name       string
+		data       []byte
+		passphrase string
+	}{
+		{"wrong passphrase", sealed, "wrong"},
+		{"no passphrase", sealed, ""},
+		{"tampered salt", tampered(len(keyedHeader)), "passphrase"},
+		{"tampered nonce", tampered(len(keyedHeader) + saltSize), "passphrase"},
+		{"tampered body", tampered(len(sealed) - 1), "passphrase"},
+		{"truncated", sealed[:len(keyedHeader)+saltSize+4], "passphrase"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if opened, err := decrypt(tt.data, tt.passphrase); err == nil {
+				t.Errorf("decrypt() = %q, want an error", opened)
+			}
+		})
+	}
+}
+
+func TestDecryptLegacy(t *testing.T) {
+	// the backups made before scrypt were sealed with the sha256 of the passphrase
+	sum := sha256.Sum256([]byte("passphrase"))
+	block, _ := aes.NewCipher(sum[:])
+	gcm, _ := cipher.NewGCM(block)
+	nonce := make([]byte, gcm.NonceSize())
+	rand.Read(nonce)
+	sealed := gcm.Seal(append([]byte{}, nonce...), nonce, []byte("dump"), nil)
+
+	opened, err := decrypt(sealed, "passphrase")
+	if err != nil || string(opened) != "dump" {
+		t.Errorf("decrypt() = %q, %v, want \"dump\", nil", opened, err)
+	}
+}
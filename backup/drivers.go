@@ -0,0 +1,92 @@
+// Copyright 2021 Harran Ali <harran.m@gmail.com>. All rights reserved.
+// Use of this source code is governed by MIT-style
+// license that can be found in the LICENSE file.
+
+package backup
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+
+	"github.com/gocondor/core/database"
+)
+
+// dumpMysql dumps the mysql database with mysqldump
+func dumpMysql() ([]byte, error) {
+	cmd := exec.Command("mysqldump", append(mysqlArgs(), "--single-transaction", "--routines", os.Getenv("MYSQL_DB_NAME"))...)
+	cmd.Env = mysqlEnv()
+
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	dump, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("mysqldump: %v: %s", err, stderr.String())
+	}
+	return dump, nil
+}
+
+// restoreMysql loads the dump into the mysql database with the mysql client
+func restoreMysql(dump []byte) error {
+	cmd := exec.Command("mysql", append(mysqlArgs(), os.Getenv("MYSQL_DB_NAME"))...)
+	cmd.Env = mysqlEnv()
+	cmd.Stdin = bytes.NewReader(dump)
+
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("mysql: %v: %s", err, out)
+	}
+	return nil
+}
+
+// mysqlArgs returns the connection arguments of the mysql tools
+func mysqlArgs() []string {
+	return []string{
+		"--host=" + os.Getenv("MYSQL_HOST"),
+		"--port=" + os.Getenv("MYSQL_PORT"),
+		"--user=" + os.Getenv("MYSQL_USERNAME"),
+	}
+}
+
+// mysqlEnv passes the password through the environment so it doesn't show up in the process list
+func mysqlEnv() []string {
+	return append(os.Environ(), "MYSQL_PWD="+os.Getenv("MYSQL_PASSWORD"))
+}
+
+// dumpSqlite copies the sqlite database with VACUUM INTO, so the copy is consistent while the app runs
+func dumpSqlite() ([]byte, error) {
+	tmp, err := ioutil.TempDir("", "backup")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(tmp)
+
+	path := filepath.Join(tmp, "db.sqlite")
+	if err := database.Resolve().Exec("VACUUM INTO ?", path).Error; err != nil {
+		return nil, err
+	}
+	return ioutil.ReadFile(path)
+}
+
+// restoreSqlite replaces the sqlite database file, the app must not be serving requests meanwhile,
+// the write-ahead log of the replaced database is emptied and removed so it isn't replayed on the backup
+func restoreSqlite(dump []byte) error {
+	path := os.Getenv("SQLITE_DB")
+	tmp := path + ".restore"
+	if err := ioutil.WriteFile(tmp, dump, 0600); err != nil {
+		return err
+	}
+	if err := database.Resolve().Exec("PRAGMA wal_checkpoint(TRUNCATE)").Error; err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	for _, suffix := range []string{"-wal", "-shm"} {
+		if err := os.Remove(path + suffix); err != nil && !os.IsNotExist(err) {
+			os.Remove(tmp)
+			return err
+		}
+	}
+	return os.Rename(tmp, path)
+}
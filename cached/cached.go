@@ -0,0 +1,123 @@
+// Copyright 2021 Harran Ali <harran.m@gmail.com>. All rights reserved.
+// Use of this source code is governed by MIT-style
+// license that can be found in the LICENSE file.
+
+package cached
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gocondor/gocondor/metrics"
+	"github.com/gocondor/gocondor/models"
+	"gorm.io/gorm"
+)
+
+var reads = metrics.NewCounter("cached_reads_total", "Number of cached repository reads by table and result.")
+
+// Repository caches the reads it runs, keyed by their sql, the cache of a table is invalidated
+// when a captured model of the table changes, e.g:
+//
+//	posts := cached.New(db, cached.NewMemoryStore(), time.Minute)
+//	err := posts.Find(&list, func(db *gorm.DB) *gorm.DB { return db.Where("user_id = ?", id) })
+type Repository struct {
+	db    *gorm.DB
+	store Store
+	ttl   time.Duration
+}
+
+// the stores the captured changes invalidate, a store is added once however many
+// repositories share it
+var storesMu sync.Mutex
+var stores = map[Store]bool{}
+var subscribe sync.Once
+
+// New creates a repository caching its reads in store for ttl, the store's stale reads are
+// invalidated on the captured changes, the repositories may share a store
+func New(db *gorm.DB, store Store, ttl time.Duration) *Repository {
+	subscribe.Do(func() {
+		models.OnChange(func(change models.Change) {
+			storesMu.Lock()
+			defer storesMu.Unlock()
+			for store := range stores {
+				Invalidate(store, change.Table)
+			}
+		})
+	})
+	storesMu.Lock()
+	stores[store] = true
+	storesMu.Unlock()
+	return &Repository{db: db, store: store, ttl: ttl}
+}
+
+// Find finds the records matching the scopes, from the cache when possible
+func (r *Repository) Find(dest interface{}, scopes ...func(*gorm.DB) *gorm.DB) error {
+	return r.read(dest, scopes, func(db *gorm.DB) *gorm.DB {
+		return db.Find(dest)
+	})
+}
+
+// First finds the first record matching the scopes, from the cache when possible
+func (r *Repository) First(dest interface{}, scopes ...func(*gorm.DB) *gorm.DB) error {
+	return r.read(dest, scopes, func(db *gorm.DB) *gorm.DB {
+		return db.First(dest)
+	})
+}
+
+// DB returns the database for the reads that mustn't be cached and the writes
+func (r *Repository) DB() *gorm.DB {
+	return r.db
+}
+
+func (r *Repository) read(dest interface{}, scopes []func(*gorm.DB) *gorm.DB, run func(*gorm.DB) *gorm.DB) error {
+	// build the sql without running it to key the cache
+	dry := run(r.db.Session(&gorm.Session{DryRun: true, NewDB: true}).Scopes(scopes...))
+	if dry.Error != nil {
+		return dry.Error
+	}
+	table := dry.Statement.Table
+	key := r.key(table, dry.Statement.SQL.String(), dry.Statement.Vars)
+
+	if cached, ok := r.store.Get(key); ok {
+		if err := json.Unmarshal(cached, dest); err == nil {
+			reads.Inc("table", table, "result", "hit")
+			return nil
+		}
+	}
+	reads.Inc("table", table, "result", "miss")
+
+	if err := run(r.db.Session(&gorm.Session{NewDB: true}).Scopes(scopes...)).Error; err != nil {
+		return err
+	}
+	if encoded, err := json.Marshal(dest); err == nil {
+		r.store.Set(key, encoded, r.ttl)
+	}
+	return nil
+}
+
+// key combines the table's generation with the query, bumping the generation orphans the old keys
+func (r *Repository) key(table string, sql string, vars []interface{}) string {
+	sum := sha256.Sum256([]byte(fmt.Sprintf("%s %v", sql, vars)))
+	return "cached:" + table + ":" + generation(r.store, table) + ":" + hex.EncodeToString(sum[:])
+}
+
+// Invalidate drops the cached reads of a table, the old entries expire with their ttl
+func Invalidate(store Store, table string) {
+	store.Set(generationKey(table), []byte(strconv.FormatInt(time.Now().UnixNano(), 10)), 0)
+}
+
+func generation(store Store, table string) string {
+	if gen, ok := store.Get(generationKey(table)); ok {
+		return string(gen)
+	}
+	return "0"
+}
+
+func generationKey(table string) string {
+	return "cached:generation:" + table
+}
@@ -0,0 +1,102 @@
+// Copyright 2021 Harran Ali <harran.m@gmail.com>. All rights reserved.
+// Use of this source code is governed by MIT-style
+// license that can be found in the LICENSE file.
+
+package cached
+
+import (
+	"container/list"
+	"sync"
+	"time"
+)
+
+// Store keeps the cached reads, a ttl of 0 means no expiry,
+// wrap the core cache engine to share the cache between instances,
+// the stores are map keys so implement them on pointers
+type Store interface {
+	Get(key string) ([]byte, bool)
+	Set(key string, value []byte, ttl time.Duration)
+}
+
+// MemoryMaxEntries is the default number of entries a MemoryStore keeps
+var MemoryMaxEntries = 10000
+
+// MemoryStore is a Store local to the process, it keeps up to its max entries
+// and drops the least recently used past it
+type MemoryStore struct {
+	mu      sync.Mutex
+	max     int
+	entries map[string]*list.Element
+	// the entries from the most to the least recently used
+	recent *list.List
+}
+
+type memoryEntry struct {
+	key       string
+	value     []byte
+	expiresAt time.Time
+}
+
+// NewMemoryStore creates a MemoryStore keeping up to MemoryMaxEntries entries
+func NewMemoryStore() *MemoryStore {
+	return NewMemoryStoreOf(MemoryMaxEntries)
+}
+
+// NewMemoryStoreOf creates a MemoryStore keeping up to max entries
+func NewMemoryStoreOf(max int) *MemoryStore {
+	return &MemoryStore{max: max, entries: map[string]*list.Element{}, recent: list.New()}
+}
+
+// Get implements Store
+func (s *MemoryStore) Get(key string) ([]byte, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	element, ok := s.entries[key]
+	if !ok {
+		return nil, false
+	}
+	entry := element.Value.(*memoryEntry)
+	if entry.expired(time.Now()) {
+		s.remove(element)
+		return nil, false
+	}
+	s.recent.MoveToFront(element)
+	return entry.value, true
+}
+
+// Set implements Store
+func (s *MemoryStore) Set(key string, value []byte, ttl time.Duration) {
+	entry := &memoryEntry{key: key, value: value}
+	if ttl > 0 {
+		entry.expiresAt = time.Now().Add(ttl)
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if element, ok := s.entries[key]; ok {
+		element.Value = entry
+		s.recent.MoveToFront(element)
+		return
+	}
+	s.entries[key] = s.recent.PushFront(entry)
+	// past the max the least recently used entry is dropped, the expired entries are
+	// dropped when they're read, or with the least recently used ones
+	if len(s.entries) > s.max {
+		s.remove(s.recent.Back())
+	}
+}
+
+// Len returns the number of entries kept, the expired ones count until they're dropped
+func (s *MemoryStore) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.entries)
+}
+
+func (s *MemoryStore) remove(element *list.Element) {
+	s.recent.Remove(element)
+	delete(s.entries, element.Value.(*memoryEntry).key)
+}
+
+func (e *memoryEntry) expired(now time.Time) bool {
+	return !e.expiresAt.IsZero() && now.After(e.expiresAt)
+}
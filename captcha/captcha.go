@@ -0,0 +1,142 @@
+// Copyright 2021 Harran Ali <harran.m@gmail.com>. All rights reserved.
+// Use of this source code is governed by MIT-style
+// license that can be found in the LICENSE file.
+
+package captcha
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Provider is a captcha service verifying the tokens solved by the clients
+type Provider struct {
+	Name      string
+	VerifyURL string
+	// Field is the form field the client widget puts the token in
+	Field string
+	// Scored providers answer with a score instead of a challenge, from 0 (bot) to 1 (human)
+	Scored bool
+}
+
+// ReCaptcha verifies google reCAPTCHA v2 tokens
+var ReCaptcha = Provider{Name: "recaptcha", VerifyURL: "https://www.google.com/recaptcha/api/siteverify", Field: "g-recaptcha-response"}
+
+// ReCaptchaV3 verifies google reCAPTCHA v3 tokens, they are scored
+var ReCaptchaV3 = Provider{Name: "recaptcha-v3", VerifyURL: "https://www.google.com/recaptcha/api/siteverify", Field: "g-recaptcha-response", Scored: true}
+
+// HCaptcha verifies hCaptcha tokens
+var HCaptcha = Provider{Name: "hcaptcha", VerifyURL: "https://hcaptcha.com/siteverify", Field: "h-captcha-response"}
+
+// Turnstile verifies cloudflare turnstile tokens
+var Turnstile = Provider{Name: "turnstile", VerifyURL: "https://challenges.cloudflare.com/turnstile/v0/siteverify", Field: "cf-turnstile-response"}
+
+// ErrFailed is returned when the token is missing, wasn't solved or scored too low
+var ErrFailed = errors.New("captcha verification failed")
+
+// Verifier checks the tokens of a provider
+type Verifier struct {
+	Provider Provider
+	Secret   string
+	// MinScore is the lowest score accepted from the scored providers
+	MinScore float64
+}
+
+// Result is the answer of the provider
+type Result struct {
+	Success    bool     `json:"success"`
+	Score      float64  `json:"score"`
+	Action     string   `json:"action"`
+	Hostname   string   `json:"hostname"`
+	ErrorCodes []string `json:"error-codes"`
+}
+
+var client = &http.Client{Timeout: 5 * time.Second}
+
+// Verify asks the provider whether the token was solved, ip is the client's ip and may be empty
+func Verify(ctx context.Context, p Provider, secret string, token string, ip string) (Result, error) {
+	form := url.Values{"secret": {secret}, "response": {token}}
+	if ip != "" {
+		form.Set("remoteip", ip)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.VerifyURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return Result{}, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return Result{}, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return Result{}, fmt.Errorf("%s verify: unexpected status %d", p.Name, resp.StatusCode)
+	}
+
+	var result Result
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return Result{}, fmt.Errorf("%s verify: %w", p.Name, err)
+	}
+	return result, nil
+}
+
+// Check verifies the token and returns ErrFailed when it wasn't solved, when it scored under
+// MinScore or when it was solved for another action, action may be empty to accept any
+func (v Verifier) Check(ctx context.Context, token string, ip string, action string) error {
+	if token == "" {
+		return fmt.Errorf("%w: no token", ErrFailed)
+	}
+	result, err := Verify(ctx, v.Provider, v.Secret, token, ip)
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case !result.Success:
+		return fmt.Errorf("%w: %s", ErrFailed, strings.Join(result.ErrorCodes, ", "))
+	case v.Provider.Scored && result.Score < v.MinScore:
+		return fmt.Errorf("%w: scored %.1f under %.1f", ErrFailed, result.Score, v.MinScore)
+	case action != "" && result.Action != "" && result.Action != action:
+		return fmt.Errorf("%w: solved for action %s", ErrFailed, result.Action)
+	}
+	return nil
+}
+
+// FromEnv returns the verifier of CAPTCHA_PROVIDER, ok is false while it's empty
+func FromEnv() (v Verifier, ok bool, err error) {
+	name := os.Getenv("CAPTCHA_PROVIDER")
+	if name == "" {
+		return Verifier{}, false, nil
+	}
+	provider, known := ProviderNamed(name)
+	if !known {
+		return Verifier{}, false, fmt.Errorf("unknown captcha provider \"%s\" in CAPTCHA_PROVIDER", name)
+	}
+
+	v = Verifier{Provider: provider, Secret: os.Getenv("CAPTCHA_SECRET"), MinScore: 0.5}
+	if minScore := os.Getenv("CAPTCHA_MIN_SCORE"); minScore != "" {
+		if v.MinScore, err = strconv.ParseFloat(minScore, 64); err != nil {
+			return Verifier{}, false, fmt.Errorf("CAPTCHA_MIN_SCORE: %w", err)
+		}
+	}
+	return v, true, nil
+}
+
+// ProviderNamed returns the provider with the name: recaptcha, recaptcha-v3, hcaptcha or turnstile
+func ProviderNamed(name string) (Provider, bool) {
+	for _, p := range []Provider{ReCaptcha, ReCaptchaV3, HCaptcha, Turnstile} {
+		if p.Name == name {
+			return p, true
+		}
+	}
+	return Provider{}, false
+}
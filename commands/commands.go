@@ -0,0 +1,47 @@
+// Copyright 2021 Harran Ali <harran.m@gmail.com>. All rights reserved.
+// Use of this source code is governed by MIT-style
+// license that can be found in the LICENSE file.
+
+package commands
+
+import (
+	"fmt"
+	"sort"
+)
+
+// Command is a cli command, run with: go run main.go [command] [args...]
+type Command struct {
+	Description string
+	Run         func(args []string) error
+}
+
+var commands = map[string]Command{}
+
+// Register adds the command under the given name
+func Register(name string, cmd Command) {
+	commands[name] = cmd
+}
+
+// Run runs the command with the given name
+func Run(name string, args []string) error {
+	cmd, ok := commands[name]
+	if !ok {
+		Usage()
+		return fmt.Errorf("unknown command \"%s\"", name)
+	}
+	return cmd.Run(args)
+}
+
+// Usage prints the available commands
+func Usage() {
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	fmt.Println("available commands:")
+	for _, name := range names {
+		fmt.Printf("  %-20s %s\n", name, commands[name].Description)
+	}
+}
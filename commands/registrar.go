@@ -0,0 +1,221 @@
+// Copyright 2021 Harran Ali <harran.m@gmail.com>. All rights reserved.
+// Use of this source code is governed by MIT-style
+// license that can be found in the LICENSE file.
+
+package commands
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/gocondor/core/database"
+	corerouting "github.com/gocondor/core/routing"
+	"github.com/joho/godotenv"
+
+	"github.com/gocondor/gocondor/archive"
+	"github.com/gocondor/gocondor/backup"
+	"github.com/gocondor/gocondor/config"
+	"github.com/gocondor/gocondor/http/routing"
+	"github.com/gocondor/gocondor/maintenance"
+	"github.com/gocondor/gocondor/reports"
+	"github.com/gocondor/gocondor/retention"
+	"github.com/gocondor/gocondor/settings"
+	"github.com/gocondor/gocondor/views"
+)
+
+// RegisterCommands registers the cli commands
+func RegisterCommands() {
+	Register("db:backup", Command{
+		Description: "dump the database to BACKUP_DIR",
+		Run: func(args []string) error {
+			path, err := backup.Create()
+			if err != nil {
+				return err
+			}
+			fmt.Printf("backup created: %s\n", path)
+			return nil
+		},
+	})
+
+	Register("db:restore", Command{
+		Description: "restore the database from the given backup file",
+		Run: func(args []string) error {
+			if len(args) != 1 {
+				return errors.New("usage: db:restore [backup-file]")
+			}
+			if err := backup.Restore(args[0]); err != nil {
+				return err
+			}
+			fmt.Printf("backup restored: %s\n", args[0])
+			return nil
+		},
+	})
+
+	Register("settings:get", Command{
+		Description: "show an app setting, or all of them without a key",
+		Run: func(args []string) error {
+			if len(args) == 0 {
+				all, err := settings.Resolve().App().All()
+				if err != nil {
+					return err
+				}
+				for key, value := range all {
+					fmt.Printf("%s=%s\n", key, value)
+				}
+				return nil
+			}
+			value, _, err := settings.Resolve().App().Get(args[0])
+			if err != nil {
+				return err
+			}
+			fmt.Println(value)
+			return nil
+		},
+	})
+
+	Register("settings:set", Command{
+		Description: "change an app setting",
+		Run: func(args []string) error {
+			if len(args) != 2 {
+				return errors.New("usage: settings:set [key] [value]")
+			}
+			return settings.Resolve().App().Set(args[0], args[1])
+		},
+	})
+
+	Register("config:lint", Command{
+		Description: "check .env for unknown, deprecated and insecure keys",
+		Run: func(args []string) error {
+			env, err := godotenv.Read(".env")
+			if err != nil {
+				return err
+			}
+			issues := config.Lint(env)
+			for _, issue := range issues {
+				fmt.Println(issue)
+			}
+			if config.HasErrors(issues) {
+				return errors.New("the configuration has errors")
+			}
+			if len(issues) == 0 {
+				fmt.Println("no issues found")
+			}
+			return nil
+		},
+	})
+
+	Register("report:generate", Command{
+		Description: "generate a report now and record the run",
+		Run: func(args []string) error {
+			if len(args) != 1 {
+				return errors.New("usage: report:generate [report-name]")
+			}
+			run, err := reports.Generate(database.Resolve(), args[0])
+			if err != nil {
+				return err
+			}
+			fmt.Printf("report generated: %s (%d rows)\n", run.File, run.Rows)
+			return nil
+		},
+	})
+
+	Register("retention:run", Command{
+		Description: "prune the rows matching the retention policies, --dry-run only counts them",
+		Run: func(args []string) error {
+			dryRun := len(args) > 0 && args[0] == "--dry-run"
+			results, err := retention.Run(database.Resolve(), dryRun)
+			for _, result := range results {
+				if dryRun {
+					fmt.Printf("%s: %d rows would be pruned\n", result.Policy, result.Rows)
+				} else {
+					fmt.Printf("%s: %d rows pruned\n", result.Policy, result.Rows)
+				}
+			}
+			return err
+		},
+	})
+
+	Register("archive:run", Command{
+		Description: "move the rows matching the archiving policies to their archives",
+		Run: func(args []string) error {
+			archived, err := archive.Run(database.Resolve())
+			for table, rows := range archived {
+				fmt.Printf("%s: %d rows archived\n", table, rows)
+			}
+			return err
+		},
+	})
+
+	Register("view:refresh", Command{
+		Description: "rebuild a derived table, or all of them without a name",
+		Run: func(args []string) error {
+			if len(args) == 0 {
+				return views.RefreshAll(database.Resolve())
+			}
+			return views.Refresh(database.Resolve(), args[0])
+		},
+	})
+
+	Register("maintenance:down", Command{
+		Description: "put the app in maintenance mode [--message=] [--retry=seconds] [--allow=ip,cidr] [--secret=]",
+		Run: func(args []string) error {
+			flags := flag.NewFlagSet("maintenance:down", flag.ContinueOnError)
+			message := flags.String("message", "", "the message of the 503 response")
+			retry := flags.Int("retry", 0, "the Retry-After header, in seconds")
+			allow := flags.String("allow", "", "the ips or cidrs still served, comma separated")
+			secret := flags.String("secret", "", "visiting /{secret} hands out a bypass cookie")
+			if err := flags.Parse(args); err != nil {
+				return err
+			}
+
+			state := maintenance.State{Message: *message, RetryAfter: *retry, Secret: *secret}
+			if *allow != "" {
+				state.Allow = strings.Split(*allow, ",")
+			}
+			if err := maintenance.Down(state); err != nil {
+				return err
+			}
+			fmt.Println("the app is down for maintenance")
+			if *secret != "" {
+				fmt.Printf("bypass it by visiting /%s\n", *secret)
+			}
+			return nil
+		},
+	})
+
+	Register("maintenance:up", Command{
+		Description: "bring the app out of maintenance mode",
+		Run: func(args []string) error {
+			if err := maintenance.Up(); err != nil {
+				return err
+			}
+			fmt.Println("the app is up")
+			return nil
+		},
+	})
+
+	Register("route:list", Command{
+		Description: "list the routes with their names and summaries",
+		Run: func(args []string) error {
+			w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+			fmt.Fprintln(w, "METHOD\tPATH\tNAME\tSUMMARY")
+			for _, route := range routing.Routes() {
+				fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", strings.ToUpper(route.Method), route.Path, route.RouteName(), route.Doc().Summary)
+			}
+			// the routes of core's router, the groups join their prefix to their paths once
+			// they're read, it's fine as the app isn't served after the command
+			core := corerouting.Resolve().GetRoutes()
+			core = append(core, corerouting.ResolveGroupsHolder().GetGroupsRoutes()...)
+			for _, route := range core {
+				fmt.Fprintf(w, "%s\t%s\t\t\n", strings.ToUpper(route.Method), route.Path)
+			}
+			return w.Flush()
+		},
+	})
+
+	// Register your commands here
+}
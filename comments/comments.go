@@ -0,0 +1,149 @@
+// Copyright 2021 Harran Ali <harran.m@gmail.com>. All rights reserved.
+// Use of this source code is governed by MIT-style
+// license that can be found in the LICENSE file.
+
+package comments
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gocondor/gocondor/models"
+	"gorm.io/gorm"
+)
+
+// EditWindow is how long authors may edit their comments, 0 never closes
+var EditWindow = 15 * time.Minute
+
+// ErrEditWindowClosed is returned when editing a comment after EditWindow
+var ErrEditWindowClosed = errors.New("the comment can no longer be edited")
+
+// ErrUnknownResource is returned for resources that weren't registered
+var ErrUnknownResource = errors.New("unknown commentable resource")
+
+// ErrParentMismatch is returned when replying to a comment of another record
+var ErrParentMismatch = errors.New("the parent comment belongs to another record")
+
+// Policy authorizes the requests on the comments of a resource, nil hooks allow everyone
+type Policy struct {
+	// View decides whether the request may read the comments of the record
+	View func(c *gin.Context, id uint) bool
+	// Comment decides whether the request may comment on the record
+	Comment func(c *gin.Context, id uint) bool
+	// Moderate decides whether the request may edit or delete the comment of someone else,
+	// authors may always edit their comments within EditWindow and delete them
+	Moderate func(c *gin.Context, comment *Comment) bool
+}
+
+// resource is a commentable model
+type resource struct {
+	model  reflect.Type
+	policy Policy
+}
+
+var mu sync.RWMutex
+var resources = map[string]resource{}
+
+// Register makes the records of model commentable under name in the routes, e.g:
+//
+//	comments.Register("posts", &models.Post{}, comments.Policy{View: canReadPost})
+func Register(name string, model interface{}, policy Policy) {
+	mu.Lock()
+	defer mu.Unlock()
+	resources[name] = resource{model: reflect.Indirect(reflect.ValueOf(model)).Type(), policy: policy}
+}
+
+// lookup returns the resource registered under name
+func lookup(name string) (resource, error) {
+	mu.RLock()
+	defer mu.RUnlock()
+	r, ok := resources[name]
+	if !ok {
+		return resource{}, fmt.Errorf("%w: %s", ErrUnknownResource, name)
+	}
+	return r, nil
+}
+
+// Add comments on a saved record, parentID is the comment replied to and may be nil
+func Add(db *gorm.DB, record interface{}, authorID uint, parentID *uint, body string) (*Comment, error) {
+	commentableType, commentableID, err := models.Subject(db, record)
+	if err != nil {
+		return nil, err
+	}
+
+	comment := &Comment{
+		CommentableType: commentableType,
+		CommentableID:   commentableID,
+		ParentID:        parentID,
+		AuthorID:        authorID,
+		Body:            body,
+	}
+	err = db.Transaction(func(tx *gorm.DB) error {
+		if parentID != nil {
+			var parent Comment
+			if err := tx.First(&parent, *parentID).Error; err != nil {
+				return err
+			}
+			if parent.CommentableType != commentableType || parent.CommentableID != commentableID {
+				return ErrParentMismatch
+			}
+			if err := tx.Model(&parent).UpdateColumn("replies", gorm.Expr("replies + 1")).Error; err != nil {
+				return err
+			}
+		}
+		return tx.Create(comment).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return comment, nil
+}
+
+// Edit changes the body of a comment, authors editing after EditWindow get ErrEditWindowClosed,
+// moderators aren't bound by the window
+func Edit(db *gorm.DB, comment *Comment, body string, moderator bool) error {
+	if !moderator && EditWindow > 0 && time.Since(comment.CreatedAt) > EditWindow {
+		return ErrEditWindowClosed
+	}
+
+	now := time.Now()
+	err := db.Model(comment).Updates(map[string]interface{}{"body": body, "edited_at": now}).Error
+	if err != nil {
+		return err
+	}
+	comment.Body = body
+	comment.EditedAt = &now
+	return nil
+}
+
+// Delete soft deletes a comment, its replies stay in the thread
+func Delete(db *gorm.DB, comment *Comment) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		if comment.ParentID != nil {
+			err := tx.Model(&Comment{}).Where("id = ? AND replies > 0", *comment.ParentID).
+				UpdateColumn("replies", gorm.Expr("replies - 1")).Error
+			if err != nil {
+				return err
+			}
+		}
+		return tx.Delete(comment).Error
+	})
+}
+
+// On is a query scope keeping the comments of the record, e.g:
+//
+//	db.Scopes(comments.On(&post), page.Scope).Order("id").Find(&list)
+func On(record interface{}) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		commentableType, commentableID, err := models.Subject(db, record)
+		if err != nil {
+			db.AddError(err)
+			return db
+		}
+		return db.Where("commentable_type = ? AND commentable_id = ?", commentableType, commentableID)
+	}
+}
@@ -0,0 +1,189 @@
+// Copyright 2021 Harran Ali <harran.m@gmail.com>. All rights reserved.
+// Use of this source code is governed by MIT-style
+// license that can be found in the LICENSE file.
+
+package comments
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gocondor/core/database"
+	"github.com/gocondor/gocondor/http/authentication"
+	"github.com/gocondor/gocondor/http/input"
+	"github.com/gocondor/gocondor/http/response"
+	"github.com/gocondor/gocondor/i18n"
+)
+
+// CommentInput is the body of a comment, ParentID replies to a comment
+type CommentInput struct {
+	Body     string `form:"body" json:"body" binding:"required,max=5000"`
+	ParentID *uint  `form:"parentId" json:"parentId"`
+}
+
+// EditInput is the new body of a comment
+type EditInput struct {
+	Body string `form:"body" json:"body" binding:"required,max=5000"`
+}
+
+// ListInput selects a page of the threads, or of the replies to ParentID
+type ListInput struct {
+	input.Page
+	ParentID *uint `form:"parentId" json:"parentId"`
+}
+
+// CommentsIndex lists a page of the comments on a record, oldest first
+func CommentsIndex(c *gin.Context) {
+	r, record, id, ok := target(c)
+	if !ok {
+		return
+	}
+	if r.policy.View != nil && !r.policy.View(c, id) {
+		response.AbortForbidden(c)
+		return
+	}
+	var in ListInput
+	if err := c.ShouldBindQuery(&in); err != nil {
+		response.AbortWithValidationError(c, err)
+		return
+	}
+
+	query := database.Resolve().Scopes(On(record), in.Page.Scope)
+	if in.ParentID != nil {
+		query = query.Where("parent_id = ?", *in.ParentID)
+	} else {
+		query = query.Where("parent_id IS NULL")
+	}
+	var list []Comment
+	if err := query.Order("id").Find(&list).Error; err != nil {
+		response.AbortWithDBError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": list,
+	})
+}
+
+// CommentsStore comments on a record
+func CommentsStore(c *gin.Context) {
+	r, record, id, ok := target(c)
+	if !ok {
+		return
+	}
+	userID, authenticated := authentication.UserID(c)
+	if !authenticated || (r.policy.Comment != nil && !r.policy.Comment(c, id)) {
+		response.AbortForbidden(c)
+		return
+	}
+	var in CommentInput
+	if err := c.ShouldBind(&in); err != nil {
+		response.AbortWithValidationError(c, err)
+		return
+	}
+
+	comment, err := Add(database.Resolve(), record, userID, in.ParentID, in.Body)
+	if errors.Is(err, ErrParentMismatch) {
+		c.AbortWithStatusJSON(http.StatusUnprocessableEntity, gin.H{
+			"message": err.Error(),
+		})
+		return
+	}
+	if err != nil {
+		response.AbortWithDBError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"data": comment,
+	})
+}
+
+// CommentsUpdate edits a comment, authors within EditWindow and moderators may
+func CommentsUpdate(c *gin.Context) {
+	comment, moderator, ok := ownComment(c)
+	if !ok {
+		return
+	}
+	var in EditInput
+	if err := c.ShouldBind(&in); err != nil {
+		response.AbortWithValidationError(c, err)
+		return
+	}
+
+	err := Edit(database.Resolve(), comment, in.Body, moderator)
+	if errors.Is(err, ErrEditWindowClosed) {
+		c.AbortWithStatusJSON(http.StatusForbidden, gin.H{
+			"message": i18n.T(i18n.Locale(c), "error.edit_window_closed", nil),
+		})
+		return
+	}
+	if err != nil {
+		response.AbortWithDBError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": comment,
+	})
+}
+
+// CommentsDestroy deletes a comment, its author and moderators may
+func CommentsDestroy(c *gin.Context) {
+	comment, _, ok := ownComment(c)
+	if !ok {
+		return
+	}
+	if err := Delete(database.Resolve(), comment); err != nil {
+		response.AbortWithDBError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "comment deleted successfully",
+	})
+}
+
+// target resolves the record the comments are about, the request is aborted when ok is false
+func target(c *gin.Context) (r resource, record interface{}, id uint, ok bool) {
+	r, err := lookup(c.Param("resource"))
+	if err != nil {
+		response.AbortNotFound(c)
+		return r, nil, 0, false
+	}
+	record, id, ok = input.RouteRecord(c, database.Resolve(), r.model)
+	return r, record, id, ok
+}
+
+// ownComment loads the comment of the route if the user wrote it or may moderate it,
+// the request is aborted when ok is false
+func ownComment(c *gin.Context) (comment *Comment, moderator bool, ok bool) {
+	r, record, _, ok := target(c)
+	if !ok {
+		return nil, false, false
+	}
+	userID, authenticated := authentication.UserID(c)
+	if !authenticated {
+		response.AbortForbidden(c)
+		return nil, false, false
+	}
+
+	id, err := strconv.ParseUint(c.Param("comment"), 10, 64)
+	if err != nil {
+		response.AbortNotFound(c)
+		return nil, false, false
+	}
+	comment = &Comment{}
+	if err := database.Resolve().Scopes(On(record)).First(comment, id).Error; err != nil {
+		response.AbortWithDBError(c, err)
+		return nil, false, false
+	}
+	moderator = r.policy.Moderate != nil && r.policy.Moderate(c, comment)
+	if comment.AuthorID != userID && !moderator {
+		response.AbortForbidden(c)
+		return nil, false, false
+	}
+	return comment, moderator, true
+}
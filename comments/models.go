@@ -0,0 +1,24 @@
+// Copyright 2021 Harran Ali <harran.m@gmail.com>. All rights reserved.
+// Use of this source code is governed by MIT-style
+// license that can be found in the LICENSE file.
+
+package comments
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Comment is a comment on a record of any model, identified by its table and id,
+// replies point to the comment they answer
+type Comment struct {
+	gorm.Model
+	CommentableType string     `gorm:"size:64;index:idx_comments_commentable" json:"commentableType"`
+	CommentableID   uint       `gorm:"index:idx_comments_commentable" json:"commentableId"`
+	ParentID        *uint      `gorm:"index" json:"parentId"`
+	AuthorID        uint       `gorm:"index" json:"authorId"`
+	Body            string     `gorm:"type:text" json:"body"`
+	Replies         int        `json:"replies"`
+	EditedAt        *time.Time `json:"editedAt"`
+}
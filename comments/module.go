@@ -0,0 +1,41 @@
+// Copyright 2021 Harran Ali <harran.m@gmail.com>. All rights reserved.
+// Use of this source code is governed by MIT-style
+// license that can be found in the LICENSE file.
+
+package comments
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/gocondor/core/routing"
+	"github.com/gocondor/gocondor/commands"
+)
+
+// Module plugs the comments into the app, register it in modules.RegisterModules
+// and the commentable models with Register
+type Module struct{}
+
+// Name identifies the module
+func (Module) Name() string {
+	return "comments"
+}
+
+// Routes registers the comments endpoints of the registered resources
+func (Module) Routes() {
+	router := routing.Resolve()
+
+	router.Get("/comments/:resource/:id", CommentsIndex)
+	router.Post("/comments/:resource/:id", CommentsStore)
+	router.Put("/comments/:resource/:id/:comment", CommentsUpdate)
+	router.Delete("/comments/:resource/:id/:comment", CommentsDestroy)
+}
+
+// Middlewares returns no middlewares
+func (Module) Middlewares() []gin.HandlerFunc { return nil }
+
+// Migrations returns the comments
+func (Module) Migrations() []interface{} {
+	return []interface{}{&Comment{}}
+}
+
+// Commands returns no commands
+func (Module) Commands() map[string]commands.Command { return nil }
@@ -0,0 +1,33 @@
+// Copyright 2021 Harran Ali <harran.m@gmail.com>. All rights reserved.
+// Use of this source code is governed by MIT-style
+// license that can be found in the LICENSE file.
+
+package config
+
+// EnvKeys are the keys the framework reads from .env, add the keys your app reads
+// so config:lint doesn't report them as unknown
+var EnvKeys = []string{
+	"APP_NAME", "APP_MODE", "APP_HTTP_HOST", "APP_HTTP_PORT", "APP_LISTEN_SOCKET", "APP_LISTEN_SOCKET_MODE", "APP_H2C", "APP_INTERNAL_ADDR", "APP_URL", "APP_TIMEZONE", "APP_LOCALE",
+	"APP_ADMIN_TOKEN", "APP_SERVERLESS", "APP_LAMBDA", "APP_BANNER", "APP_WATCH",
+	"APP_READ_HEADER_TIMEOUT", "APP_READ_TIMEOUT", "APP_WRITE_TIMEOUT", "APP_IDLE_TIMEOUT", "APP_SHUTDOWN_TIMEOUT", "APP_MAX_HEADER_BYTES", "APP_KEEP_ALIVES",
+	"SCRUB_FIELDS", "APP_METRICS_ON", "APP_PPROF_ON", "APP_PPROF_PREFIX",
+	"APP_MIDDLEWARES", "APP_MAX_REQUEST_BODY", "WARMUP_TIMEOUT_SECONDS", "MAINTENANCE_FILE", "MAINTENANCE_TEMPLATE",
+	"ERROR_PAGES_ON", "ERROR_PAGES_DIR",
+	"APP_TRUSTED_PROXIES", "APP_CLIENT_IP_HEADER", "QUOTA_API_KEYS",
+	"APP_HTTPS_ON", "APP_HTTPS_USE_LETSENCRYPT", "APP_REDIRECT_HTTP_TO_HTTPS", "APP_HTTPS_HOST", "APP_HTTPS_PORT",
+	"APP_HTTPS_CERT_FILE_PATH", "APP_HTTPS_KEY_FILE_PATH", "APP_HTTPS_AUTOCERT_HOSTS", "APP_HTTPS_AUTOCERT_CACHE_DIR",
+	"JWT_SECRET", "JWT_LIFESPAN_MINUTES", "JWT_REFRESH_TOKEN_SECRET", "JWT_REFRESH_TOKEN_LIFESPAN_HOURS",
+	"SESSION_DRIVER", "ID_GENERATOR", "ID_NODE",
+	"DB_DRIVER", "DB_READ_ONLY", "MYSQL_HOST", "MYSQL_DB_NAME", "MYSQL_PORT", "MYSQL_USERNAME",
+	"MYSQL_PASSWORD", "MYSQL_CHARSET", "SQLITE_DB", "BACKUP_DIR", "BACKUP_ENCRYPTION_KEY", "BACKUP_INTERVAL_HOURS",
+	"REPORTS_DIR", "MEDIA_DIR", "MEDIA_URL",
+	"MAIL_HOST", "MAIL_PORT", "MAIL_USERNAME", "MAIL_PASSWORD", "MAIL_FROM",
+	"CACHE_DRIVER", "REDIS_HOST", "REDIS_PORT", "REDIS_PASSWORD", "REDIS_DB_NAME",
+	"ANALYTICS_FILE", "ANALYTICS_BATCH_SIZE", "ANALYTICS_FLUSH_SECONDS",
+	"INBOUND_MAIL_TOKEN", "RETENTION_INTERVAL_MINUTES", "PRIVACY_ANONYMIZE_AFTER_DAYS", "PUBLISH_INTERVAL_SECONDS", "SHORT_LINKS_URL",
+	"AKISMET_KEY", "CAPTCHA_PROVIDER", "CAPTCHA_SECRET", "CAPTCHA_MIN_SCORE",
+}
+
+// DeprecatedEnvKeys maps keys that are no longer read to the keys replacing them,
+// an empty replacement means the key was dropped
+var DeprecatedEnvKeys = map[string]string{}
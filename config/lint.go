@@ -0,0 +1,148 @@
+// Copyright 2021 Harran Ali <harran.m@gmail.com>. All rights reserved.
+// Use of this source code is governed by MIT-style
+// license that can be found in the LICENSE file.
+
+package config
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Severity tells how serious a lint issue is
+type Severity string
+
+const (
+	// Warning issues should be looked at
+	Warning Severity = "warning"
+	// Error issues prevent the app from starting in release mode
+	Error Severity = "error"
+)
+
+// Issue is a problem found in the configuration
+type Issue struct {
+	Key      string
+	Severity Severity
+	Message  string
+}
+
+// String formats the issue for printing
+func (i Issue) String() string {
+	return fmt.Sprintf("%s: %s: %s", i.Severity, i.Key, i.Message)
+}
+
+// shippedSecrets are the secrets the project template ships with
+var shippedSecrets = map[string]string{
+	"JWT_SECRET":               "dkfTgonmgaAdlgkw",
+	"JWT_REFRESH_TOKEN_SECRET": "VbonmghslRdo",
+}
+
+// minSecretLength is the length under which secrets are considered weak
+const minSecretLength = 16
+
+// Lint checks the env for unknown, deprecated and insecure keys,
+// insecure values are errors in release mode and warnings otherwise
+func Lint(env map[string]string) []Issue {
+	var issues []Issue
+	release := strings.TrimSpace(env["APP_MODE"]) == "release"
+	insecure := Warning
+	if release {
+		insecure = Error
+	}
+
+	known := make(map[string]bool, len(EnvKeys))
+	for _, key := range EnvKeys {
+		known[key] = true
+	}
+
+	keys := make([]string, 0, len(env))
+	for key := range env {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		if replacement, ok := DeprecatedEnvKeys[key]; ok {
+			message := "is deprecated and no longer read"
+			if replacement != "" {
+				message = fmt.Sprintf("is deprecated, use %s instead", replacement)
+			}
+			issues = append(issues, Issue{key, Warning, message})
+			continue
+		}
+		if !known[key] {
+			issues = append(issues, Issue{key, Warning, "is not a known key, add it to config.EnvKeys if your app reads it"})
+		}
+	}
+
+	// mode
+	switch mode := strings.TrimSpace(env["APP_MODE"]); mode {
+	case "debug", "release", "test":
+		if release && strings.TrimSpace(env["DB_DRIVER"]) == "sqlite" {
+			issues = append(issues, Issue{"DB_DRIVER", Warning, "sqlite is used in release mode"})
+		}
+	default:
+		issues = append(issues, Issue{"APP_MODE", Error, fmt.Sprintf("\"%s\" is not one of debug, release or test", mode)})
+	}
+
+	// secrets
+	for _, key := range []string{"JWT_SECRET", "JWT_REFRESH_TOKEN_SECRET"} {
+		value := env[key]
+		switch {
+		case value == "":
+			issues = append(issues, Issue{key, insecure, "is empty"})
+		case value == shippedSecrets[key]:
+			issues = append(issues, Issue{key, insecure, "still holds the value shipped with the project template"})
+		case len(value) < minSecretLength:
+			issues = append(issues, Issue{key, insecure, fmt.Sprintf("is shorter than %d characters", minSecretLength)})
+		}
+	}
+	for _, key := range []string{"APP_ADMIN_TOKEN", "BACKUP_ENCRYPTION_KEY", "INBOUND_MAIL_TOKEN"} {
+		if value := env[key]; value != "" && len(value) < minSecretLength {
+			issues = append(issues, Issue{key, insecure, fmt.Sprintf("is shorter than %d characters", minSecretLength)})
+		}
+	}
+	if release && strings.TrimSpace(env["DB_DRIVER"]) == "mysql" && env["MYSQL_PASSWORD"] == "" {
+		issues = append(issues, Issue{"MYSQL_PASSWORD", Warning, "is empty in release mode"})
+	}
+
+	// snowflake ids
+	if value := strings.TrimSpace(env["ID_NODE"]); value != "" && strings.TrimSpace(env["ID_GENERATOR"]) == "snowflake" {
+		if node, err := strconv.ParseInt(value, 10, 64); err != nil || node < 0 || node > 1023 {
+			issues = append(issues, Issue{"ID_NODE", Error, fmt.Sprintf("\"%s\" is not a node number between 0 and 1023", value)})
+		}
+	}
+
+	// booleans
+	for _, key := range []string{"APP_HTTPS_ON", "APP_HTTPS_USE_LETSENCRYPT", "APP_REDIRECT_HTTP_TO_HTTPS", "APP_SERVERLESS", "APP_LAMBDA", "APP_BANNER", "APP_WATCH", "APP_METRICS_ON", "APP_PPROF_ON", "ERROR_PAGES_ON", "DB_READ_ONLY", "APP_KEEP_ALIVES", "APP_H2C"} {
+		if value, ok := env[key]; ok && value != "" {
+			if _, err := strconv.ParseBool(strings.TrimSpace(value)); err != nil {
+				issues = append(issues, Issue{key, Error, fmt.Sprintf("\"%s\" is not true or false", value)})
+			}
+		}
+	}
+
+	// durations
+	for _, key := range []string{"APP_READ_HEADER_TIMEOUT", "APP_READ_TIMEOUT", "APP_WRITE_TIMEOUT", "APP_IDLE_TIMEOUT", "APP_SHUTDOWN_TIMEOUT"} {
+		if value, ok := env[key]; ok && value != "" {
+			if _, err := time.ParseDuration(strings.TrimSpace(value)); err != nil {
+				issues = append(issues, Issue{key, Error, fmt.Sprintf("\"%s\" is not a duration, e.g: 30s", value)})
+			}
+		}
+	}
+
+	return issues
+}
+
+// HasErrors reports whether any of the issues is an error
+func HasErrors(issues []Issue) bool {
+	for _, issue := range issues {
+		if issue.Severity == Error {
+			return true
+		}
+	}
+	return false
+}
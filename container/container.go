@@ -0,0 +1,105 @@
+// Copyright 2021 Harran Ali <harran.m@gmail.com>. All rights reserved.
+// Use of this source code is governed by MIT-style
+// license that can be found in the LICENSE file.
+
+package container
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+)
+
+// services are keyed by their type, a factory builds a new value on every resolution
+type binding struct {
+	value   reflect.Value
+	factory reflect.Value
+}
+
+var mu sync.RWMutex
+var bindings = map[reflect.Type]binding{}
+
+var contextType = reflect.TypeOf((*gin.Context)(nil))
+
+// Singleton binds a value to its type, e.g: container.Singleton(database.Resolve())
+func Singleton(value interface{}) {
+	bind(reflect.TypeOf(value), binding{value: reflect.ValueOf(value)})
+}
+
+// SingletonAs binds a value to an interface it implements, e.g:
+//
+//	container.SingletonAs(smtpMailer, (*mail.Mailer)(nil))
+func SingletonAs(value interface{}, iface interface{}) {
+	typ := reflect.TypeOf(iface).Elem()
+	if !reflect.TypeOf(value).Implements(typ) {
+		panic(fmt.Sprintf("container: %T doesn't implement %s", value, typ))
+	}
+	bind(typ, binding{value: reflect.ValueOf(value)})
+}
+
+// Factory binds a func() T or a func(*gin.Context) T building a new T on every resolution,
+// request scoped services take the context, e.g:
+//
+//	container.Factory(func(c *gin.Context) *Cart { return loadCart(c) })
+func Factory(factory interface{}) {
+	fn := reflect.ValueOf(factory)
+	typ := fn.Type()
+	if typ.Kind() != reflect.Func || typ.NumOut() != 1 || typ.NumIn() > 1 || (typ.NumIn() == 1 && typ.In(0) != contextType) {
+		panic(fmt.Sprintf("container: factory must be a func() T or a func(*gin.Context) T, got %s", typ))
+	}
+	bind(typ.Out(0), binding{factory: fn})
+}
+
+func bind(typ reflect.Type, b binding) {
+	mu.Lock()
+	defer mu.Unlock()
+	bindings[typ] = b
+}
+
+// Resolve sets target, a pointer, to the service bound to the type it points to,
+// factories taking the request context can't be resolved here, use Get instead
+func Resolve(target interface{}) error {
+	return resolve(nil, target)
+}
+
+// Get sets target, a pointer, to the service bound to the type it points to and panics
+// when none is bound, like c.MustGet, e.g:
+//
+//	var db *gorm.DB
+//	container.Get(c, &db)
+func Get(c *gin.Context, target interface{}) {
+	if err := resolve(c, target); err != nil {
+		panic(err)
+	}
+}
+
+func resolve(c *gin.Context, target interface{}) error {
+	ptr := reflect.ValueOf(target)
+	if ptr.Kind() != reflect.Ptr || ptr.IsNil() {
+		return fmt.Errorf("container: target must be a non nil pointer, got %T", target)
+	}
+	typ := ptr.Elem().Type()
+
+	mu.RLock()
+	b, ok := bindings[typ]
+	mu.RUnlock()
+	if !ok {
+		return fmt.Errorf("container: nothing is bound to %s", typ)
+	}
+
+	if !b.factory.IsValid() {
+		ptr.Elem().Set(b.value)
+		return nil
+	}
+	var args []reflect.Value
+	if b.factory.Type().NumIn() == 1 {
+		if c == nil {
+			return fmt.Errorf("container: %s is request scoped, resolve it with Get", typ)
+		}
+		args = []reflect.Value{reflect.ValueOf(c)}
+	}
+	ptr.Elem().Set(b.factory.Call(args)[0])
+	return nil
+}
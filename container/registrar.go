@@ -0,0 +1,30 @@
+// Copyright 2021 Harran Ali <harran.m@gmail.com>. All rights reserved.
+// Use of this source code is governed by MIT-style
+// license that can be found in the LICENSE file.
+
+package container
+
+import (
+	"github.com/gocondor/core/cache"
+	"github.com/gocondor/core/database"
+	"github.com/gocondor/core/jwt"
+	"github.com/gocondor/core/sessions"
+	"github.com/gocondor/gocondor/config"
+)
+
+// RegisterServices binds the framework services and the app's own
+func RegisterServices() {
+	if config.Features.Database == true {
+		Singleton(database.Resolve())
+	}
+	if config.Features.Cache == true {
+		Singleton(cache.Resolve())
+	}
+	Singleton(jwt.Resolve())
+	if config.Features.Sessions == true {
+		Singleton(sessions.Resolve())
+	}
+
+	// Bind your services here, e.g:
+	// Singleton(payments.NewClient(os.Getenv("PAYMENTS_KEY")))
+}
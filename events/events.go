@@ -0,0 +1,74 @@
+// Copyright 2021 Harran Ali <harran.m@gmail.com>. All rights reserved.
+// Use of this source code is governed by MIT-style
+// license that can be found in the LICENSE file.
+
+package events
+
+import (
+	"fmt"
+	"log"
+	"sync"
+
+	"github.com/gocondor/gocondor/models"
+)
+
+// Listener handles the payload of an event
+type Listener func(payload interface{}) error
+
+var mu sync.RWMutex
+var listeners = map[string][]Listener{}
+
+// Listen registers a listener of an event, e.g:
+//
+//	events.Listen("user.registered", sendWelcomeEmail)
+func Listen(name string, listener Listener) {
+	mu.Lock()
+	defer mu.Unlock()
+	listeners[name] = append(listeners[name], listener)
+}
+
+func listenersOf(name string) []Listener {
+	mu.RLock()
+	defer mu.RUnlock()
+	ls := make([]Listener, len(listeners[name]))
+	copy(ls, listeners[name])
+	return ls
+}
+
+// Dispatch runs the listeners of an event in the order they were registered and stops
+// at the first error, e.g:
+//
+//	err := events.Dispatch("user.registered", user)
+func Dispatch(name string, payload interface{}) error {
+	for _, listener := range listenersOf(name) {
+		if err := listener(payload); err != nil {
+			return fmt.Errorf("event %s: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// DispatchAsync runs the listeners of an event in the background, each in its own goroutine,
+// the errors are logged
+func DispatchAsync(name string, payload interface{}) {
+	for _, listener := range listenersOf(name) {
+		go func(listener Listener) {
+			defer func() {
+				if r := recover(); r != nil {
+					log.Printf("event %s: listener panicked: %v", name, r)
+				}
+			}()
+			if err := listener(payload); err != nil {
+				log.Printf("event %s: %v", name, err)
+			}
+		}(listener)
+	}
+}
+
+// DispatchChanges dispatches the committed changes of the captured models as <table>.<op> events,
+// e.g: posts.create, in the background so the listeners don't hold the committing goroutine
+func DispatchChanges() {
+	models.OnChange(func(change models.Change) {
+		DispatchAsync(change.Table+"."+change.Op, change)
+	})
+}
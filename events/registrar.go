@@ -0,0 +1,11 @@
+// Copyright 2021 Harran Ali <harran.m@gmail.com>. All rights reserved.
+// Use of this source code is governed by MIT-style
+// license that can be found in the LICENSE file.
+
+package events
+
+// RegisterListeners registers the app's event listeners
+func RegisterListeners() {
+	// Register your listeners here, e.g:
+	// Listen("user.registered", sendWelcomeEmail)
+}
@@ -12,8 +12,10 @@ go 1.16
 
 require (
 	github.com/gin-gonic/gin v1.7.1
+	github.com/go-playground/validator/v10 v10.4.1
 	github.com/gocondor/core v1.4.4
 	github.com/joho/godotenv v1.3.0
+	github.com/json-iterator/go v1.1.9
 	golang.org/x/crypto v0.0.0-20210421170649-83a5a9bb288b
 	gorm.io/gorm v1.21.6
 )
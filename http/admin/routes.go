@@ -0,0 +1,18 @@
+// Copyright 2021 Harran Ali <harran.m@gmail.com>. All rights reserved.
+// Use of this source code is governed by MIT-style
+// license that can be found in the LICENSE file.
+
+package admin
+
+import (
+	"github.com/gocondor/core/routing"
+	"github.com/gocondor/gocondor/http/middlewares"
+)
+
+// RegisterAdminRoutes registers the admin endpoints, they require the X-Admin-Token header
+func RegisterAdminRoutes() {
+	router := routing.Resolve()
+
+	router.Get("/admin/settings", middlewares.AdminToken, SettingsIndex)
+	router.Put("/admin/settings/:key", middlewares.AdminToken, SettingsUpdate)
+}
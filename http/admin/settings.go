@@ -0,0 +1,54 @@
+// Copyright 2021 Harran Ali <harran.m@gmail.com>. All rights reserved.
+// Use of this source code is governed by MIT-style
+// license that can be found in the LICENSE file.
+
+package admin
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gocondor/gocondor/settings"
+)
+
+// SettingInput is the new value of a setting
+type SettingInput struct {
+	Value string `form:"value" json:"value"`
+}
+
+// SettingsIndex lists the app wide settings
+func SettingsIndex(c *gin.Context) {
+	all, err := settings.Resolve().App().All()
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{
+			"message": "something went wrong",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": all,
+	})
+}
+
+// SettingsUpdate changes the value of an app wide setting
+func SettingsUpdate(c *gin.Context) {
+	var input SettingInput
+	if err := c.ShouldBind(&input); err != nil {
+		c.AbortWithStatusJSON(http.StatusUnprocessableEntity, gin.H{
+			"message": err.Error(),
+		})
+		return
+	}
+
+	if err := settings.Resolve().App().Set(c.Param("key"), input.Value); err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{
+			"message": "something went wrong",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "setting updated successfully",
+	})
+}
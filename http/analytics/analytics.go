@@ -0,0 +1,112 @@
+// Copyright 2021 Harran Ali <harran.m@gmail.com>. All rights reserved.
+// Use of this source code is governed by MIT-style
+// license that can be found in the LICENSE file.
+
+package analytics
+
+import (
+	"log"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Event is a single request recorded by the analytics middleware
+type Event struct {
+	Time      time.Time `json:"time"`
+	Method    string    `json:"method"`
+	Route     string    `json:"route"`
+	Status    int       `json:"status"`
+	LatencyMS float64   `json:"latencyMs"`
+	Client    string    `json:"client"`
+}
+
+// Sink receives the collected events in batches, implement it to ship events
+// to your own storage (ClickHouse, BigQuery, ...)
+type Sink interface {
+	Write(events []Event) error
+}
+
+// Collector batches events and ships them to a sink in the background
+type Collector struct {
+	sink      Sink
+	events    chan Event
+	batchSize int
+	interval  time.Duration
+}
+
+var (
+	collector *Collector
+	once      sync.Once
+)
+
+// New creates the collector shipping batches of batchSize events to sink,
+// pending events are shipped at least every interval
+func New(sink Sink, batchSize int, interval time.Duration) *Collector {
+	collector = &Collector{
+		sink:      sink,
+		events:    make(chan Event, batchSize*10),
+		batchSize: batchSize,
+		interval:  interval,
+	}
+	go collector.run()
+
+	return collector
+}
+
+// Resolve returns the collector, if none was created with New
+// a collector writing to the file ANALYTICS_FILE is created
+func Resolve() *Collector {
+	once.Do(func() {
+		if collector != nil {
+			return
+		}
+		batchSize, err := strconv.Atoi(os.Getenv("ANALYTICS_BATCH_SIZE"))
+		if err != nil || batchSize <= 0 {
+			batchSize = 100
+		}
+		seconds, err := strconv.Atoi(os.Getenv("ANALYTICS_FLUSH_SECONDS"))
+		if err != nil || seconds <= 0 {
+			seconds = 5
+		}
+		New(&FileSink{Path: os.Getenv("ANALYTICS_FILE")}, batchSize, time.Duration(seconds)*time.Second)
+	})
+
+	return collector
+}
+
+// Collect queues the event for shipping, events are dropped if the queue is full
+// so a slow sink never holds up requests
+func (c *Collector) Collect(e Event) {
+	select {
+	case c.events <- e:
+	default:
+	}
+}
+
+// run ships the queued events in batches
+func (c *Collector) run() {
+	ticker := time.NewTicker(c.interval)
+	defer ticker.Stop()
+
+	batch := make([]Event, 0, c.batchSize)
+	for {
+		select {
+		case e := <-c.events:
+			batch = append(batch, e)
+			if len(batch) < c.batchSize {
+				continue
+			}
+		case <-ticker.C:
+			if len(batch) == 0 {
+				continue
+			}
+		}
+
+		if err := c.sink.Write(batch); err != nil {
+			log.Printf("analytics: failed to ship %d events: %v", len(batch), err)
+		}
+		batch = make([]Event, 0, c.batchSize)
+	}
+}
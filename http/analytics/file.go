@@ -0,0 +1,38 @@
+// Copyright 2021 Harran Ali <harran.m@gmail.com>. All rights reserved.
+// Use of this source code is governed by MIT-style
+// license that can be found in the LICENSE file.
+
+package analytics
+
+import (
+	"encoding/json"
+	"os"
+	"sync"
+)
+
+// FileSink appends the events as json lines to the file at Path
+type FileSink struct {
+	Path string
+	mu   sync.Mutex
+}
+
+// Write appends the events to the file
+func (s *FileSink) Write(events []Event) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	f, err := os.OpenFile(s.Path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	enc := json.NewEncoder(f)
+	for _, e := range events {
+		if err := enc.Encode(e); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -8,6 +8,8 @@ import (
 	"github.com/gocondor/core/auth"
 	"github.com/gocondor/core/database"
 	"github.com/gocondor/core/jwt"
+	"github.com/gocondor/gocondor/http/response"
+	"github.com/gocondor/gocondor/i18n"
 	"github.com/gocondor/gocondor/models"
 	"golang.org/x/crypto/bcrypt"
 	"gorm.io/gorm"
@@ -26,9 +28,7 @@ func Login(c *gin.Context) {
 	// validate and bind user input
 	var loginData LoginCreds
 	if err := c.ShouldBind(&loginData); err != nil {
-		c.AbortWithStatusJSON(http.StatusUnprocessableEntity, gin.H{
-			"message": err.Error(),
-		})
+		response.AbortWithValidationError(c, err)
 		return
 	}
 
@@ -38,7 +38,7 @@ func Login(c *gin.Context) {
 	// check if the record not found
 	if result.Error != nil && errors.Is(result.Error, gorm.ErrRecordNotFound) {
 		c.AbortWithStatusJSON(http.StatusUnprocessableEntity, gin.H{
-			"message": "wrong credentials",
+			"message": i18n.T(i18n.Locale(c), "error.wrong_credentials", nil),
 		})
 		return
 	}
@@ -56,7 +56,7 @@ func Login(c *gin.Context) {
 	if err != nil {
 		// wrong password
 		c.AbortWithStatusJSON(http.StatusUnprocessableEntity, gin.H{
-			"message": "wrong credentials",
+			"message": i18n.T(i18n.Locale(c), "error.wrong_credentials", nil),
 		})
 		return
 	}
@@ -104,9 +104,7 @@ func Register(c *gin.Context) {
 	// bind the input to the user's model
 	var user models.User
 	if err := c.ShouldBind(&user); err != nil {
-		c.AbortWithStatusJSON(http.StatusUnprocessableEntity, gin.H{
-			"message": err.Error(),
-		})
+		response.AbortWithValidationError(c, err)
 		return
 	}
 
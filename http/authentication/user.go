@@ -0,0 +1,25 @@
+// Copyright 2021 Harran Ali <harran.m@gmail.com>. All rights reserved.
+// Use of this source code is governed by MIT-style
+// license that can be found in the LICENSE file.
+
+package authentication
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/gocondor/core/auth"
+)
+
+// UserID returns the id of the user logged in with Login, ok is false for the guests,
+// the handlers and the modules identifying the user all call it, so replace it here
+// if your app identifies its users differently
+var UserID = func(c *gin.Context) (id uint, ok bool) {
+	a := auth.Resolve()
+	if a == nil {
+		return 0, false
+	}
+	id, err := a.UserID(c)
+	if err != nil || id == 0 {
+		return 0, false
+	}
+	return id, true
+}
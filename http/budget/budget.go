@@ -0,0 +1,99 @@
+// Copyright 2021 Harran Ali <harran.m@gmail.com>. All rights reserved.
+// Use of this source code is governed by MIT-style
+// license that can be found in the LICENSE file.
+
+package budget
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// contextKey is the key the budget is stored under in the gin context
+const contextKey = "gocondor.budget"
+
+// Budget splits a request's deadline across its downstream calls
+type Budget struct {
+	mu       sync.Mutex
+	deadline time.Time
+	calls    int
+}
+
+// Start sets a budget of timeout on the request, split across the given number of downstream calls
+func Start(c *gin.Context, timeout time.Duration, calls int) *Budget {
+	b := &Budget{deadline: time.Now().Add(timeout), calls: calls}
+	c.Set(contextKey, b)
+	return b
+}
+
+// From returns the request's budget, nil when no budget was started
+func From(c *gin.Context) *Budget {
+	b, ok := c.Get(contextKey)
+	if !ok {
+		return nil
+	}
+	return b.(*Budget)
+}
+
+// Remaining returns how much of the request's budget is left, a request without a budget has no limit
+func Remaining(c *gin.Context) time.Duration {
+	b := From(c)
+	if b == nil {
+		return time.Duration(1<<63 - 1)
+	}
+	return b.Remaining()
+}
+
+// Remaining returns how much of the budget is left
+func (b *Budget) Remaining() time.Duration {
+	remaining := time.Until(b.deadline)
+	if remaining < 0 {
+		return 0
+	}
+	return remaining
+}
+
+// Plan changes the number of downstream calls left, for when a branch skips or adds calls
+func (b *Budget) Plan(calls int) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.calls = calls
+}
+
+// Next returns a context for the next downstream call, the remaining budget is split evenly
+// across the calls left so earlier calls can't starve the later ones, the last call gets all
+// of what's left
+func (b *Budget) Next(parent context.Context) (context.Context, context.CancelFunc) {
+	b.mu.Lock()
+	calls := b.calls
+	if b.calls > 1 {
+		b.calls--
+	}
+	b.mu.Unlock()
+
+	share := b.Remaining()
+	if calls > 1 {
+		share /= time.Duration(calls)
+	}
+	deadline := time.Now().Add(share)
+	if deadline.After(b.deadline) {
+		deadline = b.deadline
+	}
+	return context.WithDeadline(parent, deadline)
+}
+
+// Next returns a context for the request's next downstream call, e.g:
+//
+//	ctx, cancel := budget.Next(c)
+//	defer cancel()
+//	db.WithContext(ctx).Find(&users)
+func Next(c *gin.Context) (context.Context, context.CancelFunc) {
+	b := From(c)
+	if b == nil {
+		return context.WithCancel(c.Request.Context())
+	}
+	return b.Next(c.Request.Context())
+}
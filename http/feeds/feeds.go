@@ -0,0 +1,79 @@
+// Copyright 2021 Harran Ali <harran.m@gmail.com>. All rights reserved.
+// Use of this source code is governed by MIT-style
+// license that can be found in the LICENSE file.
+
+package feeds
+
+import (
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// URL is an entry of the sitemap, Loc is relative to APP_URL or absolute
+type URL struct {
+	Loc        string
+	LastMod    time.Time
+	ChangeFreq string
+	Priority   float64
+}
+
+// Item is an entry of a feed
+type Item struct {
+	ID          string
+	Title       string
+	Link        string
+	Description string
+	Author      string
+	Published   time.Time
+	Updated     time.Time
+}
+
+// Feed is a named RSS and Atom feed built from a query
+type Feed struct {
+	Name        string
+	Title       string
+	Link        string
+	Description string
+	Items       func(db *gorm.DB) ([]Item, error)
+}
+
+var mu sync.RWMutex
+var sitemapSources []func(db *gorm.DB) ([]URL, error)
+var feeds = map[string]Feed{}
+
+// Pages adds fixed urls to the sitemap, e.g: feeds.Pages("/", "/about", "/pricing")
+func Pages(locs ...string) {
+	urls := make([]URL, len(locs))
+	for i, loc := range locs {
+		urls[i] = URL{Loc: loc}
+	}
+	AddSitemapSource(func(db *gorm.DB) ([]URL, error) {
+		return urls, nil
+	})
+}
+
+// AddSitemapSource adds the urls returned by a query to the sitemap, e.g:
+//
+//	feeds.AddSitemapSource(func(db *gorm.DB) ([]feeds.URL, error) {
+//		var posts []Post
+//		err := db.Select("slug", "updated_at").Find(&posts).Error
+//		urls := make([]feeds.URL, len(posts))
+//		for i, p := range posts {
+//			urls[i] = feeds.URL{Loc: "/posts/" + p.Slug, LastMod: p.UpdatedAt}
+//		}
+//		return urls, err
+//	})
+func AddSitemapSource(source func(db *gorm.DB) ([]URL, error)) {
+	mu.Lock()
+	defer mu.Unlock()
+	sitemapSources = append(sitemapSources, source)
+}
+
+// AddFeed registers a feed, served as /feeds/<name>.rss and /feeds/<name>.atom
+func AddFeed(f Feed) {
+	mu.Lock()
+	defer mu.Unlock()
+	feeds[f.Name] = f
+}
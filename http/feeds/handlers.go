@@ -0,0 +1,108 @@
+// Copyright 2021 Harran Ali <harran.m@gmail.com>. All rights reserved.
+// Use of this source code is governed by MIT-style
+// license that can be found in the LICENSE file.
+
+package feeds
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gocondor/core/database"
+	"github.com/gocondor/gocondor/http/response"
+	"gorm.io/gorm"
+)
+
+// MaxAge is how long clients and proxies may cache the sitemap and the feeds
+var MaxAge = time.Hour
+
+// Sitemap serves sitemap.xml
+func Sitemap(c *gin.Context) {
+	mu.RLock()
+	sources := make([]func(db *gorm.DB) ([]URL, error), len(sitemapSources))
+	copy(sources, sitemapSources)
+	mu.RUnlock()
+
+	var urls []URL
+	var lastMod time.Time
+	for _, source := range sources {
+		found, err := source(database.Resolve())
+		if err != nil {
+			response.AbortWithDBError(c, err)
+			return
+		}
+		for _, u := range found {
+			if u.LastMod.After(lastMod) {
+				lastMod = u.LastMod
+			}
+		}
+		urls = append(urls, found...)
+	}
+
+	if notModified(c, lastMod) {
+		return
+	}
+	c.XML(http.StatusOK, sitemapXML(urls))
+}
+
+// FeedShow serves a feed, /feeds/<name>.rss as RSS and /feeds/<name>.atom as Atom
+func FeedShow(c *gin.Context) {
+	file := c.Param("file")
+	format := "rss"
+	name := strings.TrimSuffix(file, ".rss")
+	if strings.HasSuffix(file, ".atom") {
+		format = "atom"
+		name = strings.TrimSuffix(file, ".atom")
+	}
+
+	mu.RLock()
+	f, ok := feeds[name]
+	mu.RUnlock()
+	if !ok {
+		c.AbortWithStatusJSON(http.StatusNotFound, gin.H{
+			"message": "not found",
+		})
+		return
+	}
+
+	items, err := f.Items(database.Resolve())
+	if err != nil {
+		response.AbortWithDBError(c, err)
+		return
+	}
+	var updated time.Time
+	for _, item := range items {
+		if t := itemUpdated(item); t.After(updated) {
+			updated = t
+		}
+	}
+
+	if notModified(c, updated) {
+		return
+	}
+	if format == "atom" {
+		c.Header("Content-Type", "application/atom+xml; charset=utf-8")
+		c.XML(http.StatusOK, atomXML(f, items, updated))
+		return
+	}
+	c.Header("Content-Type", "application/rss+xml; charset=utf-8")
+	c.XML(http.StatusOK, rssXML(f, items, updated))
+}
+
+// notModified sets the caching headers and answers 304 when the client's copy is fresh
+func notModified(c *gin.Context, lastMod time.Time) bool {
+	c.Header("Cache-Control", "public, max-age="+strconv.Itoa(int(MaxAge.Seconds())))
+	if lastMod.IsZero() {
+		return false
+	}
+	c.Header("Last-Modified", lastMod.UTC().Format(http.TimeFormat))
+	since, err := http.ParseTime(c.GetHeader("If-Modified-Since"))
+	if err == nil && !lastMod.Truncate(time.Second).After(since) {
+		c.AbortWithStatus(http.StatusNotModified)
+		return true
+	}
+	return false
+}
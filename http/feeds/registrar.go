@@ -0,0 +1,12 @@
+// Copyright 2021 Harran Ali <harran.m@gmail.com>. All rights reserved.
+// Use of this source code is governed by MIT-style
+// license that can be found in the LICENSE file.
+
+package feeds
+
+// RegisterFeeds registers the sitemap sources and the feeds
+func RegisterFeeds() {
+	// Register your sitemap pages, sources and feeds here, e.g:
+	// Pages("/", "/about")
+	// AddFeed(Feed{Name: "blog", Title: "Blog", Link: "/blog", Items: latestPosts})
+}
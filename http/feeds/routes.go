@@ -0,0 +1,15 @@
+// Copyright 2021 Harran Ali <harran.m@gmail.com>. All rights reserved.
+// Use of this source code is governed by MIT-style
+// license that can be found in the LICENSE file.
+
+package feeds
+
+import "github.com/gocondor/core/routing"
+
+// RegisterFeedsRoutes registers the sitemap and the feeds
+func RegisterFeedsRoutes() {
+	router := routing.Resolve()
+
+	router.Get("/sitemap.xml", Sitemap)
+	router.Get("/feeds/:file", FeedShow)
+}
@@ -0,0 +1,158 @@
+// Copyright 2021 Harran Ali <harran.m@gmail.com>. All rights reserved.
+// Use of this source code is governed by MIT-style
+// license that can be found in the LICENSE file.
+
+package feeds
+
+import (
+	"encoding/xml"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type sitemapURLSet struct {
+	XMLName xml.Name     `xml:"urlset"`
+	XMLNS   string       `xml:"xmlns,attr"`
+	URLs    []sitemapURL `xml:"url"`
+}
+
+type sitemapURL struct {
+	Loc        string `xml:"loc"`
+	LastMod    string `xml:"lastmod,omitempty"`
+	ChangeFreq string `xml:"changefreq,omitempty"`
+	Priority   string `xml:"priority,omitempty"`
+}
+
+type rss struct {
+	XMLName xml.Name   `xml:"rss"`
+	Version string     `xml:"version,attr"`
+	Channel rssChannel `xml:"channel"`
+}
+
+type rssChannel struct {
+	Title         string    `xml:"title"`
+	Link          string    `xml:"link"`
+	Description   string    `xml:"description"`
+	LastBuildDate string    `xml:"lastBuildDate,omitempty"`
+	Items         []rssItem `xml:"item"`
+}
+
+type rssItem struct {
+	Title       string `xml:"title"`
+	Link        string `xml:"link"`
+	Description string `xml:"description,omitempty"`
+	Author      string `xml:"author,omitempty"`
+	GUID        string `xml:"guid"`
+	PubDate     string `xml:"pubDate,omitempty"`
+}
+
+type atomFeed struct {
+	XMLName xml.Name    `xml:"feed"`
+	XMLNS   string      `xml:"xmlns,attr"`
+	ID      string      `xml:"id"`
+	Title   string      `xml:"title"`
+	Link    atomLink    `xml:"link"`
+	Updated string      `xml:"updated"`
+	Entries []atomEntry `xml:"entry"`
+}
+
+type atomLink struct {
+	Href string `xml:"href,attr"`
+}
+
+type atomEntry struct {
+	ID      string      `xml:"id"`
+	Title   string      `xml:"title"`
+	Link    atomLink    `xml:"link"`
+	Summary string      `xml:"summary,omitempty"`
+	Author  *atomAuthor `xml:"author,omitempty"`
+	Updated string      `xml:"updated"`
+}
+
+type atomAuthor struct {
+	Name string `xml:"name"`
+}
+
+func sitemapXML(urls []URL) sitemapURLSet {
+	set := sitemapURLSet{XMLNS: "http://www.sitemaps.org/schemas/sitemap/0.9"}
+	for _, u := range urls {
+		entry := sitemapURL{Loc: absolute(u.Loc), ChangeFreq: u.ChangeFreq}
+		if !u.LastMod.IsZero() {
+			entry.LastMod = u.LastMod.UTC().Format("2006-01-02")
+		}
+		if u.Priority > 0 {
+			entry.Priority = strconv.FormatFloat(u.Priority, 'f', 1, 64)
+		}
+		set.URLs = append(set.URLs, entry)
+	}
+	return set
+}
+
+func rssXML(f Feed, items []Item, updated time.Time) rss {
+	channel := rssChannel{Title: f.Title, Link: absolute(f.Link), Description: f.Description}
+	if !updated.IsZero() {
+		channel.LastBuildDate = updated.UTC().Format(time.RFC1123Z)
+	}
+	for _, item := range items {
+		entry := rssItem{
+			Title:       item.Title,
+			Link:        absolute(item.Link),
+			Description: item.Description,
+			Author:      item.Author,
+			GUID:        itemID(item),
+		}
+		if !item.Published.IsZero() {
+			entry.PubDate = item.Published.UTC().Format(time.RFC1123Z)
+		}
+		channel.Items = append(channel.Items, entry)
+	}
+	return rss{Version: "2.0", Channel: channel}
+}
+
+func atomXML(f Feed, items []Item, updated time.Time) atomFeed {
+	feed := atomFeed{
+		XMLNS:   "http://www.w3.org/2005/Atom",
+		ID:      absolute(f.Link),
+		Title:   f.Title,
+		Link:    atomLink{Href: absolute(f.Link)},
+		Updated: updated.UTC().Format(time.RFC3339),
+	}
+	for _, item := range items {
+		entry := atomEntry{
+			ID:      itemID(item),
+			Title:   item.Title,
+			Link:    atomLink{Href: absolute(item.Link)},
+			Summary: item.Description,
+			Updated: itemUpdated(item).UTC().Format(time.RFC3339),
+		}
+		if item.Author != "" {
+			entry.Author = &atomAuthor{Name: item.Author}
+		}
+		feed.Entries = append(feed.Entries, entry)
+	}
+	return feed
+}
+
+// absolute prefixes the relative urls with APP_URL
+func absolute(loc string) string {
+	if strings.HasPrefix(loc, "http://") || strings.HasPrefix(loc, "https://") {
+		return loc
+	}
+	return strings.TrimRight(os.Getenv("APP_URL"), "/") + "/" + strings.TrimLeft(loc, "/")
+}
+
+func itemID(item Item) string {
+	if item.ID != "" {
+		return item.ID
+	}
+	return absolute(item.Link)
+}
+
+func itemUpdated(item Item) time.Time {
+	if !item.Updated.IsZero() {
+		return item.Updated
+	}
+	return item.Published
+}
@@ -0,0 +1,33 @@
+// Copyright 2021 Harran Ali <harran.m@gmail.com>. All rights reserved.
+// Use of this source code is governed by MIT-style
+// license that can be found in the LICENSE file.
+
+package health
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Healthz answers the liveness probe
+func Healthz(c *gin.Context) {
+	respond(c, Liveness)
+}
+
+// Readyz answers the readiness probe
+func Readyz(c *gin.Context) {
+	respond(c, Readiness)
+}
+
+func respond(c *gin.Context, kind Kind) {
+	results, ok := Run(c.Request.Context(), kind)
+	status, code := "ok", http.StatusOK
+	if !ok {
+		status, code = "failing", http.StatusServiceUnavailable
+	}
+	c.JSON(code, gin.H{
+		"status": status,
+		"checks": results,
+	})
+}
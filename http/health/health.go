@@ -0,0 +1,115 @@
+// Copyright 2021 Harran Ali <harran.m@gmail.com>. All rights reserved.
+// Use of this source code is governed by MIT-style
+// license that can be found in the LICENSE file.
+
+package health
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Check reports the health of a dependency, returning an error marks it failing
+type Check func(ctx context.Context) error
+
+// Kind tells which probe a check belongs to
+type Kind int
+
+const (
+	// Liveness checks failing means the app must be restarted
+	Liveness Kind = iota
+	// Readiness checks failing means the app must not receive traffic for now
+	Readiness
+)
+
+// Result is the outcome of a check
+type Result struct {
+	Status    string  `json:"status"`
+	LatencyMs float64 `json:"latencyMs"`
+	Error     string  `json:"error,omitempty"`
+}
+
+// Timeout bounds every check
+var Timeout = 5 * time.Second
+
+type namedCheck struct {
+	name  string
+	kind  Kind
+	check Check
+}
+
+var mu sync.RWMutex
+var checks []namedCheck
+
+// Register registers a named check for a probe
+func Register(name string, kind Kind, check Check) {
+	mu.Lock()
+	defer mu.Unlock()
+	checks = append(checks, namedCheck{name, kind, check})
+}
+
+// Run runs the checks of a probe concurrently, ok is false when any of them fails
+func Run(ctx context.Context, kind Kind) (results map[string]Result, ok bool) {
+	mu.RLock()
+	var selected []namedCheck
+	for _, c := range checks {
+		if c.kind == kind {
+			selected = append(selected, c)
+		}
+	}
+	mu.RUnlock()
+
+	ctx, cancel := context.WithTimeout(ctx, Timeout)
+	defer cancel()
+
+	results = make(map[string]Result, len(selected))
+	var resultsMu sync.Mutex
+	var wg sync.WaitGroup
+	ok = true
+	for _, c := range selected {
+		wg.Add(1)
+		go func(c namedCheck) {
+			defer wg.Done()
+			started := time.Now()
+			err := c.check(ctx)
+			result := Result{Status: "ok", LatencyMs: float64(time.Since(started).Microseconds()) / 1000}
+			if err != nil {
+				result.Status = "failing"
+				result.Error = err.Error()
+			}
+			resultsMu.Lock()
+			defer resultsMu.Unlock()
+			results[c.name] = result
+			if err != nil {
+				ok = false
+			}
+		}(c)
+	}
+	wg.Wait()
+	return results, ok
+}
+
+// Database returns a check pinging the database
+func Database(db *gorm.DB) Check {
+	return func(ctx context.Context) error {
+		sqlDB, err := db.DB()
+		if err != nil {
+			return err
+		}
+		return sqlDB.PingContext(ctx)
+	}
+}
+
+// Ready returns a check failing until ready returns true, e.g: health.Ready(warmup.Ready)
+func Ready(ready func() bool) Check {
+	return func(ctx context.Context) error {
+		if !ready() {
+			return errors.New("not ready yet")
+		}
+		return nil
+	}
+}
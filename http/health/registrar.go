@@ -0,0 +1,11 @@
+// Copyright 2021 Harran Ali <harran.m@gmail.com>. All rights reserved.
+// Use of this source code is governed by MIT-style
+// license that can be found in the LICENSE file.
+
+package health
+
+// RegisterChecks registers the app's health checks
+func RegisterChecks() {
+	// Register your checks here, e.g:
+	// Register("payments-api", Readiness, pingPaymentsAPI)
+}
@@ -0,0 +1,15 @@
+// Copyright 2021 Harran Ali <harran.m@gmail.com>. All rights reserved.
+// Use of this source code is governed by MIT-style
+// license that can be found in the LICENSE file.
+
+package health
+
+import "github.com/gocondor/core/routing"
+
+// RegisterHealthRoutes registers the liveness and readiness probes
+func RegisterHealthRoutes() {
+	router := routing.Resolve()
+
+	router.Get("/healthz", Healthz)
+	router.Get("/readyz", Readyz)
+}
@@ -0,0 +1,196 @@
+// Copyright 2021 Harran Ali <harran.m@gmail.com>. All rights reserved.
+// Use of this source code is governed by MIT-style
+// license that can be found in the LICENSE file.
+
+package inbound
+
+import (
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// maxMessageSize is the largest webhook body accepted
+const maxMessageSize = 32 << 20
+
+// SendGrid receives the messages posted by SendGrid's inbound parse webhook
+func SendGrid(c *gin.Context) {
+	if !authorized(c) {
+		return
+	}
+	if err := c.Request.ParseMultipartForm(maxMessageSize); err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
+			"message": "invalid inbound message",
+		})
+		return
+	}
+
+	// "send raw" posts the whole mime message in the email field
+	if raw := c.PostForm("email"); raw != "" {
+		msg, err := ParseMIME([]byte(raw))
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
+				"message": "invalid inbound message",
+			})
+			return
+		}
+		msg.Provider = "sendgrid"
+		Dispatch(msg)
+		c.Status(http.StatusOK)
+		return
+	}
+
+	msg := Message{
+		Provider: "sendgrid",
+		From:     c.PostForm("from"),
+		Subject:  c.PostForm("subject"),
+		Text:     c.PostForm("text"),
+		HTML:     c.PostForm("html"),
+		Headers:  map[string]string{},
+	}
+	for _, to := range strings.Split(c.PostForm("to"), ",") {
+		if to = strings.TrimSpace(to); to != "" {
+			msg.To = append(msg.To, to)
+		}
+	}
+	for _, line := range strings.Split(c.PostForm("headers"), "\n") {
+		if parts := strings.SplitN(line, ":", 2); len(parts) == 2 {
+			msg.Headers[strings.TrimSpace(parts[0])] = strings.TrimSpace(parts[1])
+		}
+	}
+	if c.Request.MultipartForm != nil {
+		for _, files := range c.Request.MultipartForm.File {
+			for _, file := range files {
+				f, err := file.Open()
+				if err != nil {
+					continue
+				}
+				content, err := ioutil.ReadAll(f)
+				f.Close()
+				if err != nil {
+					continue
+				}
+				msg.Attachments = append(msg.Attachments, Attachment{
+					Filename:    file.Filename,
+					ContentType: file.Header.Get("Content-Type"),
+					Content:     content,
+				})
+			}
+		}
+	}
+
+	Dispatch(msg)
+	c.Status(http.StatusOK)
+}
+
+// sesNotification is the SES receipt notification, content holds the raw message
+type sesNotification struct {
+	NotificationType string `json:"notificationType"`
+	Content          string `json:"content"`
+	Receipt          struct {
+		Action struct {
+			Encoding string `json:"encoding"`
+		} `json:"action"`
+	} `json:"receipt"`
+}
+
+// SES receives the messages SES publishes to an SNS topic with the https subscription, the
+// messages not signed by SNS are rejected, the receipt rule's SNS action must include the
+// message content
+func SES(c *gin.Context) {
+	if !authorized(c) {
+		return
+	}
+	var envelope snsMessage
+	body, err := ioutil.ReadAll(http.MaxBytesReader(c.Writer, c.Request.Body, maxMessageSize))
+	if err == nil {
+		err = json.Unmarshal(body, &envelope)
+	}
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
+			"message": "invalid inbound message",
+		})
+		return
+	}
+	if err := envelope.verify(); err != nil {
+		c.AbortWithStatusJSON(http.StatusForbidden, gin.H{
+			"message": "forbidden",
+		})
+		return
+	}
+
+	switch envelope.Type {
+	case "SubscriptionConfirmation":
+		confirm, err := url.Parse(envelope.SubscribeURL)
+		if err != nil || confirm.Scheme != "https" || !strings.HasSuffix(confirm.Hostname(), ".amazonaws.com") {
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
+				"message": "invalid subscription url",
+			})
+			return
+		}
+		resp, err := http.Get(confirm.String())
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusBadGateway, gin.H{
+				"message": "couldn't confirm the subscription",
+			})
+			return
+		}
+		resp.Body.Close()
+		c.Status(http.StatusOK)
+		return
+	case "Notification":
+	default:
+		c.Status(http.StatusOK)
+		return
+	}
+
+	var notification sesNotification
+	if err := json.Unmarshal([]byte(envelope.Message), &notification); err != nil || notification.NotificationType != "Received" {
+		c.Status(http.StatusOK)
+		return
+	}
+	raw := []byte(notification.Content)
+	if notification.Receipt.Action.Encoding == "BASE64" {
+		if raw, err = base64.StdEncoding.DecodeString(notification.Content); err != nil {
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
+				"message": "invalid inbound message",
+			})
+			return
+		}
+	}
+	msg, err := ParseMIME(raw)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
+			"message": "invalid inbound message",
+		})
+		return
+	}
+	msg.Provider = "ses"
+	Dispatch(msg)
+	c.Status(http.StatusOK)
+}
+
+// authorized checks the token the webhook url was configured with against INBOUND_MAIL_TOKEN,
+// it's sent in the X-Inbound-Token header, or as the password of the url's basic auth for the
+// providers that can't set headers, e.g: https://inbound:<token>@example.com/inbound/ses
+func authorized(c *gin.Context) bool {
+	token := os.Getenv("INBOUND_MAIL_TOKEN")
+	given := c.GetHeader("X-Inbound-Token")
+	if given == "" {
+		_, given, _ = c.Request.BasicAuth()
+	}
+	if token == "" || subtle.ConstantTimeCompare([]byte(given), []byte(token)) != 1 {
+		c.AbortWithStatusJSON(http.StatusForbidden, gin.H{
+			"message": "forbidden",
+		})
+		return false
+	}
+	return true
+}
@@ -0,0 +1,152 @@
+// Copyright 2021 Harran Ali <harran.m@gmail.com>. All rights reserved.
+// Use of this source code is governed by MIT-style
+// license that can be found in the LICENSE file.
+
+package inbound
+
+import (
+	"bytes"
+	"encoding/base64"
+	"io"
+	"io/ioutil"
+	"log"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net/mail"
+	"strings"
+	"sync"
+)
+
+// Attachment is a file attached to an inbound message
+type Attachment struct {
+	Filename    string
+	ContentType string
+	Content     []byte
+}
+
+// Message is an inbound email, parsed from the provider's webhook
+type Message struct {
+	Provider    string
+	From        string
+	To          []string
+	Subject     string
+	Text        string
+	HTML        string
+	Headers     map[string]string
+	Attachments []Attachment
+}
+
+// Handler handles an inbound message
+type Handler func(msg Message) error
+
+var mu sync.RWMutex
+var handlers []Handler
+
+// Handle registers a handler for the inbound messages
+func Handle(h Handler) {
+	mu.Lock()
+	defer mu.Unlock()
+	handlers = append(handlers, h)
+}
+
+// Dispatch passes the message to the handlers in the background so the webhook answers quickly
+func Dispatch(msg Message) {
+	mu.RLock()
+	hs := make([]Handler, len(handlers))
+	copy(hs, handlers)
+	mu.RUnlock()
+
+	go func() {
+		for _, h := range hs {
+			if err := h(msg); err != nil {
+				log.Printf("inbound mail from %s: %v", msg.From, err)
+			}
+		}
+	}()
+}
+
+// ParseMIME parses a raw email
+func ParseMIME(raw []byte) (Message, error) {
+	parsed, err := mail.ReadMessage(bytes.NewReader(raw))
+	if err != nil {
+		return Message{}, err
+	}
+
+	msg := Message{Headers: map[string]string{}}
+	for key := range parsed.Header {
+		msg.Headers[key] = parsed.Header.Get(key)
+	}
+	decoder := new(mime.WordDecoder)
+	msg.Subject, _ = decoder.DecodeHeader(parsed.Header.Get("Subject"))
+	if from, err := mail.ParseAddress(parsed.Header.Get("From")); err == nil {
+		msg.From = from.Address
+	} else {
+		msg.From = parsed.Header.Get("From")
+	}
+	if to, err := parsed.Header.AddressList("To"); err == nil {
+		for _, addr := range to {
+			msg.To = append(msg.To, addr.Address)
+		}
+	}
+
+	err = readPart(&msg, parsed.Header.Get("Content-Type"), parsed.Header.Get("Content-Transfer-Encoding"), "", parsed.Body)
+	return msg, err
+}
+
+// readPart reads the text, html and attachments of a part, walking nested multiparts
+func readPart(msg *Message, contentType string, encoding string, disposition string, body io.Reader) error {
+	mediaType, params, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		mediaType = "text/plain"
+	}
+
+	if strings.HasPrefix(mediaType, "multipart/") {
+		reader := multipart.NewReader(body, params["boundary"])
+		for {
+			part, err := reader.NextPart()
+			if err == io.EOF {
+				return nil
+			}
+			if err != nil {
+				return err
+			}
+			err = readPart(msg, part.Header.Get("Content-Type"), part.Header.Get("Content-Transfer-Encoding"), part.Header.Get("Content-Disposition"), part)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	content, err := ioutil.ReadAll(decode(encoding, body))
+	if err != nil {
+		return err
+	}
+
+	_, dispositionParams, _ := mime.ParseMediaType(disposition)
+	switch {
+	case strings.HasPrefix(disposition, "attachment") || dispositionParams["filename"] != "":
+		msg.Attachments = append(msg.Attachments, Attachment{
+			Filename:    dispositionParams["filename"],
+			ContentType: mediaType,
+			Content:     content,
+		})
+	case mediaType == "text/html" && msg.HTML == "":
+		msg.HTML = string(content)
+	case mediaType == "text/plain" && msg.Text == "":
+		msg.Text = string(content)
+	}
+	return nil
+}
+
+// decode undoes the content transfer encoding of a part
+func decode(encoding string, body io.Reader) io.Reader {
+	switch strings.ToLower(strings.TrimSpace(encoding)) {
+	case "base64":
+		return base64.NewDecoder(base64.StdEncoding, body)
+	case "quoted-printable":
+		return quotedprintable.NewReader(body)
+	default:
+		return body
+	}
+}
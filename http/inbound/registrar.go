@@ -0,0 +1,11 @@
+// Copyright 2021 Harran Ali <harran.m@gmail.com>. All rights reserved.
+// Use of this source code is governed by MIT-style
+// license that can be found in the LICENSE file.
+
+package inbound
+
+// RegisterHandlers registers the handlers of the inbound messages
+func RegisterHandlers() {
+	// Register your inbound mail handlers here, e.g:
+	// Handle(createTicketFromEmail)
+}
@@ -0,0 +1,16 @@
+// Copyright 2021 Harran Ali <harran.m@gmail.com>. All rights reserved.
+// Use of this source code is governed by MIT-style
+// license that can be found in the LICENSE file.
+
+package inbound
+
+import "github.com/gocondor/core/routing"
+
+// RegisterInboundRoutes registers the inbound mail webhooks, configure the providers to post
+// to them with INBOUND_MAIL_TOKEN as the password of the url, e.g: https://inbound:<token>@example.com/inbound/sendgrid
+func RegisterInboundRoutes() {
+	router := routing.Resolve()
+
+	router.Post("/inbound/sendgrid", SendGrid)
+	router.Post("/inbound/ses", SES)
+}
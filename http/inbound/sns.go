@@ -0,0 +1,130 @@
+// Copyright 2021 Harran Ali <harran.m@gmail.com>. All rights reserved.
+// Use of this source code is governed by MIT-style
+// license that can be found in the LICENSE file.
+
+package inbound
+
+import (
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+)
+
+// snsMessage is the envelope SNS posts notifications in
+type snsMessage struct {
+	Type             string
+	MessageID        string
+	Token            string
+	TopicArn         string
+	Subject          string
+	Message          string
+	Timestamp        string
+	SignatureVersion string
+	Signature        string
+	SigningCertURL   string `json:"SigningCertURL"`
+	SubscribeURL     string `json:"SubscribeURL"`
+}
+
+// ErrInvalidSignature is returned for the SNS messages not signed by SNS
+var ErrInvalidSignature = errors.New("invalid sns signature")
+
+// the hosts SNS serves its signing certificates from
+var snsCertHost = regexp.MustCompile(`^sns\.[a-z0-9-]+\.amazonaws\.com(\.cn)?$`)
+
+// the signing certificates by url, SNS signs with few certificates
+var certsMu sync.Mutex
+var certs = map[string]*x509.Certificate{}
+
+var certClient = &http.Client{Timeout: 10 * time.Second}
+
+// verify checks the message was signed by SNS with the certificate of its SigningCertURL
+func (m snsMessage) verify() error {
+	signature, err := base64.StdEncoding.DecodeString(m.Signature)
+	if err != nil {
+		return ErrInvalidSignature
+	}
+	cert, err := signingCert(m.SigningCertURL)
+	if err != nil {
+		return err
+	}
+	algorithm := x509.SHA1WithRSA
+	switch m.SignatureVersion {
+	case "1":
+	case "2":
+		algorithm = x509.SHA256WithRSA
+	default:
+		return ErrInvalidSignature
+	}
+	if err := cert.CheckSignature(algorithm, []byte(m.signed()), signature); err != nil {
+		return ErrInvalidSignature
+	}
+	return nil
+}
+
+// signed returns the string SNS signs, the fields by name, in order, a name and value per line
+func (m snsMessage) signed() string {
+	fields := []string{"Message", m.Message, "MessageId", m.MessageID}
+	if m.Type == "Notification" {
+		if m.Subject != "" {
+			fields = append(fields, "Subject", m.Subject)
+		}
+	} else {
+		fields = append(fields, "SubscribeURL", m.SubscribeURL)
+	}
+	fields = append(fields, "Timestamp", m.Timestamp)
+	if m.Type != "Notification" {
+		fields = append(fields, "Token", m.Token)
+	}
+	fields = append(fields, "TopicArn", m.TopicArn, "Type", m.Type)
+	return strings.Join(fields, "\n") + "\n"
+}
+
+// signingCert downloads the certificate of an SNS url, only the certificates served
+// by SNS over https are accepted
+func signingCert(certURL string) (*x509.Certificate, error) {
+	u, err := url.Parse(certURL)
+	if err != nil || u.Scheme != "https" || !snsCertHost.MatchString(u.Hostname()) || !strings.HasSuffix(u.Path, ".pem") {
+		return nil, ErrInvalidSignature
+	}
+
+	certsMu.Lock()
+	cert, ok := certs[certURL]
+	certsMu.Unlock()
+	if ok {
+		return cert, nil
+	}
+
+	resp, err := certClient.Get(certURL)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("downloading the sns certificate: %s", resp.Status)
+	}
+	data, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, ErrInvalidSignature
+	}
+	if cert, err = x509.ParseCertificate(block.Bytes); err != nil {
+		return nil, err
+	}
+
+	certsMu.Lock()
+	certs[certURL] = cert
+	certsMu.Unlock()
+	return cert, nil
+}
@@ -1,7 +1,32 @@
 package input
 
+import "gorm.io/gorm"
+
 // User represents request data with user information
 type User struct {
 	Name string `json:"name" binding:"exists,alphanum"`
 	Age  int    `json:"age" binding:"exists,alphanum,min=18"`
 }
+
+// IDParam represents the id route param, bind it with c.ShouldBindUri
+type IDParam struct {
+	ID string `uri:"id" binding:"required,id"`
+}
+
+// Page represents the pagination query, bind it with c.ShouldBindQuery
+type Page struct {
+	Page    int `form:"page" json:"page" binding:"omitempty,min=1"`
+	PerPage int `form:"perPage" json:"perPage" binding:"omitempty,min=1,max=100"`
+}
+
+// Scope is a query scope limiting the query to the page, pages start at 1 and hold 20 records by default
+func (p Page) Scope(db *gorm.DB) *gorm.DB {
+	page, perPage := p.Page, p.PerPage
+	if page < 1 {
+		page = 1
+	}
+	if perPage < 1 {
+		perPage = 20
+	}
+	return db.Offset((page - 1) * perPage).Limit(perPage)
+}
@@ -0,0 +1,30 @@
+// Copyright 2021 Harran Ali <harran.m@gmail.com>. All rights reserved.
+// Use of this source code is governed by MIT-style
+// license that can be found in the LICENSE file.
+
+package input
+
+import (
+	"reflect"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gocondor/gocondor/http/response"
+	"gorm.io/gorm"
+)
+
+// RouteRecord loads the record of model with the id route param, e.g: the record of
+// /comments/:resource/:id, the request is aborted when ok is false
+func RouteRecord(c *gin.Context, db *gorm.DB, model reflect.Type) (record interface{}, id uint, ok bool) {
+	parsed, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		response.AbortNotFound(c)
+		return nil, 0, false
+	}
+	record = reflect.New(model).Interface()
+	if err := db.First(record, parsed).Error; err != nil {
+		response.AbortWithDBError(c, err)
+		return nil, 0, false
+	}
+	return record, uint(parsed), true
+}
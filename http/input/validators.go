@@ -0,0 +1,42 @@
+// Copyright 2021 Harran Ali <harran.m@gmail.com>. All rights reserved.
+// Use of this source code is governed by MIT-style
+// license that can be found in the LICENSE file.
+
+package input
+
+import (
+	"reflect"
+	"strings"
+
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+	"github.com/gocondor/gocondor/id"
+	"github.com/gocondor/gocondor/money"
+)
+
+// RegisterValidators registers the custom binding tags
+func RegisterValidators() {
+	v, ok := binding.Validator.Engine().(*validator.Validate)
+	if !ok {
+		return
+	}
+
+	// name the fields in validation errors after their json names
+	v.RegisterTagNameFunc(func(field reflect.StructField) string {
+		name := strings.SplitN(field.Tag.Get("json"), ",", 2)[0]
+		if name == "" || name == "-" {
+			return field.Name
+		}
+		return name
+	})
+
+	// "id" checks the value has the format of the configured ID_GENERATOR
+	v.RegisterValidation("id", func(fl validator.FieldLevel) bool {
+		return id.Valid(fl.Field().String())
+	})
+
+	// "currency" checks the value is a supported ISO 4217 currency code
+	v.RegisterValidation("currency", func(fl validator.FieldLevel) bool {
+		return money.ValidCurrency(fl.Field().String())
+	})
+}
@@ -0,0 +1,237 @@
+// Copyright 2021 Harran Ali <harran.m@gmail.com>. All rights reserved.
+// Use of this source code is governed by MIT-style
+// license that can be found in the LICENSE file.
+
+package menus
+
+import (
+	"log"
+	"net/http"
+	"sort"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/render"
+	"github.com/gocondor/gocondor/http/routing"
+)
+
+// Item is an entry of a menu, its url is generated from the named route, or is URL for the
+// links outside the app, the params of the route default to the ones of the current request
+type Item struct {
+	Label    string
+	Route    string
+	Params   map[string]string
+	URL      string
+	Order    int
+	Children []Item
+	// Exact only activates the item on its own route and not on the routes under it, e.g: home
+	Exact bool
+	// Active is set on the items leading to the current route when the menu is built
+	Active bool
+}
+
+// Crumb is an entry of the breadcrumbs, the last one is the current page
+type Crumb struct {
+	Label  string
+	URL    string
+	Active bool
+}
+
+type breadcrumb struct {
+	parent string
+	label  string
+}
+
+var mu sync.RWMutex
+var menus = map[string][]Item{}
+var breadcrumbs = map[string]breadcrumb{}
+
+// Add adds items to a menu, the modules contribute to the app's menus with it from their Routes, e.g:
+//
+//	menus.Add("main", menus.Item{Label: "Posts", Route: "posts.index", Order: 10})
+func Add(menu string, items ...Item) {
+	mu.Lock()
+	defer mu.Unlock()
+	menus[menu] = append(menus[menu], items...)
+}
+
+// Breadcrumb declares the breadcrumb of a named route and the route it's under,
+// the top routes have no parent, e.g:
+//
+//	menus.Breadcrumb("posts.index", "", "Posts")
+//	menus.Breadcrumb("posts.show", "posts.index", "Post")
+func Breadcrumb(route string, parent string, label string) {
+	mu.Lock()
+	defer mu.Unlock()
+	breadcrumbs[route] = breadcrumb{parent: parent, label: label}
+}
+
+// Build returns a menu ordered by Order with the urls generated, the items of the current route,
+// of the routes above it in the breadcrumbs unless they're Exact, and the parents of active items
+// are active
+func Build(menu string, current string, params map[string]string) []Item {
+	mu.RLock()
+	defer mu.RUnlock()
+	trail := map[string]bool{}
+	for route := current; route != "" && !trail[route]; route = breadcrumbs[route].parent {
+		trail[route] = true
+	}
+	return build(menus[menu], current, trail, params)
+}
+
+// Breadcrumbs returns the trail of breadcrumbs from the top route down to the current one
+func Breadcrumbs(current string, params map[string]string) []Crumb {
+	mu.RLock()
+	defer mu.RUnlock()
+	var crumbs []Crumb
+	seen := map[string]bool{}
+	for route := current; route != "" && !seen[route]; route = breadcrumbs[route].parent {
+		seen[route] = true
+		b, ok := breadcrumbs[route]
+		if !ok {
+			break
+		}
+		crumb := Crumb{Label: b.label, URL: url(route, nil, params), Active: route == current}
+		crumbs = append([]Crumb{crumb}, crumbs...)
+	}
+	return crumbs
+}
+
+// Data returns the menus and the breadcrumbs of the request, the templates get them
+// as .Menus.<name> and .Breadcrumbs, and the name of the current route as .Route,
+// the templates rendered through Render get them without calling it
+func Data(c *gin.Context) gin.H {
+	current := routing.CurrentName(c)
+	params := map[string]string{}
+	for _, param := range c.Params {
+		params[param.Key] = param.Value
+	}
+
+	mu.RLock()
+	names := make([]string, 0, len(menus))
+	for name := range menus {
+		names = append(names, name)
+	}
+	mu.RUnlock()
+
+	built := map[string][]Item{}
+	for _, name := range names {
+		built[name] = Build(name, current, params)
+	}
+	return gin.H{
+		"Route":       current,
+		"Menus":       built,
+		"Breadcrumbs": Breadcrumbs(current, params),
+	}
+}
+
+// With adds the menus and the breadcrumbs of the request to the data of a template
+func With(c *gin.Context, data gin.H) gin.H {
+	merged := Data(c)
+	for key, value := range data {
+		merged[key] = value
+	}
+	return merged
+}
+
+// Middleware keeps the request with its response so the templates rendered through
+// Render get the menus of the request, it's attached to every route
+func Middleware(c *gin.Context) {
+	c.Writer = &writer{ResponseWriter: c.Writer, c: c}
+	c.Next()
+}
+
+// Render wraps the templates' renderer so the maps given to c.HTML get the menus, the breadcrumbs
+// and the route of the request, the keys given by the handlers win, e.g:
+//
+//	engine.LoadHTMLGlob("templates/**/*")
+//	engine.HTMLRender = menus.Render(engine.HTMLRender)
+//	// c.HTML(http.StatusOK, "posts/show.html", gin.H{"Post": post}) renders {{range .Menus.main}}
+func Render(r render.HTMLRender) render.HTMLRender {
+	return htmlRender{r}
+}
+
+// writer is the response writer of the requests going through Middleware
+type writer struct {
+	gin.ResponseWriter
+	c *gin.Context
+}
+
+type htmlRender struct {
+	render.HTMLRender
+}
+
+// Instance returns the template's render with the data it merges when it's rendered
+func (r htmlRender) Instance(name string, data interface{}) render.Render {
+	return instance{r: r.HTMLRender, name: name, data: data}
+}
+
+type instance struct {
+	r    render.HTMLRender
+	name string
+	data interface{}
+}
+
+// Render adds the menus to the map data of the requests going through Middleware
+func (i instance) Render(w http.ResponseWriter) error {
+	data := i.data
+	if mw, ok := w.(*writer); ok {
+		switch given := data.(type) {
+		case nil:
+			data = Data(mw.c)
+		case gin.H:
+			data = With(mw.c, given)
+		case map[string]interface{}:
+			data = With(mw.c, given)
+		}
+	}
+	return i.r.Instance(i.name, data).Render(w)
+}
+
+// WriteContentType writes the content type of the template
+func (i instance) WriteContentType(w http.ResponseWriter) {
+	i.r.Instance(i.name, i.data).WriteContentType(w)
+}
+
+// build copies the items with their urls and active state
+func build(items []Item, current string, trail map[string]bool, params map[string]string) []Item {
+	built := make([]Item, len(items))
+	for i, item := range items {
+		item.Children = build(item.Children, current, trail, params)
+		if item.URL == "" && item.Route != "" {
+			item.URL = url(item.Route, item.Params, params)
+		}
+		item.Active = item.Route != "" && (item.Route == current || (!item.Exact && trail[item.Route]))
+		for _, child := range item.Children {
+			item.Active = item.Active || child.Active
+		}
+		built[i] = item
+	}
+	sort.SliceStable(built, func(i, j int) bool {
+		return built[i].Order < built[j].Order
+	})
+	return built
+}
+
+// url generates the url of a named route, the params it needs that aren't given
+// are taken from the current request
+func url(name string, given map[string]string, current map[string]string) string {
+	route := routing.Named(name)
+	if route == nil {
+		log.Printf("menus: no route is named %s", name)
+		return ""
+	}
+	params := map[string]string{}
+	for _, param := range route.Params() {
+		if value, ok := given[param]; ok {
+			params[param] = value
+		} else if value, ok := current[param]; ok {
+			params[param] = value
+		}
+	}
+	path, err := routing.URL(name, params, nil)
+	if err != nil {
+		log.Printf("menus: %v", err)
+	}
+	return path
+}
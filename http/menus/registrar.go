@@ -0,0 +1,14 @@
+// Copyright 2021 Harran Ali <harran.m@gmail.com>. All rights reserved.
+// Use of this source code is governed by MIT-style
+// license that can be found in the LICENSE file.
+
+package menus
+
+// RegisterMenus registers the app's menus and breadcrumbs
+func RegisterMenus() {
+	// Register your menus and breadcrumbs here, e.g:
+	// Add("main", Item{Label: "Home", Route: "home", Exact: true}, Item{Label: "Posts", Route: "posts.index", Order: 10})
+	// Breadcrumb("home", "", "Home")
+	// Breadcrumb("posts.index", "home", "Posts")
+	// Breadcrumb("posts.show", "posts.index", "Post")
+}
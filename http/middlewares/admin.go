@@ -0,0 +1,30 @@
+// Copyright 2021 Harran Ali <harran.m@gmail.com>. All rights reserved.
+// Use of this source code is governed by MIT-style
+// license that can be found in the LICENSE file.
+
+package middlewares
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"os"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gocondor/gocondor/i18n"
+)
+
+// AdminToken checks the request carries the APP_ADMIN_TOKEN in the X-Admin-Token header,
+// all requests are rejected while APP_ADMIN_TOKEN is empty
+var AdminToken gin.HandlerFunc = func(c *gin.Context) {
+	token := os.Getenv("APP_ADMIN_TOKEN")
+	given := c.GetHeader("X-Admin-Token")
+	if token == "" || subtle.ConstantTimeCompare([]byte(token), []byte(given)) != 1 {
+		c.AbortWithStatusJSON(http.StatusForbidden, gin.H{
+			"message": i18n.T(i18n.Locale(c), "error.forbidden", nil),
+		})
+		return
+	}
+
+	// Pass on to the next-in-chain
+	c.Next()
+}
@@ -0,0 +1,35 @@
+// Copyright 2021 Harran Ali <harran.m@gmail.com>. All rights reserved.
+// Use of this source code is governed by MIT-style
+// license that can be found in the LICENSE file.
+
+package middlewares
+
+import (
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gocondor/gocondor/http/analytics"
+	"github.com/gocondor/gocondor/http/privacy"
+)
+
+// Analytics records every request in the analytics collector,
+// requests of clients sending DNT or Sec-GPC are not recorded
+var Analytics gin.HandlerFunc = func(c *gin.Context) {
+	if privacy.DoNotTrack(c) {
+		c.Next()
+		return
+	}
+	start := time.Now()
+
+	// Pass on to the next-in-chain
+	c.Next()
+
+	analytics.Resolve().Collect(analytics.Event{
+		Time:      start,
+		Method:    c.Request.Method,
+		Route:     c.FullPath(),
+		Status:    c.Writer.Status(),
+		LatencyMS: float64(time.Since(start)) / float64(time.Millisecond),
+		Client:    c.ClientIP(),
+	})
+}
@@ -0,0 +1,56 @@
+// Copyright 2021 Harran Ali <harran.m@gmail.com>. All rights reserved.
+// Use of this source code is governed by MIT-style
+// license that can be found in the LICENSE file.
+
+package middlewares
+
+import (
+	"log"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gocondor/gocondor/i18n"
+)
+
+// BodyLimit rejects the request bodies larger than limit bytes with 413, bodies without
+// a Content-Length are cut at the limit and fail to bind with http.MaxBytesReader's error
+func BodyLimit(limit int64) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.Request.ContentLength > limit {
+			c.Header("Connection", "close")
+			c.AbortWithStatusJSON(http.StatusRequestEntityTooLarge, gin.H{
+				"message": i18n.T(i18n.Locale(c), "error.body_too_large", nil),
+			})
+			return
+		}
+		if c.Request.Body != nil {
+			c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, limit)
+		}
+		c.Next()
+	}
+}
+
+// MaxRequestBody returns the body limit in APP_MAX_REQUEST_BODY, e.g: 1048576, 512KB, 10MB or 1GB,
+// 0 means no limit
+func MaxRequestBody() int64 {
+	value := strings.ToUpper(strings.TrimSpace(os.Getenv("APP_MAX_REQUEST_BODY")))
+	if value == "" {
+		return 0
+	}
+	multiplier := int64(1)
+	for suffix, m := range map[string]int64{"KB": 1 << 10, "MB": 1 << 20, "GB": 1 << 30} {
+		if strings.HasSuffix(value, suffix) {
+			multiplier = m
+			value = strings.TrimSpace(strings.TrimSuffix(value, suffix))
+			break
+		}
+	}
+	size, err := strconv.ParseInt(value, 10, 64)
+	if err != nil || size < 0 {
+		log.Fatalf("invalid APP_MAX_REQUEST_BODY \"%s\"", os.Getenv("APP_MAX_REQUEST_BODY"))
+	}
+	return size * multiplier
+}
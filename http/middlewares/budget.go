@@ -0,0 +1,28 @@
+// Copyright 2021 Harran Ali <harran.m@gmail.com>. All rights reserved.
+// Use of this source code is governed by MIT-style
+// license that can be found in the LICENSE file.
+
+package middlewares
+
+import (
+	"context"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gocondor/gocondor/http/budget"
+)
+
+// Budget gives the request a deadline split across the given number of downstream calls, e.g:
+//
+//	router.Get("/orders/:id", middlewares.Budget(2*time.Second, 3), handlers.OrdersShow)
+//
+// the handler then takes a context per call with budget.Next(c)
+func Budget(timeout time.Duration, calls int) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		b := budget.Start(c, timeout, calls)
+		ctx, cancel := context.WithTimeout(c.Request.Context(), b.Remaining())
+		defer cancel()
+		c.Request = c.Request.WithContext(ctx)
+		c.Next()
+	}
+}
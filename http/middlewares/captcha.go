@@ -0,0 +1,55 @@
+// Copyright 2021 Harran Ali <harran.m@gmail.com>. All rights reserved.
+// Use of this source code is governed by MIT-style
+// license that can be found in the LICENSE file.
+
+package middlewares
+
+import (
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gocondor/gocondor/captcha"
+	"github.com/gocondor/gocondor/http/response"
+	"github.com/gocondor/gocondor/i18n"
+)
+
+// Captcha rejects the requests without a captcha of CAPTCHA_PROVIDER solved for the action,
+// the token is read from the provider's form field or the X-Captcha-Token header, e.g:
+//
+//	router.Post("/signup", middlewares.Captcha("signup"), Signup)
+func Captcha(action string) gin.HandlerFunc {
+	verifier, ok, err := captcha.FromEnv()
+	if err != nil {
+		log.Fatal(err)
+	}
+	if !ok {
+		log.Fatal("the captcha middleware requires CAPTCHA_PROVIDER")
+	}
+	return VerifyCaptcha(verifier, action)
+}
+
+// VerifyCaptcha is Captcha with its own verifier, e.g: a stricter score for the payments
+func VerifyCaptcha(verifier captcha.Verifier, action string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		token := c.GetHeader("X-Captcha-Token")
+		if token == "" {
+			token = c.PostForm(verifier.Provider.Field)
+		}
+
+		err := verifier.Check(c.Request.Context(), token, c.ClientIP(), action)
+		if errors.Is(err, captcha.ErrFailed) {
+			response.AbortWithValidationError(c, err)
+			return
+		}
+		if err != nil {
+			log.Printf("captcha: %v", err)
+			c.AbortWithStatusJSON(http.StatusServiceUnavailable, gin.H{
+				"message": i18n.T(i18n.Locale(c), "error.internal", nil),
+			})
+			return
+		}
+		c.Next()
+	}
+}
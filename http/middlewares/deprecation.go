@@ -0,0 +1,69 @@
+// Copyright 2021 Harran Ali <harran.m@gmail.com>. All rights reserved.
+// Use of this source code is governed by MIT-style
+// license that can be found in the LICENSE file.
+
+package middlewares
+
+import (
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Deprecation describes a deprecated route
+type Deprecation struct {
+	// Since is the date the route got deprecated, zero means just "deprecated"
+	Since time.Time
+	// Sunset is the date the route stops working, zero if not planned yet
+	Sunset time.Time
+	// Replacement is a link to the route replacing the deprecated one
+	Replacement string
+}
+
+var (
+	deprecatedMu    sync.Mutex
+	deprecatedUsage = map[string]uint64{}
+)
+
+// Deprecated marks the route it's attached to as deprecated by adding
+// the Deprecation, Sunset and Link headers to the response, e.g:
+//
+//	router.Get("/v1/users", middlewares.Deprecated(middlewares.Deprecation{Replacement: "/v2/users"}), handlers.UsersIndex)
+func Deprecated(d Deprecation) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if d.Since.IsZero() {
+			c.Header("Deprecation", "true")
+		} else {
+			c.Header("Deprecation", d.Since.UTC().Format(http.TimeFormat))
+		}
+		if !d.Sunset.IsZero() {
+			c.Header("Sunset", d.Sunset.UTC().Format(http.TimeFormat))
+		}
+		if d.Replacement != "" {
+			c.Header("Link", fmt.Sprintf("<%s>; rel=\"successor-version\"", d.Replacement))
+		}
+
+		// count the usage of the deprecated route
+		deprecatedMu.Lock()
+		deprecatedUsage[c.Request.Method+" "+c.FullPath()]++
+		deprecatedMu.Unlock()
+
+		// Pass on to the next-in-chain
+		c.Next()
+	}
+}
+
+// DeprecatedUsage returns the number of requests received by each deprecated route
+func DeprecatedUsage() map[string]uint64 {
+	deprecatedMu.Lock()
+	defer deprecatedMu.Unlock()
+
+	usage := make(map[string]uint64, len(deprecatedUsage))
+	for route, count := range deprecatedUsage {
+		usage[route] = count
+	}
+	return usage
+}
@@ -0,0 +1,185 @@
+// Copyright 2021 Harran Ali <harran.m@gmail.com>. All rights reserved.
+// Use of this source code is governed by MIT-style
+// license that can be found in the LICENSE file.
+
+package middlewares
+
+import (
+	"bytes"
+	"encoding/json"
+	"html/template"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"runtime/debug"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gocondor/gocondor/http/menus"
+	"github.com/gocondor/gocondor/http/routing"
+	"github.com/gocondor/gocondor/i18n"
+)
+
+// StatusPageExpired is answered when the session or the form token expired
+const StatusPageExpired = 419
+
+// the statuses with an error page, besides the ones with a template in ERROR_PAGES_DIR,
+// mapped to their default message
+var errorPageMessages = map[int]string{
+	http.StatusNotFound:            "error.not_found",
+	StatusPageExpired:              "error.page_expired",
+	http.StatusTooManyRequests:     "error.too_many_requests",
+	http.StatusInternalServerError: "error.internal",
+}
+
+// the page of the statuses without their own template, the pages keep the error status
+// so the search engines don't index them
+var defaultErrorPage = template.Must(template.New("error").Parse(`<!DOCTYPE html>
+<html>
+<head><meta charset="utf-8"><meta name="robots" content="noindex"><title>{{.Status}} {{.Title}}</title></head>
+<body><h1>{{.Title}}</h1><p>{{.Message}}</p></body>
+</html>
+`))
+
+// ErrorPages renders the error responses as html pages for the browsers, the other clients
+// keep getting json, the page of a status is the template <status>.html of ERROR_PAGES_DIR,
+// then error.html, then the built-in page, the templates get .Status, .Title and .Message,
+// the menus of menus.Data and the route function of routing.Funcs
+func ErrorPages() gin.HandlerFunc {
+	pages := map[int]*template.Template{}
+	fallback := defaultErrorPage
+	if dir := os.Getenv("ERROR_PAGES_DIR"); dir != "" {
+		files, err := filepath.Glob(filepath.Join(dir, "*.html"))
+		if err != nil {
+			log.Fatalf("error pages: %v", err)
+		}
+		for _, file := range files {
+			page, err := template.New(filepath.Base(file)).Funcs(routing.Funcs()).ParseFiles(file)
+			if err != nil {
+				log.Fatalf("error pages: %v", err)
+			}
+			name := strings.TrimSuffix(filepath.Base(file), ".html")
+			if name == "error" {
+				fallback = page
+				continue
+			}
+			if status, err := strconv.Atoi(name); err == nil {
+				pages[status] = page
+			}
+		}
+	}
+	pageOf := func(status int) *template.Template {
+		if page, ok := pages[status]; ok {
+			return page
+		}
+		if _, ok := errorPageMessages[status]; ok {
+			return fallback
+		}
+		return nil
+	}
+
+	return func(c *gin.Context) {
+		if c.NegotiateFormat(gin.MIMEJSON, gin.MIMEHTML) != gin.MIMEHTML {
+			c.Next()
+			return
+		}
+
+		original := c.Writer
+		w := &errorPageWriter{ResponseWriter: original, pageOf: pageOf}
+		c.Writer = w
+		defer func() {
+			c.Writer = original
+			if r := recover(); r != nil {
+				if r == http.ErrAbortHandler {
+					panic(r)
+				}
+				// the panics get the 500 page instead of the blank response of the recovery
+				log.Printf("panic recovered: %v\n%s", r, debug.Stack())
+				c.Abort()
+				renderErrorPage(c, original, pageOf(http.StatusInternalServerError), http.StatusInternalServerError, "")
+			}
+		}()
+		c.Next()
+
+		// the errors without a body, like the unknown routes, get their page too
+		status := w.status
+		if status == 0 && !original.Written() && pageOf(original.Status()) != nil {
+			status = original.Status()
+		}
+		if status == 0 {
+			return
+		}
+
+		var body struct {
+			Message string `json:"message"`
+		}
+		json.Unmarshal(w.body.Bytes(), &body)
+		renderErrorPage(c, original, pageOf(status), status, body.Message)
+	}
+}
+
+// renderErrorPage writes the page of the status, the 5xx pages don't show the message
+// of the response as it may leak internals
+func renderErrorPage(c *gin.Context, w gin.ResponseWriter, page *template.Template, status int, message string) {
+	if message == "" || status >= http.StatusInternalServerError {
+		message = i18n.T(i18n.Locale(c), errorPageMessages[status], nil)
+	}
+	title := http.StatusText(status)
+	if status == StatusPageExpired {
+		title = "Page Expired"
+	}
+
+	var body bytes.Buffer
+	data := menus.With(c, gin.H{"Status": status, "Title": title, "Message": message})
+	if err := page.Execute(&body, data); err != nil {
+		log.Printf("error page %d: %v", status, err)
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Header().Del("Content-Length")
+	w.WriteHeader(status)
+	w.Write(body.Bytes())
+}
+
+// errorPageWriter holds back the responses of the statuses with an error page
+// so they're replaced with the page
+type errorPageWriter struct {
+	gin.ResponseWriter
+	pageOf func(status int) *template.Template
+	status int
+	body   bytes.Buffer
+}
+
+func (w *errorPageWriter) WriteHeader(status int) {
+	if !w.ResponseWriter.Written() && w.pageOf(status) != nil {
+		w.status = status
+		return
+	}
+	w.status = 0
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *errorPageWriter) Write(data []byte) (int, error) {
+	if w.status != 0 {
+		return w.body.Write(data)
+	}
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *errorPageWriter) WriteString(s string) (int, error) {
+	return w.Write([]byte(s))
+}
+
+func (w *errorPageWriter) WriteHeaderNow() {
+	if w.status == 0 {
+		w.ResponseWriter.WriteHeaderNow()
+	}
+}
+
+func (w *errorPageWriter) Status() int {
+	if w.status != 0 {
+		return w.status
+	}
+	return w.ResponseWriter.Status()
+}
@@ -0,0 +1,90 @@
+// Copyright 2021 Harran Ali <harran.m@gmail.com>. All rights reserved.
+// Use of this source code is governed by MIT-style
+// license that can be found in the LICENSE file.
+
+package middlewares
+
+import (
+	"bytes"
+	"crypto/subtle"
+	"html/template"
+	"log"
+	"net/http"
+	"os"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gocondor/gocondor/i18n"
+	"github.com/gocondor/gocondor/maintenance"
+)
+
+// MaintenanceCookie is the name of the cookie letting its holder through the maintenance
+const MaintenanceCookie = "maintenance_bypass"
+
+// maintenanceCookieMaxAge is how long the bypass cookie lasts, twelve hours
+const maintenanceCookieMaxAge = 12 * 60 * 60
+
+// the page served to browsers when MAINTENANCE_TEMPLATE isn't set
+var defaultMaintenancePage = template.Must(template.New("maintenance").Parse(`<!DOCTYPE html>
+<html>
+<head><meta charset="utf-8"><title>Down for maintenance</title></head>
+<body><h1>Down for maintenance</h1><p>{{.Message}}</p></body>
+</html>
+`))
+
+// Maintenance answers 503 while the app is down for maintenance, except for the ips in the
+// allow list and the holders of the bypass cookie, visiting /{secret} hands out the cookie,
+// browsers get the html page of MAINTENANCE_TEMPLATE and the others get json
+func Maintenance() gin.HandlerFunc {
+	page := defaultMaintenancePage
+	if path := os.Getenv("MAINTENANCE_TEMPLATE"); path != "" {
+		var err error
+		if page, err = template.ParseFiles(path); err != nil {
+			log.Fatalf("maintenance template: %v", err)
+		}
+	}
+
+	return func(c *gin.Context) {
+		state, down := maintenance.Current()
+		// the liveness probe keeps passing so the instances aren't restarted
+		if !down || c.Request.URL.Path == "/healthz" || state.Allowed(c.ClientIP()) {
+			c.Next()
+			return
+		}
+
+		if token := state.BypassToken(); token != "" {
+			if c.Request.URL.Path == "/"+state.Secret {
+				c.SetCookie(MaintenanceCookie, token, maintenanceCookieMaxAge, "/", "", c.Request.TLS != nil, true)
+				c.Redirect(http.StatusFound, "/")
+				c.Abort()
+				return
+			}
+			cookie, err := c.Cookie(MaintenanceCookie)
+			if err == nil && subtle.ConstantTimeCompare([]byte(cookie), []byte(token)) == 1 {
+				c.Next()
+				return
+			}
+		}
+
+		message := state.Message
+		if message == "" {
+			message = i18n.T(i18n.Locale(c), "error.maintenance", nil)
+		}
+		if state.RetryAfter > 0 {
+			c.Header("Retry-After", strconv.Itoa(state.RetryAfter))
+		}
+
+		if c.NegotiateFormat(gin.MIMEJSON, gin.MIMEHTML) == gin.MIMEHTML {
+			var body bytes.Buffer
+			if err := page.Execute(&body, gin.H{"Message": message, "RetryAfter": state.RetryAfter}); err != nil {
+				log.Printf("maintenance template: %v", err)
+			}
+			c.Data(http.StatusServiceUnavailable, "text/html; charset=utf-8", body.Bytes())
+			c.Abort()
+			return
+		}
+		c.AbortWithStatusJSON(http.StatusServiceUnavailable, gin.H{
+			"message": message,
+		})
+	}
+}
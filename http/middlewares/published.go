@@ -0,0 +1,73 @@
+// Copyright 2021 Harran Ali <harran.m@gmail.com>. All rights reserved.
+// Use of this source code is governed by MIT-style
+// license that can be found in the LICENSE file.
+
+package middlewares
+
+import (
+	"crypto/subtle"
+	"errors"
+	"log"
+	"net/http"
+	"os"
+	"reflect"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gocondor/gocondor/i18n"
+	"gorm.io/gorm"
+)
+
+// PublishedRecord is the context key of the record loaded by Published
+const PublishedRecord = "record"
+
+// publishedChecker is implemented by the models embedding models.Publishable
+type publishedChecker interface {
+	IsPublished() bool
+}
+
+// CanPreview reports whether the request may see the drafts and the scheduled records,
+// by default it requires the X-Admin-Token header, replace it to let your authors preview
+var CanPreview = func(c *gin.Context) bool {
+	token := os.Getenv("APP_ADMIN_TOKEN")
+	given := c.GetHeader("X-Admin-Token")
+	return token != "" && subtle.ConstantTimeCompare([]byte(token), []byte(given)) == 1
+}
+
+// Published loads the record of model identified by the param route param and answers 404
+// while it isn't published, unless the request may preview it, the handler gets the record
+// from the context, e.g:
+//
+//	router.Get("/posts/:id", middlewares.Published(&models.Post{}, "id"), handlers.PostShow)
+//
+//	post := c.MustGet(middlewares.PublishedRecord).(*models.Post)
+func Published(model interface{}, param string) gin.HandlerFunc {
+	modelType := reflect.Indirect(reflect.ValueOf(model)).Type()
+	if _, ok := reflect.New(modelType).Interface().(publishedChecker); !ok {
+		log.Fatalf("middlewares.Published: %s doesn't embed models.Publishable", modelType.Name())
+	}
+
+	return func(c *gin.Context) {
+		record := reflect.New(modelType).Interface()
+		err := gorm.ErrRecordNotFound
+		if id, perr := strconv.ParseUint(c.Param(param), 10, 64); perr == nil {
+			err = DB.First(record, id).Error
+		}
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{
+				"message": i18n.T(i18n.Locale(c), "error.internal", nil),
+			})
+			return
+		}
+		// the unpublished records look like missing ones
+		if err != nil || (!record.(publishedChecker).IsPublished() && !CanPreview(c)) {
+			c.AbortWithStatusJSON(http.StatusNotFound, gin.H{
+				"message": i18n.T(i18n.Locale(c), "error.not_found", nil),
+			})
+			return
+		}
+
+		c.Set(PublishedRecord, record)
+		c.Next()
+	}
+}
@@ -0,0 +1,47 @@
+// Copyright 2021 Harran Ali <harran.m@gmail.com>. All rights reserved.
+// Use of this source code is governed by MIT-style
+// license that can be found in the LICENSE file.
+
+package middlewares
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gocondor/gocondor/http/quota"
+	"github.com/gocondor/gocondor/i18n"
+)
+
+// Quota limits the number of requests a client can make per period, e.g:
+//
+//	router.Get("/reports", middlewares.Quota(1000, quota.Monthly), handlers.ReportsIndex)
+func Quota(limit int64, period quota.Period) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		usage, resetsAt, err := quota.Consume(DB, quota.Client(c), period)
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{
+				"message": i18n.T(i18n.Locale(c), "error.quota_check", nil),
+			})
+			return
+		}
+
+		remaining := limit - usage.Count
+		if remaining < 0 {
+			remaining = 0
+		}
+		c.Header("X-Quota-Limit", strconv.FormatInt(limit, 10))
+		c.Header("X-Quota-Remaining", strconv.FormatInt(remaining, 10))
+		c.Header("X-Quota-Reset", strconv.FormatInt(resetsAt.Unix(), 10))
+
+		if usage.Count > limit {
+			c.AbortWithStatusJSON(http.StatusTooManyRequests, gin.H{
+				"message": i18n.T(i18n.Locale(c), "error.quota_exceeded", nil),
+			})
+			return
+		}
+
+		// Pass on to the next-in-chain
+		c.Next()
+	}
+}
@@ -5,13 +5,40 @@
 package middlewares
 
 import (
+	"log"
+	"os"
+	"strings"
+
+	"github.com/gin-gonic/gin"
 	"github.com/gocondor/core/middlewares"
 )
 
-// RegisterMiddlewares helps you attach middlwares globally
+// available maps the names used in APP_MIDDLEWARES to global middlewares, they run after the ones
+// main.go attaches itself: the metrics, Maintenance, ErrorPages, BodyLimit and the menus.
+// Those aren't listed here because the middlewares of APP_MIDDLEWARES depend on them running first
+// in this order (the timings cover the whole chain, the bodies are limited before they're bound),
+// and each has its own switch, e.g: APP_METRICS_ON, ERROR_PAGES_ON, APP_MAX_REQUEST_BODY, maintenance:down
+var available = map[string]gin.HandlerFunc{
+	"analytics": Analytics,
+	"warmup":    WarmupGate,
+	// Register your middlewares here
+	"example": MiddlewareExample,
+}
+
+// RegisterMiddlewares helps you attach middlwares globally,
+// the middlewares listed in APP_MIDDLEWARES are attached in the listed order
 func RegisterMiddlewares() {
 	mwUtil := middlewares.Resolve()
 
-	// Register your middlewares here
-	mwUtil.Attach(MiddlewareExample)
+	for _, name := range strings.Split(os.Getenv("APP_MIDDLEWARES"), ",") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		mw, ok := available[name]
+		if !ok {
+			log.Fatalf("unknown middleware \"%s\" in APP_MIDDLEWARES", name)
+		}
+		mwUtil.Attach(mw)
+	}
 }
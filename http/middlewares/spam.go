@@ -0,0 +1,32 @@
+// Copyright 2021 Harran Ali <harran.m@gmail.com>. All rights reserved.
+// Use of this source code is governed by MIT-style
+// license that can be found in the LICENSE file.
+
+package middlewares
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/gocondor/gocondor/http/response"
+	"github.com/gocondor/gocondor/spam"
+)
+
+// SpamCheck rejects the form submissions of the kind flagged by the checkers, the registered
+// checkers run when none are given, e.g:
+//
+//	router.Post("/comments", middlewares.SpamCheck("comment"), CommentsStore)
+func SpamCheck(kind string, checkers ...spam.Checker) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if err := spam.Check(c.Request.Context(), spam.FromRequest(c, kind), checkers...); err != nil {
+			response.AbortWithValidationError(c, err)
+			return
+		}
+		c.Next()
+	}
+}
+
+// Honeypot rejects the form submissions filling the field hidden from humans, e.g:
+//
+//	<input type="text" name="website" style="display:none" tabindex="-1" autocomplete="off">
+func Honeypot(field string) gin.HandlerFunc {
+	return SpamCheck("", spam.Honeypot{Field: field})
+}
@@ -0,0 +1,25 @@
+// Copyright 2021 Harran Ali <harran.m@gmail.com>. All rights reserved.
+// Use of this source code is governed by MIT-style
+// license that can be found in the LICENSE file.
+
+package middlewares
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gocondor/gocondor/i18n"
+	"github.com/gocondor/gocondor/warmup"
+)
+
+// WarmupGate answers 503 until the warmers are done, so the load balancer retries elsewhere
+var WarmupGate gin.HandlerFunc = func(c *gin.Context) {
+	if !warmup.Ready() {
+		c.Header("Retry-After", "1")
+		c.AbortWithStatusJSON(http.StatusServiceUnavailable, gin.H{
+			"message": i18n.T(i18n.Locale(c), "error.warming_up", nil),
+		})
+		return
+	}
+	c.Next()
+}
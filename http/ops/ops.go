@@ -0,0 +1,20 @@
+// Copyright 2021 Harran Ali <harran.m@gmail.com>. All rights reserved.
+// Use of this source code is governed by MIT-style
+// license that can be found in the LICENSE file.
+
+package ops
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/gocondor/gocondor/scrubber"
+)
+
+// Engine builds the engine of the ops listener, it shares the app's services
+// but has its own routes and middlewares, and isn't reachable from the public port
+func Engine() *gin.Engine {
+	engine := gin.New()
+	logs := scrubber.Resolve()
+	engine.Use(gin.LoggerWithWriter(logs.Writer(gin.DefaultWriter)), gin.RecoveryWithWriter(logs.Writer(gin.DefaultErrorWriter)))
+	RegisterOpsRoutes(engine)
+	return engine
+}
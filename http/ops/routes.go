@@ -0,0 +1,20 @@
+// Copyright 2021 Harran Ali <harran.m@gmail.com>. All rights reserved.
+// Use of this source code is governed by MIT-style
+// license that can be found in the LICENSE file.
+
+package ops
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/gocondor/gocondor/http/health"
+	"github.com/gocondor/gocondor/metrics"
+)
+
+// RegisterOpsRoutes registers the routes served on APP_INTERNAL_ADDR
+func RegisterOpsRoutes(router *gin.Engine) {
+	router.GET("/healthz", health.Healthz)
+	router.GET("/readyz", health.Readyz)
+	router.GET("/metrics", metrics.Handler)
+
+	// Define your ops routes here
+}
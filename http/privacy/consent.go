@@ -0,0 +1,73 @@
+// Copyright 2021 Harran Ali <harran.m@gmail.com>. All rights reserved.
+// Use of this source code is governed by MIT-style
+// license that can be found in the LICENSE file.
+
+package privacy
+
+import (
+	"os"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ConsentCookie is the name of the cookie holding the granted consent categories
+const ConsentCookie = "consent"
+
+// consentMaxAge is how long a consent decision is remembered, one year
+const consentMaxAge = 365 * 24 * 60 * 60
+
+// Categories are the consent categories the clients may grant, append the ones of the app, e.g:
+//
+//	privacy.Categories = append(privacy.Categories, "personalization")
+var Categories = []string{"necessary", "analytics", "marketing"}
+
+// KnownCategory reports whether the category is one of Categories
+func KnownCategory(category string) bool {
+	for _, known := range Categories {
+		if known == category {
+			return true
+		}
+	}
+	return false
+}
+
+// Consents returns the consent categories the client granted, e.g "analytics", "marketing"
+func Consents(c *gin.Context) []string {
+	value, err := c.Cookie(ConsentCookie)
+	if err != nil || value == "" {
+		return []string{}
+	}
+	return strings.Split(value, ",")
+}
+
+// HasConsent reports whether the client granted the consent category
+func HasConsent(c *gin.Context, category string) bool {
+	for _, granted := range Consents(c) {
+		if granted == category {
+			return true
+		}
+	}
+	return false
+}
+
+// SetConsents stores the granted consent categories in the consent cookie, granting no
+// categories revokes the consent, the cookie is only sent over https when APP_HTTPS_ON is true
+func SetConsents(c *gin.Context, categories []string) {
+	secure := os.Getenv("APP_HTTPS_ON") == "true"
+	if len(categories) == 0 {
+		c.SetCookie(ConsentCookie, "", -1, "/", "", secure, true)
+		return
+	}
+	c.SetCookie(ConsentCookie, strings.Join(categories, ","), consentMaxAge, "/", "", secure, true)
+}
+
+// DoNotTrack reports whether the client asked not to be tracked with DNT or Sec-GPC
+func DoNotTrack(c *gin.Context) bool {
+	return c.GetHeader("DNT") == "1" || c.GetHeader("Sec-GPC") == "1"
+}
+
+// TrackingAllowed reports whether the client can be tracked for the consent category
+func TrackingAllowed(c *gin.Context, category string) bool {
+	return !DoNotTrack(c) && HasConsent(c, category)
+}
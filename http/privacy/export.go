@@ -0,0 +1,56 @@
+// Copyright 2021 Harran Ali <harran.m@gmail.com>. All rights reserved.
+// Use of this source code is governed by MIT-style
+// license that can be found in the LICENSE file.
+
+package privacy
+
+import (
+	"sync"
+
+	"github.com/gocondor/gocondor/models"
+	"gorm.io/gorm"
+)
+
+// Exporter returns the records stored about the user
+type Exporter func(db *gorm.DB, userID uint) (interface{}, error)
+
+var (
+	exportersMu sync.Mutex
+	exporters   = map[string]Exporter{
+		"user": exportUser,
+	}
+)
+
+// RegisterExporter adds the records returned by exporter to the user data export under name
+func RegisterExporter(name string, exporter Exporter) {
+	exportersMu.Lock()
+	defer exportersMu.Unlock()
+
+	exporters[name] = exporter
+}
+
+// Export collects the records of all registered exporters about the user
+func Export(db *gorm.DB, userID uint) (map[string]interface{}, error) {
+	exportersMu.Lock()
+	defer exportersMu.Unlock()
+
+	data := make(map[string]interface{}, len(exporters))
+	for name, exporter := range exporters {
+		records, err := exporter(db, userID)
+		if err != nil {
+			return nil, err
+		}
+		data[name] = records
+	}
+
+	return data, nil
+}
+
+// exportUser exports the user's own record
+func exportUser(db *gorm.DB, userID uint) (interface{}, error) {
+	var user models.User
+	res := db.First(&user, userID)
+	user.Password = ""
+
+	return user, res.Error
+}
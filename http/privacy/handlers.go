@@ -0,0 +1,78 @@
+// Copyright 2021 Harran Ali <harran.m@gmail.com>. All rights reserved.
+// Use of this source code is governed by MIT-style
+// license that can be found in the LICENSE file.
+
+package privacy
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gocondor/core/database"
+	"github.com/gocondor/gocondor/http/authentication"
+	"github.com/gocondor/gocondor/i18n"
+)
+
+// ConsentInput is the consent decision sent by the client
+type ConsentInput struct {
+	Categories []string `form:"categories" json:"categories"`
+}
+
+// ConsentShow shows the consent categories the client granted
+func ConsentShow(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{
+		"data": gin.H{
+			"categories": Consents(c),
+			"doNotTrack": DoNotTrack(c),
+		},
+	})
+}
+
+// ConsentUpdate stores the client's consent decision
+func ConsentUpdate(c *gin.Context) {
+	var input ConsentInput
+	if err := c.ShouldBind(&input); err != nil {
+		c.AbortWithStatusJSON(http.StatusUnprocessableEntity, gin.H{
+			"message": err.Error(),
+		})
+		return
+	}
+
+	for _, category := range input.Categories {
+		if !KnownCategory(category) {
+			c.AbortWithStatusJSON(http.StatusUnprocessableEntity, gin.H{
+				"message": i18n.T(i18n.Locale(c), "validation.consent", map[string]string{"category": category}),
+			})
+			return
+		}
+	}
+
+	SetConsents(c, input.Categories)
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "consent updated successfully",
+	})
+}
+
+// ExportShow exports the data stored about the requesting user
+func ExportShow(c *gin.Context) {
+	userID, ok := authentication.UserID(c)
+	if !ok {
+		c.AbortWithStatusJSON(http.StatusForbidden, gin.H{
+			"message": "forbidden",
+		})
+		return
+	}
+
+	data, err := Export(database.Resolve(), userID)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{
+			"message": "something went wrong",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": data,
+	})
+}
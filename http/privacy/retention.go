@@ -0,0 +1,62 @@
+// Copyright 2021 Harran Ali <harran.m@gmail.com>. All rights reserved.
+// Use of this source code is governed by MIT-style
+// license that can be found in the LICENSE file.
+
+package privacy
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/gocondor/gocondor/models"
+	"gorm.io/gorm"
+)
+
+// Anonymize replaces the personal data of the user with placeholders
+func Anonymize(db *gorm.DB, userID uint) error {
+	return db.Unscoped().Model(&models.User{}).Where("id = ?", userID).Updates(map[string]interface{}{
+		"name":     "anonymized",
+		"email":    fmt.Sprintf("anonymized-%d@invalid", userID),
+		"password": "",
+	}).Error
+}
+
+// AnonymizeDeletedUsers anonymizes the users deleted more than olderThan ago
+func AnonymizeDeletedUsers(db *gorm.DB, olderThan time.Duration) error {
+	var ids []uint
+	res := db.Unscoped().Model(&models.User{}).
+		Where("deleted_at IS NOT NULL AND deleted_at < ? AND name <> ?", time.Now().Add(-olderThan), "anonymized").
+		Pluck("id", &ids)
+	if res.Error != nil {
+		return res.Error
+	}
+
+	for _, id := range ids {
+		if err := Anonymize(db, id); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// ScheduleRetention runs AnonymizeDeletedUsers every interval in the background until ctx is done
+func ScheduleRetention(ctx context.Context, db *gorm.DB, interval time.Duration, olderThan time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if err := AnonymizeDeletedUsers(db.WithContext(ctx), olderThan); err != nil && ctx.Err() == nil {
+					log.Printf("privacy: retention run failed: %v", err)
+				}
+			}
+		}
+	}()
+}
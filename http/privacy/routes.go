@@ -0,0 +1,16 @@
+// Copyright 2021 Harran Ali <harran.m@gmail.com>. All rights reserved.
+// Use of this source code is governed by MIT-style
+// license that can be found in the LICENSE file.
+
+package privacy
+
+import "github.com/gocondor/core/routing"
+
+// RegisterPrivacyRoutes registers the consent and data export endpoints
+func RegisterPrivacyRoutes() {
+	router := routing.Resolve()
+
+	router.Get("/privacy/consent", ConsentShow)
+	router.Post("/privacy/consent", ConsentUpdate)
+	router.Get("/privacy/export", ExportShow)
+}
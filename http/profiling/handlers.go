@@ -0,0 +1,31 @@
+// Copyright 2021 Harran Ali <harran.m@gmail.com>. All rights reserved.
+// Use of this source code is governed by MIT-style
+// license that can be found in the LICENSE file.
+
+package profiling
+
+import (
+	"net/http/pprof"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Index lists the available profiles
+func Index(c *gin.Context) {
+	// pprof.Index reads the profile name from the path after /debug/pprof/
+	c.Request.URL.Path = "/debug/pprof/"
+	pprof.Index(c.Writer, c.Request)
+}
+
+// Profile serves a named profile like heap, goroutine or allocs
+func Profile(c *gin.Context) {
+	pprof.Handler(c.Param("name")).ServeHTTP(c.Writer, c.Request)
+}
+
+// the handlers of the profiles that aren't served by name
+var (
+	CPU     = gin.WrapF(pprof.Profile)
+	Trace   = gin.WrapF(pprof.Trace)
+	Cmdline = gin.WrapF(pprof.Cmdline)
+	Symbol  = gin.WrapF(pprof.Symbol)
+)
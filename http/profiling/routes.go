@@ -0,0 +1,36 @@
+// Copyright 2021 Harran Ali <harran.m@gmail.com>. All rights reserved.
+// Use of this source code is governed by MIT-style
+// license that can be found in the LICENSE file.
+
+package profiling
+
+import (
+	"os"
+	"strings"
+
+	"github.com/gocondor/core/routing"
+	"github.com/gocondor/gocondor/http/middlewares"
+)
+
+// RegisterProfilingRoutes registers the pprof endpoints under APP_PPROF_PREFIX,
+// they require the X-Admin-Token header, go tool pprof can't send it so the profiles are
+// downloaded first, e.g:
+//
+//	curl -H "X-Admin-Token: ..." -o cpu.out "http://localhost/debug/pprof/profile?seconds=30"
+//	go tool pprof -http=:8080 cpu.out
+func RegisterProfilingRoutes() {
+	router := routing.Resolve()
+
+	prefix := strings.TrimRight(os.Getenv("APP_PPROF_PREFIX"), "/")
+	if prefix == "" {
+		prefix = "/debug/pprof"
+	}
+
+	router.Get(prefix+"/", middlewares.AdminToken, Index)
+	router.Get(prefix+"/cmdline", middlewares.AdminToken, Cmdline)
+	router.Get(prefix+"/profile", middlewares.AdminToken, CPU)
+	router.Get(prefix+"/symbol", middlewares.AdminToken, Symbol)
+	router.Post(prefix+"/symbol", middlewares.AdminToken, Symbol)
+	router.Get(prefix+"/trace", middlewares.AdminToken, Trace)
+	router.Get(prefix+"/:name", middlewares.AdminToken, Profile)
+}
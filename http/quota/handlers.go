@@ -0,0 +1,38 @@
+// Copyright 2021 Harran Ali <harran.m@gmail.com>. All rights reserved.
+// Use of this source code is governed by MIT-style
+// license that can be found in the LICENSE file.
+
+package quota
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gocondor/core/database"
+)
+
+// UsageShow shows the requesting client's usage of the daily and monthly quotas
+func UsageShow(c *gin.Context) {
+	DB := database.Resolve()
+	client := Client(c)
+
+	data := gin.H{}
+	for _, p := range []Period{Daily, Monthly} {
+		usage, resetsAt, err := Usage(DB, client, p)
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{
+				"message": "something went wrong",
+			})
+			return
+		}
+
+		data[string(p)] = gin.H{
+			"used":     usage.Count,
+			"resetsAt": resetsAt,
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": data,
+	})
+}
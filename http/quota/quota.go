@@ -0,0 +1,102 @@
+// Copyright 2021 Harran Ali <harran.m@gmail.com>. All rights reserved.
+// Use of this source code is governed by MIT-style
+// license that can be found in the LICENSE file.
+
+package quota
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gocondor/gocondor/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Period is the window a quota applies to
+type Period string
+
+const (
+	// Daily quotas reset at midnight UTC
+	Daily Period = "daily"
+	// Monthly quotas reset on the first day of the month UTC
+	Monthly Period = "monthly"
+)
+
+// window returns the key of the period containing t and the time it resets at
+func (p Period) window(t time.Time) (string, time.Time) {
+	t = t.UTC()
+	if p == Monthly {
+		start := time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+		return string(p) + ":" + start.Format("2006-01"), start.AddDate(0, 1, 0)
+	}
+
+	start := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+	return string(p) + ":" + start.Format("2006-01-02"), start.AddDate(0, 0, 1)
+}
+
+// ValidKey reports whether key is an api key issued to a client, by default the keys are the
+// comma separated QUOTA_API_KEYS, replace it if your app stores its keys elsewhere
+var ValidKey = func(key string) bool {
+	valid := false
+	for _, issued := range strings.Split(os.Getenv("QUOTA_API_KEYS"), ",") {
+		issued = strings.TrimSpace(issued)
+		// compare with all the keys so the time taken doesn't tell which one matched
+		if issued != "" && subtle.ConstantTimeCompare([]byte(issued), []byte(key)) == 1 {
+			valid = true
+		}
+	}
+	return valid
+}
+
+// Client returns the identifier quotas are tracked by, the X-API-Key header when ValidKey
+// accepts it, otherwise the client ip, so made up keys don't get a fresh quota each,
+// the keys are hashed to not store them in the usage records
+func Client(c *gin.Context) string {
+	if key := c.GetHeader("X-API-Key"); key != "" && ValidKey(key) {
+		sum := sha256.Sum256([]byte(key))
+		return "key:" + hex.EncodeToString(sum[:16])
+	}
+	return "ip:" + c.ClientIP()
+}
+
+// Consume records a request of the client in the current period,
+// it returns the updated usage and the time the period resets at
+func Consume(db *gorm.DB, client string, p Period) (models.QuotaUsage, time.Time, error) {
+	period, resetsAt := p.window(time.Now())
+	usage := models.QuotaUsage{Client: client, Period: period}
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		// create the usage record of the period unless it's already there
+		res := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&usage)
+		if res.Error != nil {
+			return res.Error
+		}
+
+		res = tx.Model(&models.QuotaUsage{}).
+			Where("client = ? AND period = ?", client, period).
+			Update("count", gorm.Expr("count + ?", 1))
+		if res.Error != nil {
+			return res.Error
+		}
+
+		return tx.Where("client = ? AND period = ?", client, period).First(&usage).Error
+	})
+
+	return usage, resetsAt, err
+}
+
+// Usage returns the client's usage in the current period and the time the period resets at
+func Usage(db *gorm.DB, client string, p Period) (models.QuotaUsage, time.Time, error) {
+	period, resetsAt := p.window(time.Now())
+	usage := models.QuotaUsage{Client: client, Period: period}
+
+	res := db.Where("client = ? AND period = ?", client, period).Limit(1).Find(&usage)
+
+	return usage, resetsAt, res.Error
+}
@@ -0,0 +1,14 @@
+// Copyright 2021 Harran Ali <harran.m@gmail.com>. All rights reserved.
+// Use of this source code is governed by MIT-style
+// license that can be found in the LICENSE file.
+
+package quota
+
+import "github.com/gocondor/core/routing"
+
+// RegisterQuotaRoutes registers the quota usage endpoint
+func RegisterQuotaRoutes() {
+	router := routing.Resolve()
+
+	router.Get("/quota", UsageShow)
+}
@@ -0,0 +1,65 @@
+// Copyright 2021 Harran Ali <harran.m@gmail.com>. All rights reserved.
+// Use of this source code is governed by MIT-style
+// license that can be found in the LICENSE file.
+
+package response
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gocondor/gocondor/i18n"
+	"github.com/gocondor/gocondor/models"
+	"gorm.io/gorm"
+)
+
+// AbortNotFound aborts the request with 404, the message is translated to the request's locale
+func AbortNotFound(c *gin.Context) {
+	c.AbortWithStatusJSON(http.StatusNotFound, gin.H{
+		"message": i18n.T(i18n.Locale(c), "error.not_found", nil),
+	})
+}
+
+// AbortForbidden aborts the request with 403, the message is translated to the request's locale
+func AbortForbidden(c *gin.Context) {
+	c.AbortWithStatusJSON(http.StatusForbidden, gin.H{
+		"message": i18n.T(i18n.Locale(c), "error.forbidden", nil),
+	})
+}
+
+// AbortWithDBError aborts the request with the status matching the database error,
+// the message is translated to the request's locale
+func AbortWithDBError(c *gin.Context, err error) {
+	locale := i18n.Locale(c)
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		c.AbortWithStatusJSON(http.StatusNotFound, gin.H{
+			"message": i18n.T(locale, "error.not_found", nil),
+		})
+	case errors.Is(err, models.ErrStaleVersion), errors.Is(err, models.ErrStateChanged):
+		c.AbortWithStatusJSON(http.StatusConflict, gin.H{
+			"message": i18n.T(locale, "error.conflict", nil),
+		})
+	case errors.Is(err, models.ErrReadOnly):
+		c.AbortWithStatusJSON(http.StatusServiceUnavailable, gin.H{
+			"message": i18n.T(locale, "error.read_only", nil),
+		})
+	case errors.Is(err, models.ErrIllegalTransition):
+		var transition *models.TransitionError
+		errors.As(err, &transition)
+		message := i18n.T(locale, "validation.transition", map[string]string{
+			"field": transition.Field,
+			"from":  transition.From,
+			"to":    transition.To,
+		})
+		c.AbortWithStatusJSON(http.StatusUnprocessableEntity, gin.H{
+			"message": message,
+			"errors":  gin.H{transition.Field: message},
+		})
+	default:
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{
+			"message": i18n.T(locale, "error.internal", nil),
+		})
+	}
+}
@@ -0,0 +1,21 @@
+// Copyright 2021 Harran Ali <harran.m@gmail.com>. All rights reserved.
+// Use of this source code is governed by MIT-style
+// license that can be found in the LICENSE file.
+
+//go:build !jsoniter
+// +build !jsoniter
+
+package response
+
+import (
+	"encoding/json"
+	"io"
+)
+
+// JSONEngine is the name of the json engine the response helpers are built with
+const JSONEngine = "encoding/json"
+
+// newEncoder returns a json encoder writing to w
+func newEncoder(w io.Writer) encoder {
+	return json.NewEncoder(w)
+}
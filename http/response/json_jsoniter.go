@@ -0,0 +1,24 @@
+// Copyright 2021 Harran Ali <harran.m@gmail.com>. All rights reserved.
+// Use of this source code is governed by MIT-style
+// license that can be found in the LICENSE file.
+
+//go:build jsoniter
+// +build jsoniter
+
+package response
+
+import (
+	"io"
+
+	jsoniter "github.com/json-iterator/go"
+)
+
+// JSONEngine is the name of the json engine the response helpers are built with
+const JSONEngine = "jsoniter"
+
+var json = jsoniter.ConfigCompatibleWithStandardLibrary
+
+// newEncoder returns a json encoder writing to w
+func newEncoder(w io.Writer) encoder {
+	return json.NewEncoder(w)
+}
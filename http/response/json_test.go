@@ -0,0 +1,70 @@
+// Copyright 2021 Harran Ali <harran.m@gmail.com>. All rights reserved.
+// Use of this source code is governed by MIT-style
+// license that can be found in the LICENSE file.
+
+package response
+
+import (
+	"io/ioutil"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// the benchmarks measure the engine the package is built with, compare them with
+//
+//	go test -run '^$' -bench . -benchmem ./http/response > std.txt
+//	go test -run '^$' -bench . -benchmem -tags jsoniter ./http/response > jsoniter.txt
+
+type benchRow struct {
+	ID        uint              `json:"id"`
+	Title     string            `json:"title"`
+	Body      string            `json:"body"`
+	Tags      []string          `json:"tags"`
+	Meta      map[string]string `json:"meta"`
+	Published bool              `json:"published"`
+	CreatedAt time.Time         `json:"created_at"`
+}
+
+func benchRows(n int) []interface{} {
+	rows := make([]interface{}, n)
+	for i := range rows {
+		rows[i] = benchRow{
+			ID:        uint(i + 1),
+			Title:     "a post about the json engines",
+			Body:      "the body of the post, long enough to be escaped <b>and</b> encoded",
+			Tags:      []string{"go", "json", "benchmarks"},
+			Meta:      map[string]string{"lang": "en", "source": "import"},
+			Published: i%2 == 0,
+			CreatedAt: time.Date(2021, 5, 1, 12, 0, 0, 0, time.UTC),
+		}
+	}
+	return rows
+}
+
+func BenchmarkEncode(b *testing.B) {
+	row := benchRows(1)[0]
+	enc := newEncoder(ioutil.Discard)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if err := enc.Encode(row); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkStreamJSON(b *testing.B) {
+	gin.SetMode(gin.TestMode)
+	rows := benchRows(1000)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		c, _ := gin.CreateTestContext(httptest.NewRecorder())
+		if err := StreamJSON(c, SliceIterator(rows)); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
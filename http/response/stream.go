@@ -0,0 +1,126 @@
+// Copyright 2021 Harran Ali <harran.m@gmail.com>. All rights reserved.
+// Use of this source code is governed by MIT-style
+// license that can be found in the LICENSE file.
+
+package response
+
+import (
+	"bufio"
+	"database/sql"
+	"net/http"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// flushEvery is the number of rows written between flushes to the client
+const flushEvery = 500
+
+// writers pools the buffered writers used for streaming to reduce per request allocations
+var writers = sync.Pool{
+	New: func() interface{} {
+		return bufio.NewWriterSize(nil, 32*1024)
+	},
+}
+
+// encoder is implemented by the json encoders of the supported engines
+type encoder interface {
+	Encode(v interface{}) error
+}
+
+// Iterator returns the rows of a streamed response one at a time,
+// ok is false once there are no more rows
+type Iterator func() (row interface{}, ok bool, err error)
+
+// StreamJSON encodes the rows returned by next as a json array one row at a time,
+// so large lists are never materialized in memory.
+// Once the first byte is written the status can't be changed anymore, so if next
+// returns an error the stream is cut short and the error is attached to the context
+func StreamJSON(c *gin.Context, next Iterator) error {
+	c.Header("Content-Type", "application/json; charset=utf-8")
+	c.Status(http.StatusOK)
+
+	w := writers.Get().(*bufio.Writer)
+	w.Reset(c.Writer)
+	defer func() {
+		w.Reset(nil)
+		writers.Put(w)
+	}()
+	enc := newEncoder(w)
+
+	w.WriteByte('[')
+	for i := 0; ; i++ {
+		row, ok, err := next()
+		if err != nil {
+			w.Flush()
+			c.Error(err)
+			return err
+		}
+		if !ok {
+			break
+		}
+		if i > 0 {
+			w.WriteByte(',')
+		}
+		if err := enc.Encode(row); err != nil {
+			w.Flush()
+			c.Error(err)
+			return err
+		}
+		// push what we have so far to the client
+		if (i+1)%flushEvery == 0 {
+			if err := w.Flush(); err != nil {
+				return err
+			}
+			c.Writer.Flush()
+		}
+	}
+	w.WriteByte(']')
+
+	return w.Flush()
+}
+
+// SliceIterator returns an iterator over the given rows
+func SliceIterator(rows []interface{}) Iterator {
+	i := 0
+	return func() (interface{}, bool, error) {
+		if i >= len(rows) {
+			return nil, false, nil
+		}
+		row := rows[i]
+		i++
+		return row, true, nil
+	}
+}
+
+// StreamRows streams the database rows with StreamJSON and closes them once it returns,
+// including when the stream is cut short by an encoding error or a client gone away, e.g:
+//
+//	rows, err := db.Model(&models.Post{}).Rows()
+//	if err != nil {
+//		...
+//	}
+//	response.StreamRows(c, db, rows, func() interface{} { return &models.Post{} })
+func StreamRows(c *gin.Context, db *gorm.DB, rows *sql.Rows, newRow func() interface{}) error {
+	defer rows.Close()
+	return StreamJSON(c, RowsIterator(db, rows, newRow))
+}
+
+// RowsIterator returns an iterator over database rows, newRow must return a pointer
+// to a fresh model for every row, the rows are closed once exhausted or on a scan error,
+// when StreamJSON stops before that the caller closes them, StreamRows does it
+func RowsIterator(db *gorm.DB, rows *sql.Rows, newRow func() interface{}) Iterator {
+	return func() (interface{}, bool, error) {
+		if !rows.Next() {
+			rows.Close()
+			return nil, false, rows.Err()
+		}
+		row := newRow()
+		if err := db.ScanRows(rows, row); err != nil {
+			rows.Close()
+			return nil, false, err
+		}
+		return row, true, nil
+	}
+}
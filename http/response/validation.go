@@ -0,0 +1,87 @@
+// Copyright 2021 Harran Ali <harran.m@gmail.com>. All rights reserved.
+// Use of this source code is governed by MIT-style
+// license that can be found in the LICENSE file.
+
+package response
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"github.com/gocondor/gocondor/captcha"
+	"github.com/gocondor/gocondor/i18n"
+	"github.com/gocondor/gocondor/spam"
+)
+
+// AbortWithValidationError aborts the request with the binding error, validation errors
+// are translated to the request's locale and listed per field
+func AbortWithValidationError(c *gin.Context, err error) {
+	// the body was cut by middlewares.BodyLimit, the error of http.MaxBytesReader may come
+	// wrapped by the binding and has no type to match in this version of go
+	if strings.Contains(err.Error(), "http: request body too large") {
+		c.AbortWithStatusJSON(http.StatusRequestEntityTooLarge, gin.H{
+			"message": i18n.T(i18n.Locale(c), "error.body_too_large", nil),
+		})
+		return
+	}
+
+	// the form was flagged by the spam checkers
+	if errors.Is(err, spam.ErrSpam) {
+		c.AbortWithStatusJSON(http.StatusUnprocessableEntity, gin.H{
+			"message": i18n.T(i18n.Locale(c), "validation.spam", nil),
+		})
+		return
+	}
+
+	// the captcha wasn't solved, it's reported as an error of the captcha field
+	if errors.Is(err, captcha.ErrFailed) {
+		message := i18n.T(i18n.Locale(c), "validation.captcha", nil)
+		c.AbortWithStatusJSON(http.StatusUnprocessableEntity, gin.H{
+			"message": message,
+			"errors":  gin.H{"captcha": message},
+		})
+		return
+	}
+
+	var verrs validator.ValidationErrors
+	if !errors.As(err, &verrs) {
+		c.AbortWithStatusJSON(http.StatusUnprocessableEntity, gin.H{
+			"message": err.Error(),
+		})
+		return
+	}
+
+	locale := i18n.Locale(c)
+	fields := make(map[string]string, len(verrs))
+	message := ""
+	for _, fe := range verrs {
+		fields[fe.Field()] = validationMessage(locale, fe)
+		if message == "" {
+			message = fields[fe.Field()]
+		}
+	}
+
+	c.AbortWithStatusJSON(http.StatusUnprocessableEntity, gin.H{
+		"message": message,
+		"errors":  fields,
+	})
+}
+
+// validationMessage translates the field error, a message for the field and tag
+// (validation.email.required) wins over the message of the tag (validation.required)
+func validationMessage(locale string, fe validator.FieldError) string {
+	params := map[string]string{
+		"field": fe.Field(),
+		"param": fe.Param(),
+	}
+
+	for _, key := range []string{"validation." + fe.Field() + "." + fe.Tag(), "validation." + fe.Tag()} {
+		if i18n.Has(locale, key) {
+			return i18n.T(locale, key, params)
+		}
+	}
+	return i18n.T(locale, "validation.invalid", params)
+}
@@ -5,8 +5,8 @@
 package http
 
 import (
-	"github.com/gocondor/core/routing"
 	"github.com/gocondor/gocondor/http/handlers"
+	"github.com/gocondor/gocondor/http/routing"
 )
 
 // RegisterRoutes to register your routes
@@ -14,5 +14,17 @@ func RegisterRoutes() {
 	router := routing.Resolve()
 
 	//Define your routes here
-	router.Get("/", handlers.HomeShow)
+	router.Get("/", handlers.HomeShow).Name("home")
+
+	// group the routes sharing a prefix and middlewares, e.g:
+	// api := router.Group("/api/v1", middlewares.Auth)
+	// api.Get("/users", handlers.UsersIndex)
+	// api.Get("/users/:id", handlers.UsersShow).Name("users.show")
+	// api.Delete("/users/:id", handlers.UsersDestroy).Use(middlewares.AdminToken)
+
+	// document the routes for the tools reading them with Doc, e.g:
+	// api.Post("/users", handlers.UsersStore).Summary("create a user").Request(UserInput{}).Response(201, models.User{})
+
+	// declare the index, show, store, update and destroy routes of a controller at once, e.g:
+	// api.Resource("/posts", handlers.PostsController{}).Except("destroy").Name("posts")
 }
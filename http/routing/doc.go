@@ -0,0 +1,94 @@
+// Copyright 2021 Harran Ali <harran.m@gmail.com>. All rights reserved.
+// Use of this source code is governed by MIT-style
+// license that can be found in the LICENSE file.
+
+package routing
+
+import (
+	"reflect"
+	"sort"
+)
+
+// Doc is the documentation of a route, it's given with the route's Summary, Description,
+// Request and Response and read by the tools listing or describing the routes, e.g:
+//
+//	router.Post("/posts", handlers.PostsStore).
+//		Summary("create a post").
+//		Request(PostInput{}).
+//		Response(201, models.Post{}).
+//		Response(422, ValidationErrors{})
+type Doc struct {
+	Summary     string
+	Description string
+	// Request is the type of the request's body, nil when it has none
+	Request reflect.Type
+	// Responses are the types of the response's body by status
+	Responses map[int]reflect.Type
+}
+
+// Statuses returns the statuses of the documented responses in order
+func (doc Doc) Statuses() []int {
+	statuses := make([]int, 0, len(doc.Responses))
+	for status := range doc.Responses {
+		statuses = append(statuses, status)
+	}
+	sort.Ints(statuses)
+	return statuses
+}
+
+// Summary sets the one line summary of the route
+func (route *Route) Summary(summary string) *Route {
+	mu.Lock()
+	defer mu.Unlock()
+	route.doc.Summary = summary
+	return route
+}
+
+// Description sets the description of the route
+func (route *Route) Description(description string) *Route {
+	mu.Lock()
+	defer mu.Unlock()
+	route.doc.Description = description
+	return route
+}
+
+// Request sets the type of the request's body to the type of body, a pointer documents its element
+func (route *Route) Request(body interface{}) *Route {
+	mu.Lock()
+	defer mu.Unlock()
+	route.doc.Request = typeOf(body)
+	return route
+}
+
+// Response sets the type of the response's body with status to the type of body, a nil
+// body documents a response without one
+func (route *Route) Response(status int, body interface{}) *Route {
+	mu.Lock()
+	defer mu.Unlock()
+	if route.doc.Responses == nil {
+		route.doc.Responses = map[int]reflect.Type{}
+	}
+	route.doc.Responses[status] = typeOf(body)
+	return route
+}
+
+// Doc returns the documentation of the route
+func (route *Route) Doc() Doc {
+	mu.RLock()
+	defer mu.RUnlock()
+	doc := route.doc
+	doc.Responses = make(map[int]reflect.Type, len(route.doc.Responses))
+	for status, body := range route.doc.Responses {
+		doc.Responses[status] = body
+	}
+	return doc
+}
+
+// typeOf returns the type of v, the element type for pointers, nil for nil
+func typeOf(v interface{}) reflect.Type {
+	t := reflect.TypeOf(v)
+	for t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return t
+}
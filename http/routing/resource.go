@@ -0,0 +1,149 @@
+// Copyright 2021 Harran Ali <harran.m@gmail.com>. All rights reserved.
+// Use of this source code is governed by MIT-style
+// license that can be found in the LICENSE file.
+
+package routing
+
+import (
+	"log"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// the actions of the resource controllers, a controller implements the ones it supports
+type (
+	// Indexer lists the records, GET /users
+	Indexer interface{ Index(c *gin.Context) }
+	// Shower shows a record, GET /users/:id
+	Shower interface{ Show(c *gin.Context) }
+	// Storer creates a record, POST /users
+	Storer interface{ Store(c *gin.Context) }
+	// Updater updates a record, PUT /users/:id
+	Updater interface{ Update(c *gin.Context) }
+	// Destroyer deletes a record, DELETE /users/:id
+	Destroyer interface{ Destroy(c *gin.Context) }
+)
+
+// the actions a resource may have
+var resourceActions = []string{"index", "store", "show", "update", "destroy"}
+
+// Resource is the set of routes declared for a controller by Router.Resource
+type Resource struct {
+	path   string
+	routes map[string]*Route
+}
+
+// Resource declares the conventional routes of the actions the controller implements,
+// the record is identified by the :id param, e.g:
+//
+//	router.Resource("/users", handlers.UsersController{}).Except("destroy").Name("users")
+//
+//	type UsersController struct{}
+//	func (UsersController) Index(c *gin.Context) {}
+//	func (UsersController) Show(c *gin.Context) {}
+func (r *Router) Resource(path string, controller interface{}) *Resource {
+	res := &Resource{path: path, routes: map[string]*Route{}}
+	item := joinPaths(path, ":id")
+	if ctrl, ok := controller.(Indexer); ok {
+		res.routes["index"] = r.Get(path, ctrl.Index)
+	}
+	if ctrl, ok := controller.(Storer); ok {
+		res.routes["store"] = r.Post(path, ctrl.Store)
+	}
+	if ctrl, ok := controller.(Shower); ok {
+		res.routes["show"] = r.Get(item, ctrl.Show)
+	}
+	if ctrl, ok := controller.(Updater); ok {
+		res.routes["update"] = r.Put(item, ctrl.Update)
+	}
+	if ctrl, ok := controller.(Destroyer); ok {
+		res.routes["destroy"] = r.Delete(item, ctrl.Destroy)
+	}
+	if len(res.routes) == 0 {
+		log.Fatalf("the controller of %s has none of the Index, Show, Store, Update and Destroy methods", path)
+	}
+	return res
+}
+
+// Only keeps the routes of the given actions
+func (res *Resource) Only(actions ...string) *Resource {
+	keep := res.actions(actions)
+	for action, route := range res.routes {
+		if !keep[action] {
+			res.drop(action, route)
+		}
+	}
+	return res
+}
+
+// Except drops the routes of the given actions
+func (res *Resource) Except(actions ...string) *Resource {
+	for action := range res.actions(actions) {
+		if route, ok := res.routes[action]; ok {
+			res.drop(action, route)
+		}
+	}
+	return res
+}
+
+// Name names the routes <name>.<action>, e.g: users.index and users.show
+func (res *Resource) Name(name string) *Resource {
+	for action, route := range res.routes {
+		route.Name(name + "." + action)
+	}
+	return res
+}
+
+// Use adds middlewares to all the routes of the resource
+func (res *Resource) Use(middlewares ...gin.HandlerFunc) *Resource {
+	for _, route := range res.routes {
+		route.Use(middlewares...)
+	}
+	return res
+}
+
+// Route returns the route of an action, nil when the resource doesn't have it, e.g:
+//
+//	users.Route("destroy").Use(middlewares.AdminToken)
+func (res *Resource) Route(action string) *Route {
+	return res.routes[action]
+}
+
+// actions checks the action names are known
+func (res *Resource) actions(actions []string) map[string]bool {
+	known := map[string]bool{}
+	for _, action := range resourceActions {
+		known[action] = true
+	}
+	set := map[string]bool{}
+	for _, action := range actions {
+		if !known[action] {
+			log.Fatalf("%s: unknown resource action \"%s\"", res.path, action)
+		}
+		set[action] = true
+	}
+	return set
+}
+
+// drop removes the route of an action from the declared routes
+func (res *Resource) drop(action string, route *Route) {
+	mu.Lock()
+	defer mu.Unlock()
+	for i, declared := range routes {
+		if declared == route {
+			routes = append(routes[:i], routes[i+1:]...)
+			break
+		}
+	}
+	if route.name != "" {
+		delete(names, route.name)
+	}
+	if methods := byPath[route.Path]; methods[strings.ToUpper(route.Method)] == route {
+		delete(methods, strings.ToUpper(route.Method))
+		if len(methods) == 0 {
+			delete(byPath, route.Path)
+		}
+	}
+	delete(res.routes, action)
+}
@@ -0,0 +1,305 @@
+// Copyright 2021 Harran Ali <harran.m@gmail.com>. All rights reserved.
+// Use of this source code is governed by MIT-style
+// license that can be found in the LICENSE file.
+
+package routing
+
+import (
+	"errors"
+	"fmt"
+	"html/template"
+	"log"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+	corerouting "github.com/gocondor/core/routing"
+)
+
+// Router declares routes under a prefix with shared middlewares, it wraps core's router
+// which only takes flat routes, the app's engine reads the declared routes with CoreRoutes
+type Router struct {
+	parent      *Router
+	prefix      string
+	middlewares []gin.HandlerFunc
+}
+
+// Route is a declared route
+type Route struct {
+	Method      string
+	Path        string
+	name        string
+	router      *Router
+	middlewares []gin.HandlerFunc
+	handlers    []gin.HandlerFunc
+	doc         Doc
+}
+
+var mu sync.RWMutex
+var root = &Router{}
+var routes []*Route
+var names = map[string]*Route{}
+
+// the routes by path and upper case method, indexed when they're declared so Current
+// looks them up on every request without allocating
+var byPath = map[string]map[string]*Route{}
+
+// ErrUnknownRoute is returned by URL for the names no route was given
+var ErrUnknownRoute = errors.New("no route has this name")
+
+// Resolve returns the root router, e.g:
+//
+//	router := routing.Resolve()
+//	api := router.Group("/api/v1", middlewares.Auth)
+//	api.Get("/users", handlers.UsersIndex)
+//	admin := api.Group("/admin", middlewares.AdminToken)
+//	admin.Get("/stats", handlers.StatsShow)
+func Resolve() *Router {
+	return root
+}
+
+// Group returns a router nested in r, its routes are prefixed with r's prefix and prefix,
+// and run r's middlewares then middlewares before their handlers
+func (r *Router) Group(prefix string, middlewares ...gin.HandlerFunc) *Router {
+	return &Router{parent: r, prefix: joinPaths(r.prefix, prefix), middlewares: middlewares}
+}
+
+// Use adds middlewares to the router, they run before the handlers of all its routes
+// and of its groups' routes, including the ones declared earlier
+func (r *Router) Use(middlewares ...gin.HandlerFunc) *Router {
+	mu.Lock()
+	defer mu.Unlock()
+	r.middlewares = append(r.middlewares, middlewares...)
+	return r
+}
+
+// Get declares a GET route
+func (r *Router) Get(path string, handlers ...gin.HandlerFunc) *Route {
+	return r.add("get", path, handlers)
+}
+
+// Post declares a POST route
+func (r *Router) Post(path string, handlers ...gin.HandlerFunc) *Route {
+	return r.add("post", path, handlers)
+}
+
+// Put declares a PUT route
+func (r *Router) Put(path string, handlers ...gin.HandlerFunc) *Route {
+	return r.add("put", path, handlers)
+}
+
+// Delete declares a DELETE route
+func (r *Router) Delete(path string, handlers ...gin.HandlerFunc) *Route {
+	return r.add("delete", path, handlers)
+}
+
+// Routes returns the declared routes
+func Routes() []*Route {
+	mu.RLock()
+	defer mu.RUnlock()
+	all := make([]*Route, len(routes))
+	copy(all, routes)
+	return all
+}
+
+// CoreRoutes returns the declared routes as core's routes, the middlewares of their groups
+// and their own are composed with their handlers when it's called, the app's engine reads
+// them when it's built so the routes and their middlewares may be declared in any order
+func CoreRoutes() []corerouting.Route {
+	mu.RLock()
+	defer mu.RUnlock()
+	flat := make([]corerouting.Route, len(routes))
+	for i, route := range routes {
+		flat[i] = corerouting.Route{Method: route.Method, Path: route.Path, Handlers: route.chain()}
+	}
+	return flat
+}
+
+func (r *Router) add(method string, path string, handlers []gin.HandlerFunc) *Route {
+	mu.Lock()
+	defer mu.Unlock()
+	route := &Route{Method: method, Path: joinPaths(r.prefix, path), router: r, handlers: handlers}
+	routes = append(routes, route)
+	methods, ok := byPath[route.Path]
+	if !ok {
+		methods = map[string]*Route{}
+		byPath[route.Path] = methods
+	}
+	methods[strings.ToUpper(route.Method)] = route
+	return route
+}
+
+// Use adds middlewares to the route, they run after its groups' middlewares and before
+// its handlers, e.g:
+//
+//	router.Get("/admin", handlers.AdminShow).Use(middlewares.Auth, middlewares.Audit)
+func (route *Route) Use(middlewares ...gin.HandlerFunc) *Route {
+	mu.Lock()
+	defer mu.Unlock()
+	route.middlewares = append(route.middlewares, middlewares...)
+	return route
+}
+
+// Name names the route so its url is generated with URL instead of hardcoding its path, e.g:
+//
+//	router.Get("/users/:id", handlers.UsersShow).Name("users.show")
+func (route *Route) Name(name string) *Route {
+	mu.Lock()
+	defer mu.Unlock()
+	if existing, ok := names[name]; ok && existing != route {
+		log.Fatalf("the route name \"%s\" is given to %s and %s", name, existing.Path, route.Path)
+	}
+	delete(names, route.name)
+	route.name = name
+	names[name] = route
+	return route
+}
+
+// URL returns the path of the named route with its params filled in and the query appended,
+// every param of the path must be given and only them, e.g:
+//
+//	path, err := routing.URL("users.show", map[string]string{"id": "7"}, url.Values{"tab": {"posts"}})
+//	// /users/7?tab=posts
+func URL(name string, params map[string]string, query url.Values) (string, error) {
+	mu.RLock()
+	route, ok := names[name]
+	mu.RUnlock()
+	if !ok {
+		return "", fmt.Errorf("route %s: %w", name, ErrUnknownRoute)
+	}
+
+	used := map[string]bool{}
+	segments := strings.Split(route.Path, "/")
+	for i, segment := range segments {
+		if segment == "" || (segment[0] != ':' && segment[0] != '*') {
+			continue
+		}
+		param := segment[1:]
+		value, ok := params[param]
+		if !ok || (value == "" && segment[0] == ':') {
+			return "", fmt.Errorf("route %s: missing the %s param", name, param)
+		}
+		used[param] = true
+		if segment[0] == ':' {
+			segments[i] = url.PathEscape(value)
+			continue
+		}
+		// the catch-all param spans segments, their slashes are kept
+		parts := strings.Split(strings.TrimPrefix(value, "/"), "/")
+		for j, part := range parts {
+			parts[j] = url.PathEscape(part)
+		}
+		segments[i] = strings.Join(parts, "/")
+	}
+
+	var extra []string
+	for param := range params {
+		if !used[param] {
+			extra = append(extra, param)
+		}
+	}
+	if len(extra) > 0 {
+		sort.Strings(extra)
+		return "", fmt.Errorf("route %s: unknown params %s, pass them in the query", name, strings.Join(extra, ", "))
+	}
+
+	path := strings.Join(segments, "/")
+	if len(query) > 0 {
+		path += "?" + query.Encode()
+	}
+	return path, nil
+}
+
+// Named returns the route given the name, nil when no route has it
+func Named(name string) *Route {
+	mu.RLock()
+	defer mu.RUnlock()
+	return names[name]
+}
+
+// Current returns the route serving the request, nil when it wasn't declared with this package,
+// the lookup doesn't allocate so it's cheap enough for the logs and the metrics labels, e.g:
+//
+//	if route := routing.Current(c); route != nil {
+//		log.Println(route.RouteName(), route.Doc().Summary)
+//	}
+func Current(c *gin.Context) *Route {
+	mu.RLock()
+	defer mu.RUnlock()
+	return byPath[c.FullPath()][c.Request.Method]
+}
+
+// CurrentName returns the name of the route serving the request, empty when it has none
+func CurrentName(c *gin.Context) string {
+	mu.RLock()
+	defer mu.RUnlock()
+	if route := byPath[c.FullPath()][c.Request.Method]; route != nil {
+		return route.name
+	}
+	return ""
+}
+
+// RouteName returns the name given to the route with Name, empty when it has none
+func (route *Route) RouteName() string {
+	mu.RLock()
+	defer mu.RUnlock()
+	return route.name
+}
+
+// Params returns the names of the route's path params
+func (route *Route) Params() []string {
+	var params []string
+	for _, segment := range strings.Split(route.Path, "/") {
+		if segment != "" && (segment[0] == ':' || segment[0] == '*') {
+			params = append(params, segment[1:])
+		}
+	}
+	return params
+}
+
+// Funcs returns the template functions generating the urls of the named routes,
+// add them to the templates with Funcs(routing.Funcs()), e.g:
+//
+//	<a href="{{route "users.show" "id" .ID}}">profile</a>
+func Funcs() template.FuncMap {
+	return template.FuncMap{
+		"route": func(name string, pairs ...interface{}) (string, error) {
+			if len(pairs)%2 != 0 {
+				return "", fmt.Errorf("route %s: the params must come in name value pairs", name)
+			}
+			params := map[string]string{}
+			for i := 0; i < len(pairs); i += 2 {
+				params[fmt.Sprint(pairs[i])] = fmt.Sprint(pairs[i+1])
+			}
+			return URL(name, params, nil)
+		},
+	}
+}
+
+// chain returns the middlewares of the router's groups, outermost first, the route's
+// middlewares and the handlers
+func (route *Route) chain() []gin.HandlerFunc {
+	var groups []*Router
+	for r := route.router; r != nil; r = r.parent {
+		groups = append([]*Router{r}, groups...)
+	}
+	var chain []gin.HandlerFunc
+	for _, r := range groups {
+		chain = append(chain, r.middlewares...)
+	}
+	chain = append(chain, route.middlewares...)
+	return append(chain, route.handlers...)
+}
+
+// joinPaths joins the prefix and the path with a single slash
+func joinPaths(prefix string, path string) string {
+	if path == "" {
+		if prefix == "" {
+			return "/"
+		}
+		return prefix
+	}
+	return strings.TrimRight(prefix, "/") + "/" + strings.TrimLeft(path, "/")
+}
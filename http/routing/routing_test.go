@@ -0,0 +1,134 @@
+// Copyright 2021 Harran Ali <harran.m@gmail.com>. All rights reserved.
+// Use of this source code is governed by MIT-style
+// license that can be found in the LICENSE file.
+
+package routing
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+// mark returns a handler appending name to the X-Chain header
+func mark(name string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Writer.Header().Add("X-Chain", name)
+	}
+}
+
+type postsController struct{}
+
+func (postsController) Index(c *gin.Context)   { c.Status(http.StatusOK) }
+func (postsController) Show(c *gin.Context)    { c.Status(http.StatusOK) }
+func (postsController) Destroy(c *gin.Context) { c.Status(http.StatusOK) }
+
+// serve builds an engine from CoreRoutes and serves a request
+func serve(method string, path string) *httptest.ResponseRecorder {
+	engine := gin.New()
+	for _, route := range CoreRoutes() {
+		engine.Handle(strings.ToUpper(route.Method), route.Path, route.Handlers...)
+	}
+	w := httptest.NewRecorder()
+	engine.ServeHTTP(w, httptest.NewRequest(method, path, nil))
+	return w
+}
+
+func TestCoreRoutesComposeTheChainWhenRead(t *testing.T) {
+	api := Resolve().Group("/api", mark("group"))
+	route := api.Get("/items", mark("handler"))
+
+	// added after the route was declared, they still run in order
+	route.Use(mark("route"))
+	api.Use(mark("group-late"))
+
+	w := serve(http.MethodGet, "/api/items")
+	got := strings.Join(w.Header().Values("X-Chain"), ",")
+	if want := "group,group-late,route,handler"; got != want {
+		t.Errorf("chain = %s, want %s", got, want)
+	}
+}
+
+func TestResourceActions(t *testing.T) {
+	posts := Resolve().Resource("/posts", postsController{}).Name("posts")
+	posts.Except("destroy")
+	posts.Use(mark("resource"))
+
+	if w := serve(http.MethodGet, "/posts/7"); w.Code != http.StatusOK || w.Header().Get("X-Chain") != "resource" {
+		t.Errorf("GET /posts/7 = %d %v", w.Code, w.Header())
+	}
+	if w := serve(http.MethodDelete, "/posts/7"); w.Code != http.StatusNotFound {
+		t.Errorf("DELETE /posts/7 = %d, want %d", w.Code, http.StatusNotFound)
+	}
+	if Named("posts.destroy") != nil {
+		t.Error("the name of the dropped action is kept")
+	}
+
+	path, err := URL("posts.show", map[string]string{"id": "a b"}, nil)
+	if err != nil || path != "/posts/a%20b" {
+		t.Errorf("URL() = %s, %v", path, err)
+	}
+}
+
+func TestDoc(t *testing.T) {
+	type input struct{ Title string }
+	type output struct{ ID uint }
+	route := Resolve().Post("/documented", mark("handler")).
+		Summary("create").
+		Description("creates a thing").
+		Request(&input{}).
+		Response(http.StatusUnprocessableEntity, nil).
+		Response(http.StatusCreated, output{})
+
+	doc := route.Doc()
+	if doc.Summary != "create" || doc.Description != "creates a thing" {
+		t.Errorf("doc = %+v", doc)
+	}
+	if doc.Request != reflect.TypeOf(input{}) {
+		t.Errorf("request = %v, want input", doc.Request)
+	}
+	if got := doc.Statuses(); !reflect.DeepEqual(got, []int{http.StatusCreated, http.StatusUnprocessableEntity}) {
+		t.Errorf("statuses = %v", got)
+	}
+	if doc.Responses[http.StatusCreated] != reflect.TypeOf(output{}) || doc.Responses[http.StatusUnprocessableEntity] != nil {
+		t.Errorf("responses = %v", doc.Responses)
+	}
+}
+
+func TestCurrent(t *testing.T) {
+	var current *Route
+	var name string
+	var allocs float64
+	probe := func(c *gin.Context) {
+		current = Current(c)
+		name = CurrentName(c)
+		allocs = testing.AllocsPerRun(100, func() {
+			Current(c)
+			CurrentName(c)
+		})
+	}
+	Resolve().Group("/current").Get("/:id", probe).Name("current.show")
+
+	serve(http.MethodGet, "/current/7")
+	if current == nil || current.Path != "/current/:id" || current.RouteName() != "current.show" {
+		t.Errorf("Current() = %+v, want the current.show route", current)
+	}
+	if name != "current.show" {
+		t.Errorf("CurrentName() = %q, want current.show", name)
+	}
+	if allocs != 0 {
+		t.Errorf("Current and CurrentName allocate %v times per call, want 0", allocs)
+	}
+
+	// served by the engine without being declared with the router
+	engine := gin.New()
+	engine.GET("/undeclared", probe)
+	engine.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/undeclared", nil))
+	if current != nil || name != "" {
+		t.Errorf("Current() = %+v, CurrentName() = %q, want none", current, name)
+	}
+}
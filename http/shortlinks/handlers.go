@@ -0,0 +1,68 @@
+// Copyright 2021 Harran Ali <harran.m@gmail.com>. All rights reserved.
+// Use of this source code is governed by MIT-style
+// license that can be found in the LICENSE file.
+
+package shortlinks
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gocondor/core/database"
+	"github.com/gocondor/gocondor/http/response"
+	"github.com/gocondor/gocondor/i18n"
+)
+
+// LinkInput is the url to shorten, TTLHours 0 never expires
+type LinkInput struct {
+	URL      string `form:"url" json:"url" binding:"required,url"`
+	TTLHours int    `form:"ttlHours" json:"ttlHours" binding:"gte=0"`
+}
+
+// Redirect redirects to the link's url
+func Redirect(c *gin.Context) {
+	link, err := Resolve(database.Resolve(), c.Param("code"))
+	if errors.Is(err, ErrExpired) {
+		c.AbortWithStatusJSON(http.StatusGone, gin.H{
+			"message": i18n.T(i18n.Locale(c), "error.link_expired", nil),
+		})
+		return
+	}
+	if err != nil {
+		response.AbortWithDBError(c, err)
+		return
+	}
+
+	c.Header("Cache-Control", "no-store")
+	c.Redirect(http.StatusFound, link.URL)
+}
+
+// LinksStore shortens a url
+func LinksStore(c *gin.Context) {
+	var input LinkInput
+	if err := c.ShouldBind(&input); err != nil {
+		response.AbortWithValidationError(c, err)
+		return
+	}
+
+	link, err := Create(database.Resolve(), input.URL, time.Duration(input.TTLHours)*time.Hour)
+	if errors.Is(err, ErrInvalidURL) {
+		c.AbortWithStatusJSON(http.StatusUnprocessableEntity, gin.H{
+			"message": err.Error(),
+		})
+		return
+	}
+	if err != nil {
+		response.AbortWithDBError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"data": gin.H{
+			"link":     link,
+			"shortUrl": URL(link),
+		},
+	})
+}
@@ -0,0 +1,18 @@
+// Copyright 2021 Harran Ali <harran.m@gmail.com>. All rights reserved.
+// Use of this source code is governed by MIT-style
+// license that can be found in the LICENSE file.
+
+package shortlinks
+
+import (
+	"github.com/gocondor/core/routing"
+	"github.com/gocondor/gocondor/http/middlewares"
+)
+
+// RegisterShortLinksRoutes registers the redirect route and the admin endpoint creating links
+func RegisterShortLinksRoutes() {
+	router := routing.Resolve()
+
+	router.Get("/s/:code", Redirect)
+	router.Post("/admin/shortlinks", middlewares.AdminToken, LinksStore)
+}
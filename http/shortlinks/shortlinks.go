@@ -0,0 +1,103 @@
+// Copyright 2021 Harran Ali <harran.m@gmail.com>. All rights reserved.
+// Use of this source code is governed by MIT-style
+// license that can be found in the LICENSE file.
+
+package shortlinks
+
+import (
+	"crypto/rand"
+	"errors"
+	"log"
+	"math/big"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/gocondor/gocondor/models"
+	"gorm.io/gorm"
+)
+
+// ErrExpired is returned when resolving an expired link
+var ErrExpired = errors.New("the link has expired")
+
+// ErrInvalidURL is returned when shortening a url that isn't absolute http or https
+var ErrInvalidURL = errors.New("the url must be an absolute http or https url")
+
+// CodeLength is the length of the generated codes
+var CodeLength = 7
+
+const alphabet = "abcdefghijkmnopqrstuvwxyzABCDEFGHJKLMNPQRSTUVWXYZ23456789"
+
+// maxAttempts is how many codes are tried before giving up on collisions
+const maxAttempts = 5
+
+// Create shortens a url, a ttl of 0 never expires, e.g:
+//
+//	link, err := shortlinks.Create(db, "https://example.com/campaigns/spring?utm_source=sms", 30*24*time.Hour)
+//	sms.Send(phone, "Spring sale: "+shortlinks.URL(link))
+func Create(db *gorm.DB, target string, ttl time.Duration) (*models.ShortLink, error) {
+	parsed, err := url.Parse(target)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return nil, ErrInvalidURL
+	}
+
+	link := &models.ShortLink{URL: target}
+	if ttl > 0 {
+		expiresAt := time.Now().Add(ttl)
+		link.ExpiresAt = &expiresAt
+	}
+	for attempt := 0; attempt < maxAttempts; attempt++ {
+		if link.Code, err = code(); err != nil {
+			return nil, err
+		}
+		var taken int64
+		if err = db.Model(&models.ShortLink{}).Unscoped().Where("code = ?", link.Code).Count(&taken).Error; err != nil {
+			return nil, err
+		}
+		if taken == 0 {
+			return link, db.Create(link).Error
+		}
+	}
+	return nil, errors.New("couldn't generate a unique code, increase shortlinks.CodeLength")
+}
+
+// Resolve finds the link of a code and counts the click, failing to count the click doesn't
+// fail the redirect, e.g: in read-only mode, it's logged instead
+func Resolve(db *gorm.DB, code string) (*models.ShortLink, error) {
+	var link models.ShortLink
+	if err := db.Where("code = ?", code).First(&link).Error; err != nil {
+		return nil, err
+	}
+	if link.ExpiresAt != nil && time.Now().After(*link.ExpiresAt) {
+		return nil, ErrExpired
+	}
+
+	now := time.Now()
+	err := db.Model(&link).UpdateColumns(map[string]interface{}{
+		"clicks":          gorm.Expr("clicks + 1"),
+		"last_clicked_at": now,
+	}).Error
+	if err != nil {
+		log.Printf("shortlinks: counting the click of %s: %v", link.Code, err)
+	}
+	return &link, nil
+}
+
+// URL returns the public url of a link, prefixed with SHORT_LINKS_URL
+func URL(link *models.ShortLink) string {
+	return strings.TrimRight(os.Getenv("SHORT_LINKS_URL"), "/") + "/s/" + link.Code
+}
+
+func code() (string, error) {
+	var b strings.Builder
+	max := big.NewInt(int64(len(alphabet)))
+	for i := 0; i < CodeLength; i++ {
+		n, err := rand.Int(rand.Reader, max)
+		if err != nil {
+			return "", err
+		}
+		b.WriteByte(alphabet[n.Int64()])
+	}
+	return b.String(), nil
+}
@@ -0,0 +1,111 @@
+// Copyright 2021 Harran Ali <harran.m@gmail.com>. All rights reserved.
+// Use of this source code is governed by MIT-style
+// license that can be found in the LICENSE file.
+
+package timezone
+
+import (
+	"os"
+	"reflect"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// contextKey is the key the resolved location is stored under in the request context
+const contextKey = "timezone"
+
+// Resolver returns the IANA time zone name (e.g "Europe/Berlin") of the request if it knows it
+type Resolver func(c *gin.Context) (string, bool)
+
+// Resolvers are tried in order to find the time zone of a request,
+// append a resolver reading the user's profile to support per user zones
+var Resolvers = []Resolver{FromHeader, FromCookie}
+
+// FromHeader reads the time zone from the X-Timezone header
+func FromHeader(c *gin.Context) (string, bool) {
+	name := c.GetHeader("X-Timezone")
+	return name, name != ""
+}
+
+// FromCookie reads the time zone from the timezone cookie
+func FromCookie(c *gin.Context) (string, bool) {
+	name, err := c.Cookie("timezone")
+	return name, err == nil && name != ""
+}
+
+// Default returns the zone set in APP_TIMEZONE, defaults to UTC
+func Default() *time.Location {
+	loc, err := time.LoadLocation(os.Getenv("APP_TIMEZONE"))
+	if err != nil {
+		return time.UTC
+	}
+	return loc
+}
+
+// Location returns the time zone of the request, the first valid zone returned by
+// the resolvers, or the default zone
+func Location(c *gin.Context) *time.Location {
+	if loc, ok := c.Get(contextKey); ok {
+		return loc.(*time.Location)
+	}
+
+	loc := Default()
+	for _, resolve := range Resolvers {
+		name, ok := resolve(c)
+		if !ok {
+			continue
+		}
+		if l, err := time.LoadLocation(name); err == nil {
+			loc = l
+			break
+		}
+	}
+
+	c.Set(contextKey, loc)
+	return loc
+}
+
+// Now returns the current time in the request's time zone
+func Now(c *gin.Context) time.Time {
+	return time.Now().In(Location(c))
+}
+
+// In returns t in the request's time zone
+func In(c *gin.Context, t time.Time) time.Time {
+	return t.In(Location(c))
+}
+
+// Localize converts all the time fields of v to the request's time zone before it's
+// serialized, v must be a pointer to a struct or a slice of structs
+func Localize(c *gin.Context, v interface{}) {
+	convert(reflect.ValueOf(v), Location(c))
+}
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// convert walks v converting every settable time.Time to loc
+func convert(v reflect.Value, loc *time.Location) {
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		if !v.IsNil() {
+			convert(v.Elem(), loc)
+		}
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			convert(v.Index(i), loc)
+		}
+	case reflect.Struct:
+		if v.Type() == timeType {
+			if v.CanSet() {
+				v.Set(reflect.ValueOf(v.Interface().(time.Time).In(loc)))
+			}
+			return
+		}
+		for i := 0; i < v.NumField(); i++ {
+			if v.Type().Field(i).PkgPath == "" {
+				convert(v.Field(i), loc)
+			}
+		}
+	}
+}
@@ -0,0 +1,83 @@
+// Copyright 2021 Harran Ali <harran.m@gmail.com>. All rights reserved.
+// Use of this source code is governed by MIT-style
+// license that can be found in the LICENSE file.
+
+package i18n
+
+import (
+	"strconv"
+	"strings"
+	"time"
+)
+
+// format holds the formatting conventions of a locale
+type format struct {
+	thousands string
+	decimal   string
+	date      string
+	dateTime  string
+}
+
+var formats = map[string]format{
+	"en": {",", ".", "Jan 2, 2006", "Jan 2, 2006 3:04 PM"},
+	"de": {".", ",", "02.01.2006", "02.01.2006 15:04"},
+	"fr": {" ", ",", "02/01/2006", "02/01/2006 15:04"},
+	"es": {".", ",", "02/01/2006", "02/01/2006 15:04"},
+	"it": {".", ",", "02/01/2006", "02/01/2006 15:04"},
+	"nl": {".", ",", "02-01-2006", "02-01-2006 15:04"},
+}
+
+// formatOf returns the formatting conventions of the locale, defaults to english
+func formatOf(locale string) format {
+	if f, ok := formats[locale]; ok {
+		return f
+	}
+	if f, ok := formats[base(locale)]; ok {
+		return f
+	}
+	return formats["en"]
+}
+
+// FormatNumber formats the number with the locale's separators, e.g 1,234.50 in en and 1.234,50 in de
+func FormatNumber(locale string, n float64, decimals int) string {
+	return FormatDecimal(locale, strconv.FormatFloat(n, 'f', decimals, 64))
+}
+
+// FormatDecimal formats a decimal string like "-1234.50" with the locale's separators
+func FormatDecimal(locale string, s string) string {
+	f := formatOf(locale)
+
+	sign := ""
+	if strings.HasPrefix(s, "-") {
+		sign, s = "-", s[1:]
+	}
+	integer, fraction := s, ""
+	if i := strings.IndexByte(s, '.'); i >= 0 {
+		integer, fraction = s[:i], s[i+1:]
+	}
+
+	// group the integer part by thousands
+	var b strings.Builder
+	for i, d := range integer {
+		if i > 0 && (len(integer)-i)%3 == 0 {
+			b.WriteString(f.thousands)
+		}
+		b.WriteRune(d)
+	}
+	if fraction != "" {
+		b.WriteString(f.decimal)
+		b.WriteString(fraction)
+	}
+
+	return sign + b.String()
+}
+
+// FormatDate formats the date the way the locale writes dates
+func FormatDate(locale string, t time.Time) string {
+	return t.Format(formatOf(locale).date)
+}
+
+// FormatDateTime formats the date and time the way the locale writes them
+func FormatDateTime(locale string, t time.Time) string {
+	return t.Format(formatOf(locale).dateTime)
+}
@@ -0,0 +1,118 @@
+// Copyright 2021 Harran Ali <harran.m@gmail.com>. All rights reserved.
+// Use of this source code is governed by MIT-style
+// license that can be found in the LICENSE file.
+
+package i18n
+
+import (
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Messages maps message keys to their translation in one locale,
+// translations can hold placeholders like {field} replaced on translation
+type Messages map[string]string
+
+var (
+	mu       sync.RWMutex
+	catalogs = map[string]Messages{
+		"en": english,
+	}
+)
+
+// Register adds the messages to the locale's catalog, overriding existing keys,
+// per field validation messages are overridden with keys like "validation.email.required"
+func Register(locale string, messages Messages) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	catalog, ok := catalogs[locale]
+	if !ok {
+		catalog = Messages{}
+		catalogs[locale] = catalog
+	}
+	for key, message := range messages {
+		catalog[key] = message
+	}
+}
+
+// DefaultLocale returns the locale set in APP_LOCALE, defaults to en
+func DefaultLocale() string {
+	if locale := strings.TrimSpace(os.Getenv("APP_LOCALE")); locale != "" {
+		return locale
+	}
+	return "en"
+}
+
+// Has reports whether the key is translated in the locale or the default locale
+func Has(locale string, key string) bool {
+	_, ok := lookup(locale, key)
+	return ok
+}
+
+// T translates the key to the locale, falling back to the default locale then to the key itself,
+// params replace the placeholders of the translation
+func T(locale string, key string, params map[string]string) string {
+	message, ok := lookup(locale, key)
+	if !ok {
+		message = key
+	}
+
+	for name, value := range params {
+		message = strings.Replace(message, "{"+name+"}", value, -1)
+	}
+	return message
+}
+
+// lookup returns the translation of the key in the locale or the default locale
+func lookup(locale string, key string) (string, bool) {
+	mu.RLock()
+	defer mu.RUnlock()
+
+	for _, l := range []string{locale, base(locale), DefaultLocale()} {
+		if message, ok := catalogs[l][key]; ok {
+			return message, true
+		}
+	}
+	return "", false
+}
+
+// Locale returns the locale of the request, from the lang query param or the Accept-Language
+// header, among the registered locales, defaults to APP_LOCALE
+func Locale(c *gin.Context) string {
+	if lang := c.Query("lang"); lang != "" && supported(lang) {
+		return lang
+	}
+
+	for _, part := range strings.Split(c.GetHeader("Accept-Language"), ",") {
+		lang := strings.TrimSpace(strings.SplitN(part, ";", 2)[0])
+		if lang == "" || lang == "*" {
+			continue
+		}
+		if supported(lang) {
+			return lang
+		}
+		if supported(base(lang)) {
+			return base(lang)
+		}
+	}
+
+	return DefaultLocale()
+}
+
+// supported reports whether the locale has a catalog
+func supported(locale string) bool {
+	mu.RLock()
+	defer mu.RUnlock()
+
+	_, ok := catalogs[locale]
+	return ok
+}
+
+// base returns the language of the locale, e.g "en" for "en-US"
+func base(locale string) string {
+	return strings.SplitN(strings.Replace(locale, "_", "-", 1), "-", 2)[0]
+}
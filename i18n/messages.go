@@ -0,0 +1,50 @@
+// Copyright 2021 Harran Ali <harran.m@gmail.com>. All rights reserved.
+// Use of this source code is governed by MIT-style
+// license that can be found in the LICENSE file.
+
+package i18n
+
+// english are the built-in messages
+var english = Messages{
+	// errors
+	"error.internal":           "something went wrong",
+	"error.not_found":          "not found",
+	"error.forbidden":          "forbidden",
+	"error.conflict":           "the record was modified by someone else, reload it and try again",
+	"error.read_only":          "the service is in read-only mode, try again later",
+	"error.wrong_credentials":  "wrong credentials",
+	"error.warming_up":         "the service is starting, try again shortly",
+	"error.body_too_large":     "the request body is too large",
+	"error.maintenance":        "the service is down for maintenance, try again later",
+	"error.edit_window_closed": "the time to edit this has passed",
+	"error.page_expired":       "the page has expired, go back and try again",
+	"error.too_many_requests":  "too many requests, slow down and try again shortly",
+	"error.link_expired":       "the link has expired",
+	"error.quota_exceeded":     "the quota is exceeded, try again once it resets",
+	"error.quota_check":        "something went wrong while checking the quota",
+
+	// validation
+	"validation.invalid":    "{field} is invalid",
+	"validation.required":   "{field} is required",
+	"validation.email":      "{field} must be a valid email address",
+	"validation.url":        "{field} must be a valid url",
+	"validation.uuid":       "{field} must be a valid uuid",
+	"validation.id":         "{field} must be a valid id",
+	"validation.currency":   "{field} must be a valid currency code",
+	"validation.alpha":      "{field} may only contain letters",
+	"validation.alphanum":   "{field} may only contain letters and numbers",
+	"validation.numeric":    "{field} must be a number",
+	"validation.min":        "{field} must be at least {param}",
+	"validation.max":        "{field} must be at most {param}",
+	"validation.len":        "{field} must be exactly {param} long",
+	"validation.gt":         "{field} must be greater than {param}",
+	"validation.gte":        "{field} must be greater than or equal to {param}",
+	"validation.lt":         "{field} must be less than {param}",
+	"validation.lte":        "{field} must be less than or equal to {param}",
+	"validation.oneof":      "{field} must be one of: {param}",
+	"validation.eqfield":    "{field} must match {param}",
+	"validation.transition": "{field} can't change from {from} to {to}",
+	"validation.spam":       "the submission was flagged as spam",
+	"validation.captcha":    "the captcha wasn't solved, try again",
+	"validation.consent":    "{category} is not a consent category",
+}
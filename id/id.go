@@ -0,0 +1,70 @@
+// Copyright 2021 Harran Ali <harran.m@gmail.com>. All rights reserved.
+// Use of this source code is governed by MIT-style
+// license that can be found in the LICENSE file.
+
+package id
+
+import (
+	"crypto/rand"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Generator generates unique ids
+type Generator interface {
+	// Generate returns a new id
+	Generate() string
+	// Valid reports whether the id has the generator's format
+	Valid(id string) bool
+}
+
+var (
+	generator Generator
+	once      sync.Once
+)
+
+// Default returns the generator selected by ID_GENERATOR (uuid, ulid or snowflake),
+// defaults to uuid, it panics when ID_NODE isn't a snowflake node number
+func Default() Generator {
+	once.Do(func() {
+		switch strings.TrimSpace(os.Getenv("ID_GENERATOR")) {
+		case "ulid":
+			generator = ULID{}
+		case "snowflake":
+			var node int64
+			if value := strings.TrimSpace(os.Getenv("ID_NODE")); value != "" {
+				var err error
+				if node, err = strconv.ParseInt(value, 10, 64); err != nil {
+					panic("id: ID_NODE \"" + value + "\" isn't a number")
+				}
+			}
+			snowflake, err := NewSnowflake(node)
+			if err != nil {
+				panic(err.Error())
+			}
+			generator = snowflake
+		default:
+			generator = UUIDv7{}
+		}
+	})
+	return generator
+}
+
+// New returns a new id from the default generator
+func New() string {
+	return Default().Generate()
+}
+
+// Valid reports whether the id has the format of the default generator
+func Valid(id string) bool {
+	return Default().Valid(id)
+}
+
+// random fills b with random bytes
+func random(b []byte) {
+	if _, err := rand.Read(b); err != nil {
+		panic("id: failed to read random bytes: " + err.Error())
+	}
+}
@@ -0,0 +1,104 @@
+// Copyright 2021 Harran Ali <harran.m@gmail.com>. All rights reserved.
+// Use of this source code is governed by MIT-style
+// license that can be found in the LICENSE file.
+
+package id
+
+import (
+	"testing"
+	"time"
+)
+
+func TestUUIDv7Valid(t *testing.T) {
+	tests := []struct {
+		id   string
+		want bool
+	}{
+		{"017f22e2-79b0-7cc3-98c4-dc0c0c07398f", true},
+		{"017F22E2-79B0-7CC3-B8C4-DC0C0C07398F", true},
+		{"017f22e2-79b0-4cc3-98c4-dc0c0c07398f", false}, // version 4
+		{"017f22e2-79b0-7cc3-c8c4-dc0c0c07398f", false}, // microsoft variant
+		{"017f22e2-79b0-7cc3-78c4-dc0c0c07398f", false}, // ncs variant
+		{"017f22e2079b007cc3098c40dc0c0c07398f", false},
+		{"017f22e2-79b0-7cc3-98c4-dc0c0c07398", false},
+		{"017f22e2-79b0-7cc3-98c4-dc0c0c07398g", false},
+	}
+	for _, tt := range tests {
+		if got := (UUIDv7{}).Valid(tt.id); got != tt.want {
+			t.Errorf("UUIDv7.Valid(%q) = %t, want %t", tt.id, got, tt.want)
+		}
+	}
+}
+
+func TestULIDValid(t *testing.T) {
+	tests := []struct {
+		id   string
+		want bool
+	}{
+		{"01ARZ3NDEKTSV4RRFFQ69G5FAV", true},
+		{"81ARZ3NDEKTSV4RRFFQ69G5FAV", false}, // more than 128 bits
+		{"01ARZ3NDEKTSV4RRFFQ69G5FAU", false}, // not in the alphabet
+		{"01ARZ3NDEKTSV4RRFFQ69G5FA", false},
+	}
+	for _, tt := range tests {
+		if got := (ULID{}).Valid(tt.id); got != tt.want {
+			t.Errorf("ULID.Valid(%q) = %t, want %t", tt.id, got, tt.want)
+		}
+	}
+}
+
+func TestGenerateValid(t *testing.T) {
+	snowflake, _ := NewSnowflake(1)
+	for name, generator := range map[string]Generator{"uuid": UUIDv7{}, "ulid": ULID{}, "snowflake": snowflake} {
+		if id := generator.Generate(); !generator.Valid(id) {
+			t.Errorf("%s: Valid(%q) = false, want true", name, id)
+		}
+	}
+}
+
+// the uuids and the ulids only order across milliseconds, the snowflakes within them too
+func TestMonotonic(t *testing.T) {
+	for name, generator := range map[string]Generator{"uuid": UUIDv7{}, "ulid": ULID{}} {
+		previous := generator.Generate()
+		for i := 0; i < 3; i++ {
+			time.Sleep(2 * time.Millisecond)
+			next := generator.Generate()
+			if next <= previous {
+				t.Errorf("%s: %q generated after %q", name, next, previous)
+			}
+			previous = next
+		}
+	}
+
+	snowflake, _ := NewSnowflake(1023)
+	previous := snowflake.Next()
+	// more than the 4096 ids of a millisecond
+	for i := 0; i < 10000; i++ {
+		next := snowflake.Next()
+		if next <= previous {
+			t.Fatalf("snowflake: %d generated after %d", next, previous)
+		}
+		previous = next
+	}
+}
+
+func TestNewSnowflake(t *testing.T) {
+	tests := []struct {
+		node int64
+		err  error
+	}{
+		{0, nil},
+		{1023, nil},
+		{1024, ErrInvalidNode},
+		{-1, ErrInvalidNode},
+	}
+	for _, tt := range tests {
+		if _, err := NewSnowflake(tt.node); err != tt.err {
+			t.Errorf("NewSnowflake(%d) = %v, want %v", tt.node, err, tt.err)
+		}
+	}
+	snowflake, _ := NewSnowflake(5)
+	if node := snowflake.Next() >> 12 & 0x3ff; node != 5 {
+		t.Errorf("the node of the id is %d, want 5", node)
+	}
+}
@@ -0,0 +1,74 @@
+// Copyright 2021 Harran Ali <harran.m@gmail.com>. All rights reserved.
+// Use of this source code is governed by MIT-style
+// license that can be found in the LICENSE file.
+
+package id
+
+import (
+	"errors"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// ErrInvalidNode is returned by NewSnowflake for the node numbers outside of 0 to 1023
+var ErrInvalidNode = errors.New("id: the snowflake node must be between 0 and 1023")
+
+// snowflakeEpoch is the start of snowflake time, 2020-01-01 UTC in milliseconds
+const snowflakeEpoch = 1577836800000
+
+// Snowflake generates 64 bit time ordered ids made of 41 bits of milliseconds,
+// 10 bits of node number and 12 bits of sequence
+type Snowflake struct {
+	mu   sync.Mutex
+	node int64
+	last int64
+	seq  int64
+}
+
+// NewSnowflake creates a snowflake generator for the node, every instance
+// of the app must use a distinct node number between 0 and 1023
+func NewSnowflake(node int64) (*Snowflake, error) {
+	if node < 0 || node > 0x3ff {
+		return nil, ErrInvalidNode
+	}
+	return &Snowflake{node: node}, nil
+}
+
+// Generate returns a new snowflake id
+func (s *Snowflake) Generate() string {
+	return strconv.FormatInt(s.Next(), 10)
+}
+
+// Next returns a new snowflake id as a number
+func (s *Snowflake) Next() int64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now().UnixNano()/int64(time.Millisecond) - snowflakeEpoch
+	if now < s.last {
+		// the clock went backwards, keep counting from the last timestamp
+		now = s.last
+	}
+	if now == s.last {
+		s.seq = (s.seq + 1) & 0xfff
+		if s.seq == 0 {
+			// the sequence is exhausted for this millisecond, wait for the next one
+			for now <= s.last {
+				time.Sleep(100 * time.Microsecond)
+				now = time.Now().UnixNano()/int64(time.Millisecond) - snowflakeEpoch
+			}
+		}
+	} else {
+		s.seq = 0
+	}
+	s.last = now
+
+	return now<<22 | s.node<<12 | s.seq
+}
+
+// Valid reports whether id is a snowflake id
+func (s *Snowflake) Valid(id string) bool {
+	n, err := strconv.ParseInt(id, 10, 64)
+	return err == nil && n > 0
+}
@@ -0,0 +1,58 @@
+// Copyright 2021 Harran Ali <harran.m@gmail.com>. All rights reserved.
+// Use of this source code is governed by MIT-style
+// license that can be found in the LICENSE file.
+
+package id
+
+import (
+	"strings"
+	"time"
+)
+
+// crockford is the base32 alphabet used by ulids
+const crockford = "0123456789ABCDEFGHJKMNPQRSTVWXYZ"
+
+// ULID generates lexicographically sortable ulids
+type ULID struct{}
+
+// Generate returns a new ulid
+func (ULID) Generate() string {
+	var b [16]byte
+	random(b[6:])
+
+	// 48 bits of unix milliseconds
+	ms := uint64(time.Now().UnixNano() / int64(time.Millisecond))
+	for i := 0; i < 6; i++ {
+		b[i] = byte(ms >> (40 - 8*i))
+	}
+
+	// encode the 128 bits as 26 base32 characters, the first one holds only 3 bits
+	var s [26]byte
+	var acc uint32
+	bits := 2 // pad the 128 bits to 130
+	j := 0
+	for _, v := range b {
+		acc = acc<<8 | uint32(v)
+		bits += 8
+		for bits >= 5 {
+			bits -= 5
+			s[j] = crockford[(acc>>uint(bits))&0x1f]
+			j++
+		}
+	}
+
+	return string(s[:])
+}
+
+// Valid reports whether id is a ulid
+func (ULID) Valid(id string) bool {
+	if len(id) != 26 || id[0] > '7' {
+		return false
+	}
+	for i := 0; i < len(id); i++ {
+		if !strings.ContainsRune(crockford, rune(id[i])) {
+			return false
+		}
+	}
+	return true
+}
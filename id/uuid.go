@@ -0,0 +1,74 @@
+// Copyright 2021 Harran Ali <harran.m@gmail.com>. All rights reserved.
+// Use of this source code is governed by MIT-style
+// license that can be found in the LICENSE file.
+
+package id
+
+import (
+	"encoding/hex"
+	"time"
+)
+
+// UUIDv7 generates time ordered version 7 uuids
+type UUIDv7 struct{}
+
+// Generate returns a new uuid
+func (UUIDv7) Generate() string {
+	var b [16]byte
+	random(b[6:])
+
+	// 48 bits of unix milliseconds
+	ms := uint64(time.Now().UnixNano() / int64(time.Millisecond))
+	for i := 0; i < 6; i++ {
+		b[i] = byte(ms >> (40 - 8*i))
+	}
+	b[6] = (b[6] & 0x0f) | 0x70 // version 7
+	b[8] = (b[8] & 0x3f) | 0x80 // variant 10
+
+	var s [36]byte
+	hex.Encode(s[0:8], b[0:4])
+	s[8] = '-'
+	hex.Encode(s[9:13], b[4:6])
+	s[13] = '-'
+	hex.Encode(s[14:18], b[6:8])
+	s[18] = '-'
+	hex.Encode(s[19:23], b[8:10])
+	s[23] = '-'
+	hex.Encode(s[24:], b[10:])
+
+	return string(s[:])
+}
+
+// Valid reports whether id is a version 7 uuid in its canonical form
+func (UUIDv7) Valid(id string) bool {
+	if len(id) != 36 {
+		return false
+	}
+	// the version is the 13th hex digit, the variant the 2 high bits of the 17th one
+	if id[14] != '7' {
+		return false
+	}
+	switch id[19] {
+	case '8', '9', 'a', 'b', 'A', 'B':
+	default:
+		return false
+	}
+	for i := 0; i < len(id); i++ {
+		switch i {
+		case 8, 13, 18, 23:
+			if id[i] != '-' {
+				return false
+			}
+		default:
+			if !isHex(id[i]) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// isHex reports whether c is a lowercase or uppercase hex digit
+func isHex(c byte) bool {
+	return (c >= '0' && c <= '9') || (c >= 'a' && c <= 'f') || (c >= 'A' && c <= 'F')
+}
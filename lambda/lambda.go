@@ -0,0 +1,306 @@
+// Copyright 2021 Harran Ali <harran.m@gmail.com>. All rights reserved.
+// Use of this source code is governed by MIT-style
+// license that can be found in the LICENSE file.
+
+package lambda
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+// ErrNotOnLambda is returned by Serve when the runtime api of aws lambda isn't available
+var ErrNotOnLambda = errors.New("lambda: AWS_LAMBDA_RUNTIME_API isn't set, the app isn't running on aws lambda")
+
+// the version of the runtime api of aws lambda
+const runtimeAPIVersion = "2018-06-01"
+
+// Serve serves the invocations of the function with the handler until ctx is done, the api gateway
+// (rest and http apis) and the application load balancer events are turned into http requests, and
+// the responses of the handler into the responses they expect, the handler is built by newHandler on
+// the first invocation so the cold starts don't pay for it before the function is invoked, e.g:
+//
+//	err := lambda.Serve(ctx, app.Handler)
+func Serve(ctx context.Context, newHandler func() http.Handler) error {
+	api := os.Getenv("AWS_LAMBDA_RUNTIME_API")
+	if api == "" {
+		return ErrNotOnLambda
+	}
+	base := fmt.Sprintf("http://%s/%s/runtime/invocation/", api, runtimeAPIVersion)
+
+	var once sync.Once
+	var handler http.Handler
+	for {
+		// the next invocation is awaited without a timeout, the function is frozen meanwhile
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, base+"next", nil)
+		if err != nil {
+			return err
+		}
+		res, err := http.DefaultClient.Do(req)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return err
+		}
+		payload, err := ioutil.ReadAll(res.Body)
+		res.Body.Close()
+		if err != nil {
+			return err
+		}
+		id := res.Header.Get("Lambda-Runtime-Aws-Request-Id")
+		os.Setenv("_X_AMZN_TRACE_ID", res.Header.Get("Lambda-Runtime-Trace-Id"))
+
+		invocationCtx, cancel := context.WithCancel(ctx)
+		if ms, err := strconv.ParseInt(res.Header.Get("Lambda-Runtime-Deadline-Ms"), 10, 64); err == nil {
+			invocationCtx, cancel = context.WithDeadline(ctx, time.Unix(0, ms*int64(time.Millisecond)))
+		}
+		once.Do(func() {
+			handler = newHandler()
+		})
+		out, err := Invoke(invocationCtx, handler, payload)
+		cancel()
+		// the result is sent even when ctx is done meanwhile, so the invocation isn't retried
+		if err != nil {
+			out, _ = json.Marshal(map[string]string{"errorMessage": err.Error(), "errorType": "InvalidEvent"})
+			err = post(context.Background(), base+id+"/error", out)
+		} else {
+			err = post(context.Background(), base+id+"/response", out)
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// event is an api gateway or application load balancer event, the rest api and the load balancer
+// send version 1.0 events, the http api sends version 2.0 ones unless it's configured otherwise
+type event struct {
+	Version string `json:"version"`
+
+	// version 1.0
+	HTTPMethod                      string              `json:"httpMethod"`
+	Path                            string              `json:"path"`
+	Headers                         map[string]string   `json:"headers"`
+	MultiValueHeaders               map[string][]string `json:"multiValueHeaders"`
+	QueryStringParameters           map[string]string   `json:"queryStringParameters"`
+	MultiValueQueryStringParameters map[string][]string `json:"multiValueQueryStringParameters"`
+
+	// version 2.0
+	RawPath        string   `json:"rawPath"`
+	RawQueryString string   `json:"rawQueryString"`
+	Cookies        []string `json:"cookies"`
+
+	RequestContext struct {
+		HTTP struct {
+			Method   string `json:"method"`
+			SourceIP string `json:"sourceIp"`
+		} `json:"http"`
+		Identity struct {
+			SourceIP string `json:"sourceIp"`
+		} `json:"identity"`
+		ELB *struct{} `json:"elb"`
+	} `json:"requestContext"`
+
+	Body            string `json:"body"`
+	IsBase64Encoded bool   `json:"isBase64Encoded"`
+}
+
+// response is the response the api gateway and the application load balancer expect
+type response struct {
+	StatusCode        int                 `json:"statusCode"`
+	StatusDescription string              `json:"statusDescription,omitempty"`
+	Headers           map[string]string   `json:"headers,omitempty"`
+	MultiValueHeaders map[string][]string `json:"multiValueHeaders,omitempty"`
+	Cookies           []string            `json:"cookies,omitempty"`
+	Body              string              `json:"body"`
+	IsBase64Encoded   bool                `json:"isBase64Encoded"`
+}
+
+// Invoke serves the event of an invocation with the handler and returns the encoded response
+func Invoke(ctx context.Context, handler http.Handler, payload []byte) ([]byte, error) {
+	var e event
+	if err := json.Unmarshal(payload, &e); err != nil {
+		return nil, fmt.Errorf("lambda: decoding the event: %w", err)
+	}
+	req, err := e.request(ctx)
+	if err != nil {
+		return nil, err
+	}
+	w := &responseWriter{header: http.Header{}}
+	handler.ServeHTTP(w, req)
+	return json.Marshal(e.response(w))
+}
+
+// request returns the http request of the event
+func (e *event) request(ctx context.Context) (*http.Request, error) {
+	method, path, query, sourceIP := e.HTTPMethod, e.Path, "", e.RequestContext.Identity.SourceIP
+	if e.Version == "2.0" {
+		method, path, query, sourceIP = e.RequestContext.HTTP.Method, e.RawPath, e.RawQueryString, e.RequestContext.HTTP.SourceIP
+	} else if e.RequestContext.ELB != nil {
+		// the load balancer doesn't decode the query parameters
+		query = rawQuery(e.MultiValueQueryStringParameters, e.QueryStringParameters, func(s string) string { return s })
+	} else {
+		query = rawQuery(e.MultiValueQueryStringParameters, e.QueryStringParameters, url.QueryEscape)
+	}
+	if path == "" {
+		path = "/"
+	}
+	target := path
+	if query != "" {
+		target += "?" + query
+	}
+
+	body := []byte(e.Body)
+	if e.IsBase64Encoded {
+		decoded, err := base64.StdEncoding.DecodeString(e.Body)
+		if err != nil {
+			return nil, fmt.Errorf("lambda: decoding the body: %w", err)
+		}
+		body = decoded
+	}
+	req, err := http.NewRequestWithContext(ctx, method, target, bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("lambda: %w", err)
+	}
+	req.RequestURI = target
+	req.ContentLength = int64(len(body))
+	for key, value := range e.Headers {
+		req.Header.Set(key, value)
+	}
+	for key, values := range e.MultiValueHeaders {
+		req.Header.Del(key)
+		for _, value := range values {
+			req.Header.Add(key, value)
+		}
+	}
+	if len(e.Cookies) > 0 {
+		req.Header.Set("Cookie", strings.Join(e.Cookies, "; "))
+	}
+	req.Host = req.Header.Get("Host")
+	if sourceIP != "" {
+		req.RemoteAddr = net.JoinHostPort(sourceIP, "0")
+	}
+	return req, nil
+}
+
+// response returns the response of the event to the response written by the handler
+func (e *event) response(w *responseWriter) response {
+	status := w.status
+	if status == 0 {
+		status = http.StatusOK
+	}
+	res := response{StatusCode: status}
+	if utf8.Valid(w.body.Bytes()) {
+		res.Body = w.body.String()
+	} else {
+		res.Body = base64.StdEncoding.EncodeToString(w.body.Bytes())
+		res.IsBase64Encoded = true
+	}
+
+	switch {
+	case e.Version == "2.0":
+		// the http api takes the cookies apart and the repeated headers joined
+		res.Headers = map[string]string{}
+		for key, values := range w.header {
+			if key == "Set-Cookie" {
+				res.Cookies = values
+				continue
+			}
+			res.Headers[key] = strings.Join(values, ",")
+		}
+	case e.RequestContext.ELB != nil && e.MultiValueHeaders == nil:
+		// the load balancer without multi value headers takes one value per header
+		res.StatusDescription = fmt.Sprintf("%d %s", status, http.StatusText(status))
+		res.Headers = map[string]string{}
+		for key, values := range w.header {
+			res.Headers[key] = values[len(values)-1]
+		}
+	default:
+		if e.RequestContext.ELB != nil {
+			res.StatusDescription = fmt.Sprintf("%d %s", status, http.StatusText(status))
+		}
+		res.MultiValueHeaders = w.header
+	}
+	return res
+}
+
+// rawQuery encodes the query parameters of a version 1.0 event in a stable order
+func rawQuery(multi map[string][]string, single map[string]string, escape func(string) string) string {
+	if multi == nil {
+		multi = map[string][]string{}
+		for key, value := range single {
+			multi[key] = []string{value}
+		}
+	}
+	keys := make([]string, 0, len(multi))
+	for key := range multi {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	var pairs []string
+	for _, key := range keys {
+		for _, value := range multi[key] {
+			pairs = append(pairs, escape(key)+"="+escape(value))
+		}
+	}
+	return strings.Join(pairs, "&")
+}
+
+// post sends the response or the error of an invocation to the runtime api
+func post(ctx context.Context, endpoint string, body []byte) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	res.Body.Close()
+	if res.StatusCode >= 300 {
+		return fmt.Errorf("lambda: the runtime api answered %s to %s", res.Status, endpoint)
+	}
+	return nil
+}
+
+// responseWriter buffers the response of the handler
+type responseWriter struct {
+	header http.Header
+	status int
+	body   bytes.Buffer
+}
+
+func (w *responseWriter) Header() http.Header {
+	return w.header
+}
+
+func (w *responseWriter) WriteHeader(status int) {
+	if w.status == 0 {
+		w.status = status
+	}
+}
+
+func (w *responseWriter) Write(b []byte) (int, error) {
+	w.WriteHeader(http.StatusOK)
+	return w.body.Write(b)
+}
+
+// Flush does nothing, the response is sent once the handler returned
+func (w *responseWriter) Flush() {}
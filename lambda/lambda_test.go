@@ -0,0 +1,136 @@
+// Copyright 2021 Harran Ali <harran.m@gmail.com>. All rights reserved.
+// Use of this source code is governed by MIT-style
+// license that can be found in the LICENSE file.
+
+package lambda
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// echo answers with the request it got
+var echo = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+	body, _ := ioutil.ReadAll(r.Body)
+	w.Header().Add("Set-Cookie", "a=1")
+	w.Header().Add("Set-Cookie", "b=2")
+	w.Header().Set("Content-Type", "text/plain")
+	w.WriteHeader(http.StatusCreated)
+	w.Write([]byte(strings.Join([]string{r.Method, r.URL.RequestURI(), r.RemoteAddr, r.Header.Get("X-Test"), r.Header.Get("Cookie"), string(body)}, "|")))
+})
+
+func TestInvoke(t *testing.T) {
+	tests := []struct {
+		name  string
+		event string
+		want  response
+	}{
+		{
+			"rest api",
+			`{"httpMethod":"POST","path":"/posts","multiValueQueryStringParameters":{"q":["a b"],"tag":["x","y"]},
+			"headers":{"X-Test":"1"},"requestContext":{"identity":{"sourceIp":"203.0.113.7"}},"body":"aGk=","isBase64Encoded":true}`,
+			response{StatusCode: 201, MultiValueHeaders: map[string][]string{"Set-Cookie": {"a=1", "b=2"}, "Content-Type": {"text/plain"}},
+				Body: "POST|/posts?q=a+b&tag=x&tag=y|203.0.113.7:0|1||hi"},
+		},
+		{
+			"http api",
+			`{"version":"2.0","rawPath":"/posts/7","rawQueryString":"tab=1","cookies":["s=1","t=2"],"headers":{"x-test":"2"},
+			"requestContext":{"http":{"method":"GET","sourceIp":"198.51.100.1"}}}`,
+			response{StatusCode: 201, Headers: map[string]string{"Content-Type": "text/plain"}, Cookies: []string{"a=1", "b=2"},
+				Body: "GET|/posts/7?tab=1|198.51.100.1:0|2|s=1; t=2|"},
+		},
+		{
+			"load balancer",
+			`{"httpMethod":"GET","path":"/","queryStringParameters":{"q":"a%20b"},"headers":{"x-test":"3"},"requestContext":{"elb":{}}}`,
+			response{StatusCode: 201, StatusDescription: "201 Created", Headers: map[string]string{"Set-Cookie": "b=2", "Content-Type": "text/plain"},
+				Body: "GET|/?q=a%20b||3||"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out, err := Invoke(context.Background(), echo, []byte(tt.event))
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got response
+			if err := json.Unmarshal(out, &got); err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Invoke() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestInvokeBinaryBody(t *testing.T) {
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte{0xff, 0xfe})
+	})
+	out, err := Invoke(context.Background(), handler, []byte(`{"httpMethod":"GET","path":"/image"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got response
+	json.Unmarshal(out, &got)
+	if !got.IsBase64Encoded || got.Body != "//4=" || got.StatusCode != http.StatusOK {
+		t.Errorf("Invoke() = %+v, want the base64 body", got)
+	}
+}
+
+func TestServe(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	responses := make(chan string, 1)
+	served := false
+	runtime := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/2018-06-01/runtime/invocation/next":
+			if served {
+				<-r.Context().Done()
+				return
+			}
+			served = true
+			w.Header().Set("Lambda-Runtime-Aws-Request-Id", "req-1")
+			w.Write([]byte(`{"version":"2.0","rawPath":"/ping","requestContext":{"http":{"method":"GET"}}}`))
+		case "/2018-06-01/runtime/invocation/req-1/response":
+			body, _ := ioutil.ReadAll(r.Body)
+			responses <- string(body)
+			cancel()
+		default:
+			t.Errorf("unexpected call to %s", r.URL.Path)
+		}
+	}))
+	defer runtime.Close()
+	os.Setenv("AWS_LAMBDA_RUNTIME_API", strings.TrimPrefix(runtime.URL, "http://"))
+	defer os.Unsetenv("AWS_LAMBDA_RUNTIME_API")
+
+	built := 0
+	err := Serve(ctx, func() http.Handler {
+		built++
+		return echo
+	})
+	if err != nil {
+		t.Errorf("Serve() = %v, want nil", err)
+	}
+	if built != 1 {
+		t.Errorf("the handler was built %d times, want 1", built)
+	}
+	if got := <-responses; !strings.Contains(got, `"body":"GET|/ping||||"`) {
+		t.Errorf("response = %s", got)
+	}
+}
+
+func TestServeOutsideLambda(t *testing.T) {
+	os.Unsetenv("AWS_LAMBDA_RUNTIME_API")
+	if err := Serve(context.Background(), nil); err != ErrNotOnLambda {
+		t.Errorf("Serve() = %v, want ErrNotOnLambda", err)
+	}
+}
@@ -0,0 +1,233 @@
+// Copyright 2021 Harran Ali <harran.m@gmail.com>. All rights reserved.
+// Use of this source code is governed by MIT-style
+// license that can be found in the LICENSE file.
+
+package listeners
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net"
+	"net/http"
+	"sync"
+	"sync/atomic"
+)
+
+// TCPHandler serves a tcp connection, the connection is closed when it returns
+type TCPHandler func(ctx context.Context, conn net.Conn)
+
+// UDPHandler handles a udp packet, replies are written with conn.WriteTo(reply, addr)
+type UDPHandler func(ctx context.Context, conn net.PacketConn, addr net.Addr, packet []byte)
+
+// Stats are the counters of a listener
+type Stats struct {
+	Name        string
+	Network     string
+	Addr        string
+	Connections int64
+	Active      int64
+	Packets     int64
+	Errors      int64
+}
+
+type listener struct {
+	name    string
+	network string
+	addr    string
+	tcp     TCPHandler
+	udp     UDPHandler
+	server  *http.Server
+
+	ln net.Listener
+	pc net.PacketConn
+
+	connections int64
+	active      int64
+	packets     int64
+	errors      int64
+}
+
+var mu sync.Mutex
+var registered []*listener
+var wg sync.WaitGroup
+var ctx, cancel = context.WithCancel(context.Background())
+
+// maxPacketSize is the largest udp payload read
+const maxPacketSize = 65535
+
+// TCP registers a tcp listener, e.g: TCP("syslog", ":1514", handleSyslog)
+func TCP(name string, addr string, handler TCPHandler) {
+	mu.Lock()
+	defer mu.Unlock()
+	registered = append(registered, &listener{name: name, network: "tcp", addr: addr, tcp: handler})
+}
+
+// UDP registers a udp listener, e.g: UDP("statsd", ":8125", handleMetric)
+func UDP(name string, addr string, handler UDPHandler) {
+	mu.Lock()
+	defer mu.Unlock()
+	registered = append(registered, &listener{name: name, network: "udp", addr: addr, udp: handler})
+}
+
+// HTTP registers an http listener next to the app's, with its own handler, e.g:
+//
+//	HTTP("ops", "127.0.0.1:9090", ops.Engine())
+func HTTP(name string, addr string, handler http.Handler) {
+	mu.Lock()
+	defer mu.Unlock()
+	server := &http.Server{Addr: addr, Handler: handler}
+	registered = append(registered, &listener{name: name, network: "http", addr: addr, server: server})
+}
+
+// Start opens the registered listeners and serves them in the background
+func Start() error {
+	mu.Lock()
+	defer mu.Unlock()
+	for _, l := range registered {
+		var err error
+		switch l.network {
+		case "tcp":
+			if l.ln, err = net.Listen("tcp", l.addr); err != nil {
+				return err
+			}
+			wg.Add(1)
+			go l.acceptTCP()
+		case "http":
+			if l.ln, err = net.Listen("tcp", l.addr); err != nil {
+				return err
+			}
+			wg.Add(1)
+			go l.serveHTTP()
+		default:
+			if l.pc, err = net.ListenPacket("udp", l.addr); err != nil {
+				return err
+			}
+			wg.Add(1)
+			go l.readUDP()
+		}
+		log.Printf("listening for %s (%s) on %s", l.name, l.network, l.addr)
+	}
+	return nil
+}
+
+// Shutdown closes the listeners, cancels the context of the tcp and udp handlers, then waits for
+// the open tcp connections and http requests to be served or for ctx to be done
+func Shutdown(shutdownCtx context.Context) error {
+	mu.Lock()
+	for _, l := range registered {
+		if l.server != nil {
+			// closes the listener and drains the requests
+			wg.Add(1)
+			go func(server *http.Server) {
+				defer wg.Done()
+				server.Shutdown(shutdownCtx)
+			}(l.server)
+			continue
+		}
+		if l.ln != nil {
+			l.ln.Close()
+		}
+		if l.pc != nil {
+			l.pc.Close()
+		}
+	}
+	mu.Unlock()
+	// tell the tcp and udp handlers to finish up before waiting for them
+	cancel()
+
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-shutdownCtx.Done():
+		return shutdownCtx.Err()
+	}
+}
+
+// All returns the stats of the registered listeners
+func All() []Stats {
+	mu.Lock()
+	defer mu.Unlock()
+	stats := make([]Stats, 0, len(registered))
+	for _, l := range registered {
+		stats = append(stats, Stats{
+			Name:        l.name,
+			Network:     l.network,
+			Addr:        l.addr,
+			Connections: atomic.LoadInt64(&l.connections),
+			Active:      atomic.LoadInt64(&l.active),
+			Packets:     atomic.LoadInt64(&l.packets),
+			Errors:      atomic.LoadInt64(&l.errors),
+		})
+	}
+	return stats
+}
+
+func (l *listener) acceptTCP() {
+	defer wg.Done()
+	for {
+		conn, err := l.ln.Accept()
+		if err != nil {
+			if errors.Is(err, net.ErrClosed) {
+				return
+			}
+			atomic.AddInt64(&l.errors, 1)
+			log.Printf("listener %s: %v", l.name, err)
+			continue
+		}
+		atomic.AddInt64(&l.connections, 1)
+		atomic.AddInt64(&l.active, 1)
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer atomic.AddInt64(&l.active, -1)
+			defer conn.Close()
+			defer l.recoverPanic()
+			l.tcp(ctx, conn)
+		}()
+	}
+}
+
+func (l *listener) serveHTTP() {
+	defer wg.Done()
+	if err := l.server.Serve(l.ln); err != nil && err != http.ErrServerClosed {
+		atomic.AddInt64(&l.errors, 1)
+		log.Printf("listener %s: %v", l.name, err)
+	}
+}
+
+func (l *listener) readUDP() {
+	defer wg.Done()
+	buf := make([]byte, maxPacketSize)
+	for {
+		n, addr, err := l.pc.ReadFrom(buf)
+		if err != nil {
+			if errors.Is(err, net.ErrClosed) {
+				return
+			}
+			atomic.AddInt64(&l.errors, 1)
+			log.Printf("listener %s: %v", l.name, err)
+			continue
+		}
+		atomic.AddInt64(&l.packets, 1)
+		packet := make([]byte, n)
+		copy(packet, buf[:n])
+		func() {
+			defer l.recoverPanic()
+			l.udp(ctx, l.pc, addr, packet)
+		}()
+	}
+}
+
+// recoverPanic keeps a panicking handler from taking the app down
+func (l *listener) recoverPanic() {
+	if r := recover(); r != nil {
+		atomic.AddInt64(&l.errors, 1)
+		log.Printf("listener %s: panic: %v", l.name, r)
+	}
+}
@@ -0,0 +1,13 @@
+// Copyright 2021 Harran Ali <harran.m@gmail.com>. All rights reserved.
+// Use of this source code is governed by MIT-style
+// license that can be found in the LICENSE file.
+
+package listeners
+
+// RegisterListeners registers the tcp, udp and extra http listeners served next to the app
+func RegisterListeners() {
+	// Register your listeners here, e.g:
+	// TCP("syslog", ":1514", handleSyslog)
+	// UDP("statsd", ":8125", handleMetric)
+	// HTTP("webhooks", ":8081", webhooksEngine)
+}
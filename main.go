@@ -5,22 +5,61 @@
 package main
 
 import (
+	"context"
 	"log"
 	"os"
+	"strconv"
+	"time"
 
 	"github.com/gocondor/core"
+	"github.com/gocondor/core/cache"
+	"github.com/gocondor/core/database"
+	coremiddlewares "github.com/gocondor/core/middlewares"
+	"github.com/gocondor/gocondor/about"
+	"github.com/gocondor/gocondor/app"
+	"github.com/gocondor/gocondor/archive"
+	"github.com/gocondor/gocondor/backup"
+	"github.com/gocondor/gocondor/commands"
 	"github.com/gocondor/gocondor/config"
+	"github.com/gocondor/gocondor/container"
+	"github.com/gocondor/gocondor/events"
 	"github.com/gocondor/gocondor/http"
+	"github.com/gocondor/gocondor/http/admin"
 	"github.com/gocondor/gocondor/http/authentication"
+	"github.com/gocondor/gocondor/http/feeds"
 	"github.com/gocondor/gocondor/http/handlers"
+	"github.com/gocondor/gocondor/http/health"
+	"github.com/gocondor/gocondor/http/inbound"
+	"github.com/gocondor/gocondor/http/input"
+	"github.com/gocondor/gocondor/http/menus"
 	"github.com/gocondor/gocondor/http/middlewares"
+	"github.com/gocondor/gocondor/http/ops"
+	"github.com/gocondor/gocondor/http/privacy"
+	"github.com/gocondor/gocondor/http/profiling"
+	"github.com/gocondor/gocondor/http/quota"
+	"github.com/gocondor/gocondor/http/shortlinks"
+	"github.com/gocondor/gocondor/listeners"
+	"github.com/gocondor/gocondor/metrics"
 	"github.com/gocondor/gocondor/models"
+	"github.com/gocondor/gocondor/modules"
+	"github.com/gocondor/gocondor/providers"
+	"github.com/gocondor/gocondor/publishing"
+	"github.com/gocondor/gocondor/reports"
+	"github.com/gocondor/gocondor/retention"
+	"github.com/gocondor/gocondor/revisions"
+	"github.com/gocondor/gocondor/saga"
+	"github.com/gocondor/gocondor/scrubber"
+	"github.com/gocondor/gocondor/spam"
+	"github.com/gocondor/gocondor/tasks"
+	"github.com/gocondor/gocondor/views"
+	"github.com/gocondor/gocondor/warmup"
+	"github.com/gocondor/gocondor/watch"
 	"github.com/joho/godotenv"
 )
 
 func main() {
 	// New initializes new App variable
-	app := core.New()
+	app := app.New()
 
 	// set env
 	env, err := godotenv.Read(".env")
@@ -29,6 +68,35 @@ func main() {
 	}
 	app.SetEnv(env)
 
+	// check the configuration before serving, errors stop the app in release mode, the cli
+	// commands skip it so config:lint and the fixing commands run on a broken configuration
+	if len(os.Args) == 1 {
+		issues := config.Lint(env)
+		for _, issue := range issues {
+			log.Println(issue)
+		}
+		if config.HasErrors(issues) && os.Getenv("APP_MODE") == "release" {
+			log.Fatal("invalid configuration, run: go run main.go config:lint")
+		}
+	}
+
+	// in debug mode APP_WATCH serves a child app that is rebuilt and restarted on changes
+	if os.Getenv("APP_MODE") == "debug" && os.Getenv("APP_WATCH") == "true" && len(os.Args) == 1 && !watch.IsChild() {
+		if err := watch.Run(); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+
+	// serverless containers (Cloud Run, App Engine) and lambda functions terminate TLS themselves
+	if os.Getenv("APP_SERVERLESS") == "true" || os.Getenv("APP_LAMBDA") == "true" {
+		os.Setenv("APP_HTTPS_ON", "false")
+		os.Setenv("APP_REDIRECT_HTTP_TO_HTTPS", "false")
+	}
+
+	// scrub personal data and secrets from the logs, the request logs are scrubbed by the app's engine
+	log.SetOutput(scrubber.Resolve().Writer(os.Stderr))
+
 	// set the app mode
 	app.SetAppMode(os.Getenv("APP_MODE"))
 
@@ -38,8 +106,81 @@ func main() {
 	// initialize core packages
 	app.Bootstrap()
 
+	// attach the database and the cache to the gin context of the requests
+	if config.Features.Database == true {
+		app.Integrate(core.GORM, core.GORMIntegrator(database.Resolve()))
+	}
+	if config.Features.Cache == true {
+		app.Integrate(core.CACHE, core.Cache(cache.Resolve()))
+	}
+
+	// bind the services handlers resolve from the container
+	container.RegisterServices()
+
+	// Register event listeners
+	events.RegisterListeners()
+
+	// register then boot the service providers
+	providers.RegisterProviders()
+	providers.Register()
+	providers.Boot()
+
+	// Register modules
+	modules.RegisterModules()
+
+	// Register reports, views, retention and archiving policies, and the publishable models
+	reports.RegisterReports()
+	views.RegisterViews()
+	retention.RegisterPolicies()
+	archive.RegisterPolicies()
+	publishing.RegisterModels()
+
+	// run a cli command instead of serving when one is given, e.g: go run main.go db:backup,
+	// the ones reading the routes run once they're registered
+	if len(os.Args) > 1 && !routeCommands[os.Args[1]] {
+		runCommand(os.Args[1], os.Args[2:])
+		return
+	}
+
+	// Register custom validation tags
+	input.RegisterValidators()
+
+	// Register the spam checkers of middlewares.SpamCheck
+	spam.RegisterCheckers()
+
+	// the middlewares below are always attached, before the ones of APP_MIDDLEWARES which rely
+	// on them, they're switched on and off with their own env keys, see middlewares.available
+
+	// record the http metrics, attached first so they time the whole chain
+	if os.Getenv("APP_METRICS_ON") == "true" {
+		coremiddlewares.Resolve().Attach(metrics.Middleware)
+		app.SetConnStateHook(metrics.ConnState)
+		metrics.RegisterCollectors()
+		// the ops listener serves /metrics when APP_INTERNAL_ADDR is set
+		if os.Getenv("APP_INTERNAL_ADDR") == "" {
+			metrics.RegisterMetricsRoutes()
+		}
+	}
+
+	// answer 503 while the app is down for maintenance, see maintenance:down
+	coremiddlewares.Resolve().Attach(middlewares.Maintenance())
+
+	// answer the browsers with html error pages, see ERROR_PAGES_DIR
+	if os.Getenv("ERROR_PAGES_ON") == "true" {
+		coremiddlewares.Resolve().Attach(middlewares.ErrorPages())
+	}
+
+	// reject the request bodies over APP_MAX_REQUEST_BODY
+	if limit := middlewares.MaxRequestBody(); limit > 0 {
+		coremiddlewares.Resolve().Attach(middlewares.BodyLimit(limit))
+	}
+
+	// give the menus of the request to the templates rendered through menus.Render
+	coremiddlewares.Resolve().Attach(menus.Middleware)
+
 	// Register global middlewares
 	middlewares.RegisterMiddlewares()
+	modules.RegisterMiddlewares()
 
 	//InitiateHandlersDependencies initiate handlers dependancies
 	handlers.InitiateHandlersDependencies()
@@ -47,8 +188,21 @@ func main() {
 	// InitiateMiddlewaresDependencies initiate handlers dependancies
 	middlewares.InitiateMiddlewaresDependencies()
 
-	// Register routes
+	// Register the health probes, readiness waits for the warmers
+	health.Register("warmup", health.Readiness, health.Ready(warmup.Ready))
+	if config.Features.Database == true {
+		health.Register("database", health.Readiness, health.Database(database.Resolve()))
+	}
+	health.RegisterChecks()
+	// the ops listener serves the probes when APP_INTERNAL_ADDR is set
+	if os.Getenv("APP_INTERNAL_ADDR") == "" {
+		health.RegisterHealthRoutes()
+	}
+
+	// Register routes, and the menus and breadcrumbs built from their names
 	http.RegisterRoutes()
+	modules.RegisterRoutes()
+	menus.RegisterMenus()
 
 	// Register Auth
 	if config.Features.Authentication == true {
@@ -59,11 +213,235 @@ func main() {
 		authentication.RegisterAuthRoutes()
 	}
 
+	// Register the pprof endpoints, they require the admin token
+	if os.Getenv("APP_PPROF_ON") == "true" {
+		if os.Getenv("APP_ADMIN_TOKEN") == "" {
+			log.Fatal("APP_PPROF_ON requires APP_ADMIN_TOKEN to be set")
+		}
+		profiling.RegisterProfilingRoutes()
+	}
+
+	// Register the inbound mail webhooks
+	if os.Getenv("INBOUND_MAIL_TOKEN") != "" {
+		inbound.RegisterHandlers()
+		inbound.RegisterInboundRoutes()
+	}
+
+	// Register the endpoint showing the clients their quotas usage
+	if config.Features.Database == true {
+		quota.RegisterQuotaRoutes()
+	}
+
+	// Register the admin endpoints
+	if config.Features.Database == true && os.Getenv("APP_ADMIN_TOKEN") != "" {
+		admin.RegisterAdminRoutes()
+	}
+
+	// Register the sitemap and the feeds
+	feeds.RegisterFeeds()
+	feeds.RegisterFeedsRoutes()
+
+	// Register the consent and the user data export endpoints
+	privacy.RegisterPrivacyRoutes()
+
+	// Register the short links
+	if config.Features.Database == true {
+		shortlinks.RegisterShortLinksRoutes()
+	}
+
+	// run the cli commands reading the routes, e.g: go run main.go route:list
+	if len(os.Args) > 1 {
+		runCommand(os.Args[1], os.Args[2:])
+		return
+	}
+
 	//auto migrate tables
 	if config.Features.Database == true {
 		models.MigrateDB()
+		modules.Migrate()
+		// register the model callbacks (read-only mode, versions, slugs, change capture)
+		models.RegisterCallbacks()
+		// snapshot the records of the models registered with revisions.Register
+		revisions.RegisterCallbacks()
+
+		// generate the reports on their schedule
+		reports.Schedule(database.Resolve())
+
+		// dispatch the changes of the captured models as events
+		events.DispatchChanges()
+
+		// refresh the derived tables on their schedule and on the changes of their sources
+		views.Start(database.Resolve())
+
+		// prune and archive the rows matching the retention and archiving policies,
+		// and anonymize the deleted users
+		retention.Schedule(database.Resolve(), retentionInterval())
+		archive.Schedule(database.Resolve(), retentionInterval())
+		anonymizing, stopAnonymizing := context.WithCancel(context.Background())
+		privacy.ScheduleRetention(anonymizing, database.Resolve(), retentionInterval(), anonymizeAfter())
+		tasks.PostStop(tasks.Task{Name: "stop anonymizing", Policy: tasks.Continue, Run: func(ctx context.Context) error {
+			stopAnonymizing()
+			return nil
+		}})
+
+		// publish the scheduled records once their publish time has passed
+		publishing.Schedule(database.Resolve(), publishInterval())
+
+		// back the database up to BACKUP_DIR when BACKUP_INTERVAL_HOURS is set
+		if interval := backupInterval(); interval > 0 {
+			backup.Schedule(interval)
+		}
+
+		// start the background work of the modules
+		modules.Start()
+
+		// register the workflows and resume the runs interrupted by a shutdown once their lease expired
+		saga.RegisterWorkflows()
+		saga.Schedule(database.Resolve())
+	}
+
+	// serve the tcp, udp and ops http listeners next to http, they're closed on shutdown
+	listeners.RegisterListeners()
+	if addr := os.Getenv("APP_INTERNAL_ADDR"); addr != "" {
+		listeners.HTTP("ops", addr, ops.Engine())
+	}
+	if err := listeners.Start(); err != nil {
+		log.Fatal(err)
+	}
+	tasks.PostStop(tasks.Task{Name: "close listeners", Policy: tasks.Continue, Timeout: 10 * time.Second, Run: listeners.Shutdown})
+
+	// stop serving on shutdown, the requests being served are drained first for up to APP_SHUTDOWN_TIMEOUT
+	tasks.PostStop(tasks.Task{Name: "drain http", Order: -1, Policy: tasks.Continue, Timeout: shutdownTimeout(), Run: app.Shutdown})
+
+	// close the database connections last, once nothing uses them anymore
+	if config.Features.Database == true {
+		tasks.PostStop(tasks.Task{Name: "close database", Order: 100, Policy: tasks.Continue, Timeout: 10 * time.Second, Run: closeDatabase})
+	}
+
+	// run the tasks declared around the app's lifetime
+	tasks.RegisterTasks()
+	if err := tasks.RunPreStart(); err != nil {
+		log.Fatal(err)
+	}
+	stopped := tasks.RunPostStopOnSignal()
+
+	// warm up caches and connections in the background, the "warmup" middleware holds requests meanwhile
+	warmup.RegisterWarmers()
+	warmup.Start(warmupTimeout())
+
+	// print the boot summary, it's available to the app with app.Info()
+	if os.Getenv("APP_BANNER") == "true" {
+		about.Print(os.Stdout, app.Info())
+	}
+
+	// on aws lambda the invocations come from the runtime api instead of a port
+	if os.Getenv("APP_LAMBDA") == "true" {
+		if err := app.RunLambda(context.Background()); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+
+	// Run App, it returns once the shutdown drained the servers
+	if err := app.Run(httpPort()); err != nil {
+		log.Fatal(err)
+	}
+
+	// wait for the remaining post-stop tasks before exiting
+	if err := <-stopped; err != nil {
+		log.Println(err)
+		os.Exit(1)
+	}
+}
+
+// routeCommands are the cli commands run once the routes and the middlewares are registered
+var routeCommands = map[string]bool{"route:list": true, "about": true}
+
+// runCommand runs a cli command, the app exits on its error
+func runCommand(name string, args []string) {
+	commands.RegisterCommands()
+	modules.RegisterCommands()
+	about.RegisterCommands()
+	if err := commands.Run(name, args); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// httpPort returns the port to listen on, in serverless mode the platform provided PORT wins
+func httpPort() string {
+	if os.Getenv("APP_SERVERLESS") == "true" && os.Getenv("PORT") != "" {
+		return os.Getenv("PORT")
+	}
+	return os.Getenv("APP_HTTP_PORT")
+}
+
+// serverlessDrain is the part of the 10s Cloud Run and App Engine give a container
+// between SIGTERM and SIGKILL left to drain the requests, the rest is for the other tasks
+const serverlessDrain = 8 * time.Second
+
+// shutdownTimeout returns how long the requests being served are drained on shutdown,
+// in serverless mode it doesn't exceed the platform's window
+func shutdownTimeout() time.Duration {
+	timeout, err := time.ParseDuration(os.Getenv("APP_SHUTDOWN_TIMEOUT"))
+	if err != nil || timeout <= 0 {
+		timeout = 30 * time.Second
+	}
+	if os.Getenv("APP_SERVERLESS") == "true" && timeout > serverlessDrain {
+		return serverlessDrain
+	}
+	return timeout
+}
+
+// closeDatabase closes the connections pool of the database
+func closeDatabase(ctx context.Context) error {
+	db, err := database.Resolve().DB()
+	if err != nil {
+		return err
 	}
+	return db.Close()
+}
+
+// warmupTimeout returns how long to wait for the warmers before serving anyway
+func warmupTimeout() time.Duration {
+	seconds, err := strconv.Atoi(os.Getenv("WARMUP_TIMEOUT_SECONDS"))
+	if err != nil || seconds <= 0 {
+		return 30 * time.Second
+	}
+	return time.Duration(seconds) * time.Second
+}
+
+// publishInterval returns how often the scheduled records are checked for publishing
+func publishInterval() time.Duration {
+	seconds, err := strconv.Atoi(os.Getenv("PUBLISH_INTERVAL_SECONDS"))
+	if err != nil || seconds <= 0 {
+		return time.Minute
+	}
+	return time.Duration(seconds) * time.Second
+}
 
-	// Run App
-	app.Run(os.Getenv("APP_HTTP_PORT"))
+// backupInterval returns how often the database is backed up, zero when it isn't
+func backupInterval() time.Duration {
+	hours, err := strconv.Atoi(os.Getenv("BACKUP_INTERVAL_HOURS"))
+	if err != nil || hours <= 0 {
+		return 0
+	}
+	return time.Duration(hours) * time.Hour
+}
+
+// retentionInterval returns how often the retention and archiving policies run
+func retentionInterval() time.Duration {
+	minutes, err := strconv.Atoi(os.Getenv("RETENTION_INTERVAL_MINUTES"))
+	if err != nil || minutes <= 0 {
+		return time.Hour
+	}
+	return time.Duration(minutes) * time.Minute
+}
+
+// anonymizeAfter returns how long the deleted users keep their personal data
+func anonymizeAfter() time.Duration {
+	days, err := strconv.Atoi(os.Getenv("PRIVACY_ANONYMIZE_AFTER_DAYS"))
+	if err != nil || days <= 0 {
+		return 30 * 24 * time.Hour
+	}
+	return time.Duration(days) * 24 * time.Hour
 }
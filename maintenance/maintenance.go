@@ -0,0 +1,145 @@
+// Copyright 2021 Harran Ali <harran.m@gmail.com>. All rights reserved.
+// Use of this source code is governed by MIT-style
+// license that can be found in the LICENSE file.
+
+package maintenance
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// State describes an ongoing maintenance, it's persisted in MAINTENANCE_FILE
+// so it survives restarts and is shared by the instances sharing the file
+type State struct {
+	Since time.Time `json:"since"`
+	// Message replaces the default message of the 503 response
+	Message string `json:"message,omitempty"`
+	// RetryAfter is sent in the Retry-After header, in seconds
+	RetryAfter int `json:"retry_after,omitempty"`
+	// Allow lists the ips or cidrs still served during the maintenance
+	Allow []string `json:"allow,omitempty"`
+	// Secret lets whoever visits /{secret} through, they get a bypass cookie
+	Secret string `json:"secret,omitempty"`
+}
+
+// how long the state read from the file is reused before checking the file again
+const recheckEvery = time.Second
+
+var mu sync.Mutex
+var current *State
+var checkedAt time.Time
+
+// Down puts the app in maintenance mode
+func Down(state State) error {
+	if state.Since.IsZero() {
+		state.Since = time.Now()
+	}
+	data, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	path := file()
+	if err := os.MkdirAll(filepath.Dir(path), 0750); err != nil {
+		return err
+	}
+	if err := os.WriteFile(path, data, 0640); err != nil {
+		return err
+	}
+	forget()
+	return nil
+}
+
+// Up brings the app out of maintenance mode
+func Up() error {
+	if err := os.Remove(file()); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	forget()
+	return nil
+}
+
+// Current returns the ongoing maintenance, ok is false while the app is up
+func Current() (state State, ok bool) {
+	mu.Lock()
+	defer mu.Unlock()
+	if time.Since(checkedAt) >= recheckEvery {
+		current = read()
+		checkedAt = time.Now()
+	}
+	if current == nil {
+		return State{}, false
+	}
+	return *current, true
+}
+
+// Allowed reports whether the ip is in the state's allow list
+func (s State) Allowed(ip string) bool {
+	parsed := net.ParseIP(ip)
+	if parsed == nil {
+		return false
+	}
+	for _, entry := range s.Allow {
+		entry = strings.TrimSpace(entry)
+		if _, cidr, err := net.ParseCIDR(entry); err == nil {
+			if cidr.Contains(parsed) {
+				return true
+			}
+			continue
+		}
+		if allowed := net.ParseIP(entry); allowed != nil && allowed.Equal(parsed) {
+			return true
+		}
+	}
+	return false
+}
+
+// BypassToken is the value of the bypass cookie, it changes with the secret
+// so the cookies handed out during a previous maintenance stop working
+func (s State) BypassToken() string {
+	if s.Secret == "" {
+		return ""
+	}
+	mac := hmac.New(sha256.New, []byte(s.Secret))
+	mac.Write([]byte(s.Since.UTC().Format(time.RFC3339Nano)))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// read reads the state from the file, it returns nil while the file doesn't exist
+func read() *State {
+	data, err := os.ReadFile(file())
+	if err != nil {
+		return nil
+	}
+	var state State
+	if err := json.Unmarshal(data, &state); err != nil {
+		// a broken file still means the app was put down
+		return &State{}
+	}
+	return &state
+}
+
+// forget drops the cached state so the next check reads the file
+func forget() {
+	mu.Lock()
+	defer mu.Unlock()
+	checkedAt = time.Time{}
+}
+
+// file returns the path of the maintenance file
+func file() string {
+	if path := os.Getenv("MAINTENANCE_FILE"); path != "" {
+		return path
+	}
+	return "storage/maintenance.json"
+}
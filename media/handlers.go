@@ -0,0 +1,208 @@
+// Copyright 2021 Harran Ali <harran.m@gmail.com>. All rights reserved.
+// Use of this source code is governed by MIT-style
+// license that can be found in the LICENSE file.
+
+package media
+
+import (
+	"errors"
+	"net/http"
+	"path"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gocondor/core/database"
+	"github.com/gocondor/gocondor/http/input"
+	"github.com/gocondor/gocondor/http/response"
+	"github.com/gocondor/gocondor/i18n"
+)
+
+// UploadInput is the collection the uploaded file is attached to
+type UploadInput struct {
+	Collection string `form:"collection" json:"collection"`
+}
+
+// OrderInput lists the ids of the collection's media in their new order
+type OrderInput struct {
+	Collection string `form:"collection" json:"collection"`
+	IDs        []uint `form:"ids" json:"ids" binding:"required"`
+}
+
+// Policy authorizes the requests on the media of a resource
+type Policy struct {
+	// View decides whether the request may list the media of the record, nil allows everyone
+	View func(c *gin.Context, id uint) bool
+	// Manage decides whether the request may upload, reorder and delete, nil allows no one
+	Manage func(c *gin.Context, id uint) bool
+}
+
+// resource is a model files can be attached to
+type resource struct {
+	model  reflect.Type
+	policy Policy
+}
+
+var resourcesMu sync.RWMutex
+var resources = map[string]resource{}
+
+// Register lets files be attached to the records of model under name in the routes, e.g:
+//
+//	media.Register("products", &models.Product{}, media.Policy{Manage: isAdmin})
+func Register(name string, model interface{}, policy Policy) {
+	resourcesMu.Lock()
+	defer resourcesMu.Unlock()
+	resources[name] = resource{model: reflect.Indirect(reflect.ValueOf(model)).Type(), policy: policy}
+}
+
+// MediaIndex lists the media of a record, of one collection with ?collection=
+func MediaIndex(c *gin.Context) {
+	record, ok := routeRecord(c, false)
+	if !ok {
+		return
+	}
+	list, err := Of(database.Resolve(), record, c.Query("collection"))
+	if err != nil {
+		response.AbortWithDBError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": list,
+	})
+}
+
+// MediaStore attaches the uploaded "file" to a record
+func MediaStore(c *gin.Context) {
+	record, ok := routeRecord(c, true)
+	if !ok {
+		return
+	}
+	var in UploadInput
+	if err := c.ShouldBind(&in); err != nil {
+		response.AbortWithValidationError(c, err)
+		return
+	}
+	if in.Collection == "" {
+		in.Collection = "default"
+	}
+	upload, err := c.FormFile("file")
+	if err != nil {
+		message := i18n.T(i18n.Locale(c), "validation.required", map[string]string{"field": "file"})
+		c.AbortWithStatusJSON(http.StatusUnprocessableEntity, gin.H{
+			"message": message,
+			"errors":  gin.H{"file": message},
+		})
+		return
+	}
+
+	m, err := Attach(database.Resolve(), record, in.Collection, upload)
+	if errors.Is(err, ErrRejected) || errors.Is(err, ErrUnknownCollection) {
+		c.AbortWithStatusJSON(http.StatusUnprocessableEntity, gin.H{
+			"message": err.Error(),
+		})
+		return
+	}
+	if err != nil {
+		response.AbortWithDBError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"data": m,
+	})
+}
+
+// MediaReorder orders the media of a record's collection
+func MediaReorder(c *gin.Context) {
+	record, ok := routeRecord(c, true)
+	if !ok {
+		return
+	}
+	var in OrderInput
+	if err := c.ShouldBind(&in); err != nil {
+		response.AbortWithValidationError(c, err)
+		return
+	}
+	if in.Collection == "" {
+		in.Collection = "default"
+	}
+
+	if err := Reorder(database.Resolve(), record, in.Collection, in.IDs); err != nil {
+		response.AbortWithDBError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "media reordered successfully",
+	})
+}
+
+// MediaDestroy deletes a media of a record and its files
+func MediaDestroy(c *gin.Context) {
+	record, ok := routeRecord(c, true)
+	if !ok {
+		return
+	}
+	id, err := strconv.ParseUint(c.Param("media"), 10, 64)
+	if err != nil {
+		response.AbortNotFound(c)
+		return
+	}
+	db := database.Resolve()
+	var m Media
+	if err := db.Scopes(In(record, "")).First(&m, id).Error; err != nil {
+		response.AbortWithDBError(c, err)
+		return
+	}
+	if err := Remove(db, &m); err != nil {
+		response.AbortWithDBError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "media deleted successfully",
+	})
+}
+
+// MediaFile serves a stored file, sandboxed as the files come from the users
+func MediaFile(c *gin.Context) {
+	file := path.Clean("/" + c.Param("file"))
+	if strings.Contains(file, "..") || file == "/" {
+		response.AbortNotFound(c)
+		return
+	}
+	c.Header("Cache-Control", "public, max-age=31536000, immutable")
+	// uploaded html or svg must not run scripts on the app's origin
+	c.Header("Content-Security-Policy", "default-src 'none'; style-src 'unsafe-inline'; sandbox")
+	c.Header("X-Content-Type-Options", "nosniff")
+	c.File(filepath.Join(Dir(), filepath.FromSlash(file)))
+}
+
+// routeRecord loads the record of the route after checking the policy, the request is
+// aborted when ok is false
+func routeRecord(c *gin.Context, manage bool) (record interface{}, ok bool) {
+	resourcesMu.RLock()
+	r, registered := resources[c.Param("resource")]
+	resourcesMu.RUnlock()
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if !registered || err != nil {
+		response.AbortNotFound(c)
+		return nil, false
+	}
+
+	allowed := r.policy.View == nil || r.policy.View(c, uint(id))
+	if manage {
+		allowed = r.policy.Manage != nil && r.policy.Manage(c, uint(id))
+	}
+	if !allowed {
+		response.AbortForbidden(c)
+		return nil, false
+	}
+
+	record, _, ok = input.RouteRecord(c, database.Resolve(), r.model)
+	return record, ok
+}
@@ -0,0 +1,324 @@
+// Copyright 2021 Harran Ali <harran.m@gmail.com>. All rights reserved.
+// Use of this source code is governed by MIT-style
+// license that can be found in the LICENSE file.
+
+package media
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/gocondor/gocondor/models"
+	"gorm.io/gorm"
+)
+
+// Collection declares the rules of a named group of files, e.g: a single "avatar" image
+type Collection struct {
+	Name string
+	// Accepts lists the accepted mime types, a type ending in / accepts its subtypes, e.g: image/
+	Accepts []string
+	// MaxSize is the largest accepted file in bytes, 0 accepts any size
+	MaxSize int64
+	// Single collections hold one file, attaching replaces it
+	Single bool
+	// Conversions derive variants from the attached files, e.g: thumbnails or responsive widths
+	Conversions []Conversion
+}
+
+// Conversion derives a variant from an attached file
+type Conversion struct {
+	Name string
+	// Convert writes the variant of the file at src to dst, dst keeps the extension of src
+	Convert func(src string, dst string) error
+}
+
+// ErrUnknownCollection is returned when attaching to a collection that wasn't defined
+var ErrUnknownCollection = errors.New("unknown media collection")
+
+// ErrRejected is returned when the file breaks the rules of the collection
+var ErrRejected = errors.New("the file isn't accepted")
+
+var mu sync.RWMutex
+var collections = map[string]Collection{
+	"default": {Name: "default"},
+}
+
+// DefineCollection declares a collection, e.g:
+//
+//	media.DefineCollection(media.Collection{Name: "avatar", Accepts: []string{"image/"}, MaxSize: 2 << 20, Single: true})
+func DefineCollection(c Collection) {
+	mu.Lock()
+	defer mu.Unlock()
+	collections[c.Name] = c
+}
+
+// collection returns the collection defined under name
+func collection(name string) (Collection, error) {
+	mu.RLock()
+	defer mu.RUnlock()
+	c, ok := collections[name]
+	if !ok {
+		return Collection{}, fmt.Errorf("%w: %s", ErrUnknownCollection, name)
+	}
+	return c, nil
+}
+
+// Attach stores the uploaded file and attaches it to the end of the record's collection,
+// the conversions of the collection are run on the stored file
+func Attach(db *gorm.DB, record interface{}, collectionName string, upload *multipart.FileHeader) (*Media, error) {
+	c, err := collection(collectionName)
+	if err != nil {
+		return nil, err
+	}
+	mediableType, mediableID, err := models.Subject(db, record)
+	if err != nil {
+		return nil, err
+	}
+	if c.MaxSize > 0 && upload.Size > c.MaxSize {
+		return nil, fmt.Errorf("%w: larger than %d bytes", ErrRejected, c.MaxSize)
+	}
+
+	src, err := upload.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer src.Close()
+	head := make([]byte, 512)
+	n, err := io.ReadFull(src, head)
+	if err != nil && err != io.ErrUnexpectedEOF && err != io.EOF {
+		return nil, err
+	}
+	mimeType := http.DetectContentType(head[:n])
+	if !accepts(c, mimeType) {
+		return nil, fmt.Errorf("%w: %s", ErrRejected, mimeType)
+	}
+
+	name, err := randomName()
+	if err != nil {
+		return nil, err
+	}
+	m := &Media{
+		MediableType: mediableType,
+		MediableID:   mediableID,
+		Collection:   c.Name,
+		Name:         filepath.Base(upload.Filename),
+		File:         path.Join(mediableType, fmt.Sprint(mediableID), name+extension(upload.Filename, mimeType)),
+		MimeType:     mimeType,
+		Size:         upload.Size,
+		Variants:     Variants{},
+	}
+	if err := store(src, head[:n], m.File); err != nil {
+		return nil, err
+	}
+	for _, conversion := range c.Conversions {
+		variant := strings.TrimSuffix(m.File, path.Ext(m.File)) + "-" + conversion.Name + path.Ext(m.File)
+		if err := conversion.Convert(fullPath(m.File), fullPath(variant)); err != nil {
+			log.Printf("media: conversion %s of %s: %v", conversion.Name, m.File, err)
+			continue
+		}
+		m.Variants[conversion.Name] = variant
+	}
+
+	var replaced []Media
+	err = db.Transaction(func(tx *gorm.DB) error {
+		if c.Single {
+			if err := tx.Scopes(In(record, c.Name)).Find(&replaced).Error; err != nil {
+				return err
+			}
+			if len(replaced) > 0 {
+				if err := tx.Delete(&replaced).Error; err != nil {
+					return err
+				}
+			}
+		}
+		var last struct{ Position int }
+		if err := tx.Model(&Media{}).Scopes(In(record, c.Name)).Select("COALESCE(MAX(position), 0) AS position").Scan(&last).Error; err != nil {
+			return err
+		}
+		m.Position = last.Position + 1
+		return tx.Create(m).Error
+	})
+	if err != nil {
+		removeFiles(m)
+		return nil, err
+	}
+	for i := range replaced {
+		removeFiles(&replaced[i])
+	}
+	m.URLs = urls(m)
+	return m, nil
+}
+
+// Of returns the media of the record's collection in order
+func Of(db *gorm.DB, record interface{}, collectionName string) ([]Media, error) {
+	var list []Media
+	err := db.Scopes(In(record, collectionName)).Order("position, id").Find(&list).Error
+	return list, err
+}
+
+// Reorder orders the media of the record's collection as listed by ids, the media missing
+// from ids keep their order after the listed ones
+func Reorder(db *gorm.DB, record interface{}, collectionName string, ids []uint) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		list, err := Of(tx, record, collectionName)
+		if err != nil {
+			return err
+		}
+		positions := make(map[uint]int, len(ids))
+		for i, id := range ids {
+			positions[id] = i + 1
+		}
+		next := len(ids) + 1
+		for _, m := range list {
+			position, listed := positions[m.ID]
+			if !listed {
+				position = next
+				next++
+			}
+			if err := tx.Model(&Media{}).Where("id = ?", m.ID).UpdateColumn("position", position).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// Remove deletes the media and its files
+func Remove(db *gorm.DB, m *Media) error {
+	if err := db.Delete(m).Error; err != nil {
+		return err
+	}
+	removeFiles(m)
+	return nil
+}
+
+// In is a query scope keeping the media of the record's collection, an empty collection keeps them all
+func In(record interface{}, collectionName string) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		mediableType, mediableID, err := models.Subject(db, record)
+		if err != nil {
+			db.AddError(err)
+			return db
+		}
+		db = db.Where("mediable_type = ? AND mediable_id = ?", mediableType, mediableID)
+		if collectionName != "" {
+			db = db.Where("collection = ?", collectionName)
+		}
+		return db
+	}
+}
+
+// Dir is the directory the files are stored in, MEDIA_DIR
+func Dir() string {
+	if dir := os.Getenv("MEDIA_DIR"); dir != "" {
+		return dir
+	}
+	return "storage/media"
+}
+
+// URL returns the public url of a stored file, prefixed with MEDIA_URL
+func URL(file string) string {
+	base := os.Getenv("MEDIA_URL")
+	if base == "" {
+		base = "/uploads"
+	}
+	return strings.TrimRight(base, "/") + "/" + file
+}
+
+// urls maps the original file and the variants of the media to their urls
+func urls(m *Media) map[string]string {
+	all := map[string]string{"original": URL(m.File)}
+	for name, file := range m.Variants {
+		all[name] = URL(file)
+	}
+	return all
+}
+
+// accepts reports whether the collection accepts the mime type
+func accepts(c Collection, mimeType string) bool {
+	if len(c.Accepts) == 0 {
+		return true
+	}
+	mimeType = strings.SplitN(mimeType, ";", 2)[0]
+	for _, accepted := range c.Accepts {
+		if accepted == mimeType || (strings.HasSuffix(accepted, "/") && strings.HasPrefix(mimeType, accepted)) {
+			return true
+		}
+	}
+	return false
+}
+
+// store writes the head already read and the rest of src to the file
+func store(src io.Reader, head []byte, file string) error {
+	dst := fullPath(file)
+	if err := os.MkdirAll(filepath.Dir(dst), 0750); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(dst, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0640)
+	if err != nil {
+		return err
+	}
+	if _, err = io.Copy(f, io.MultiReader(bytes.NewReader(head), src)); err != nil {
+		f.Close()
+		os.Remove(dst)
+		return err
+	}
+	return f.Close()
+}
+
+// removeFiles deletes the original file and the variants of the media
+func removeFiles(m *Media) {
+	files := []string{m.File}
+	for _, file := range m.Variants {
+		files = append(files, file)
+	}
+	for _, file := range files {
+		if err := os.Remove(fullPath(file)); err != nil && !errors.Is(err, os.ErrNotExist) {
+			log.Printf("media: %v", err)
+		}
+	}
+}
+
+// fullPath returns the path of a stored file on disk
+func fullPath(file string) string {
+	return filepath.Join(Dir(), filepath.FromSlash(file))
+}
+
+// extension returns the extension of the uploaded file, or one matching its mime type
+func extension(filename string, mimeType string) string {
+	ext := strings.ToLower(filepath.Ext(filename))
+	valid := len(ext) > 1 && len(ext) <= 10
+	for _, r := range strings.TrimPrefix(ext, ".") {
+		if (r < 'a' || r > 'z') && (r < '0' || r > '9') {
+			valid = false
+		}
+	}
+	if valid {
+		return ext
+	}
+	if exts, err := mime.ExtensionsByType(mimeType); err == nil && len(exts) > 0 {
+		return exts[0]
+	}
+	return ""
+}
+
+func randomName() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
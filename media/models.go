@@ -0,0 +1,70 @@
+// Copyright 2021 Harran Ali <harran.m@gmail.com>. All rights reserved.
+// Use of this source code is governed by MIT-style
+// license that can be found in the LICENSE file.
+
+package media
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+
+	"gorm.io/gorm"
+)
+
+// Media is a file attached to a record of any model, identified by its table and id,
+// the files of a record are grouped in ordered collections
+type Media struct {
+	gorm.Model
+	MediableType string   `gorm:"size:64;index:idx_media_mediable" json:"mediableType"`
+	MediableID   uint     `gorm:"index:idx_media_mediable" json:"mediableId"`
+	Collection   string   `gorm:"size:64" json:"collection"`
+	Name         string   `gorm:"size:255" json:"name"`
+	File         string   `gorm:"size:255" json:"-"`
+	MimeType     string   `gorm:"size:127" json:"mimeType"`
+	Size         int64    `json:"size"`
+	Position     int      `json:"position"`
+	Variants     Variants `gorm:"type:text" json:"-"`
+	// URLs maps "original" and the variants to their public urls
+	URLs map[string]string `gorm:"-" json:"urls"`
+}
+
+// TableName names the table of the media
+func (Media) TableName() string {
+	return "media"
+}
+
+// AfterFind fills the urls of the loaded media
+func (m *Media) AfterFind(tx *gorm.DB) error {
+	m.URLs = urls(m)
+	return nil
+}
+
+// Variants maps the names of the conversions to their files
+type Variants map[string]string
+
+// Value stores the variants as json
+func (v Variants) Value() (driver.Value, error) {
+	if len(v) == 0 {
+		return "{}", nil
+	}
+	data, err := json.Marshal(v)
+	return string(data), err
+}
+
+// Scan reads the variants stored as json
+func (v *Variants) Scan(value interface{}) error {
+	var data []byte
+	switch value := value.(type) {
+	case nil:
+		*v = Variants{}
+		return nil
+	case string:
+		data = []byte(value)
+	case []byte:
+		data = value
+	default:
+		return errors.New("variants must be stored as text")
+	}
+	return json.Unmarshal(data, v)
+}
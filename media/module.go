@@ -0,0 +1,53 @@
+// Copyright 2021 Harran Ali <harran.m@gmail.com>. All rights reserved.
+// Use of this source code is governed by MIT-style
+// license that can be found in the LICENSE file.
+
+package media
+
+import (
+	"os"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gocondor/core/routing"
+	"github.com/gocondor/gocondor/commands"
+)
+
+// Module plugs the media library into the app, register it in modules.RegisterModules
+// and the models files can be attached to with Register
+type Module struct{}
+
+// Name identifies the module
+func (Module) Name() string {
+	return "media"
+}
+
+// Routes registers the media endpoints of the registered resources, the files are served
+// under MEDIA_URL unless it points to another host, e.g: a cdn
+func (Module) Routes() {
+	router := routing.Resolve()
+
+	router.Get("/media/:resource/:id", MediaIndex)
+	router.Post("/media/:resource/:id", MediaStore)
+	router.Put("/media/:resource/:id/order", MediaReorder)
+	router.Delete("/media/:resource/:id/:media", MediaDestroy)
+
+	base := os.Getenv("MEDIA_URL")
+	if base == "" {
+		base = "/uploads"
+	}
+	if strings.HasPrefix(base, "/") {
+		router.Get(strings.TrimRight(base, "/")+"/*file", MediaFile)
+	}
+}
+
+// Middlewares returns no middlewares
+func (Module) Middlewares() []gin.HandlerFunc { return nil }
+
+// Migrations returns the media
+func (Module) Migrations() []interface{} {
+	return []interface{}{&Media{}}
+}
+
+// Commands returns no commands
+func (Module) Commands() map[string]commands.Command { return nil }
@@ -0,0 +1,84 @@
+// Copyright 2021 Harran Ali <harran.m@gmail.com>. All rights reserved.
+// Use of this source code is governed by MIT-style
+// license that can be found in the LICENSE file.
+
+package metrics
+
+import (
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gocondor/core/routing"
+	"github.com/gocondor/gocondor/http/middlewares"
+)
+
+// the http metrics recorded by Middleware
+var (
+	requests = NewCounter("http_requests_total", "Number of http requests by method, route and status.")
+	duration = NewHistogram("http_request_duration_seconds", "Duration of the http requests by method and route.", nil)
+	inFlight = NewGauge("http_requests_in_flight", "Number of http requests being served.")
+)
+
+// the connections metrics recorded by ConnState
+var (
+	connections     = NewGauge("http_connections", "Number of open http connections by state.")
+	connectionsSeen = NewCounter("http_connection_states_total", "Number of http connections which entered a state.")
+
+	connMu     sync.Mutex
+	connStates = map[net.Conn]http.ConnState{}
+)
+
+// Middleware records the request counts, durations and the requests in flight
+func Middleware(c *gin.Context) {
+	started := time.Now()
+	inFlight.Add(1)
+	defer inFlight.Add(-1)
+
+	c.Next()
+
+	// the route pattern keeps the number of series bounded
+	route := c.FullPath()
+	if route == "" {
+		route = "unmatched"
+	}
+	method := c.Request.Method
+	requests.Inc("method", method, "route", route, "status", strconv.Itoa(c.Writer.Status()))
+	duration.Observe(time.Since(started).Seconds(), "method", method, "route", route)
+}
+
+// ConnState records the states of the http connections, the new, active and idle connections
+// are counted by the http_connections gauge, it's set as the servers' hook in main.go
+func ConnState(conn net.Conn, state http.ConnState) {
+	connectionsSeen.Inc("state", state.String())
+
+	connMu.Lock()
+	defer connMu.Unlock()
+	if previous, ok := connStates[conn]; ok {
+		connections.Add(-1, "state", previous.String())
+	}
+	// the hijacked connections are no longer the server's, e.g: websockets
+	if state == http.StateHijacked || state == http.StateClosed {
+		delete(connStates, conn)
+		return
+	}
+	connStates[conn] = state
+	connections.Add(1, "state", state.String())
+}
+
+// Handler serves the metrics in the prometheus text format
+func Handler(c *gin.Context) {
+	c.Header("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	Write(c.Writer)
+}
+
+// RegisterMetricsRoutes registers the endpoint prometheus scrapes on the public port, it requires
+// the X-Admin-Token header, the ops listener of APP_INTERNAL_ADDR serves it without
+func RegisterMetricsRoutes() {
+	router := routing.Resolve()
+
+	router.Get("/metrics", middlewares.AdminToken, Handler)
+}
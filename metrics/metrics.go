@@ -0,0 +1,238 @@
+// Copyright 2021 Harran Ali <harran.m@gmail.com>. All rights reserved.
+// Use of this source code is governed by MIT-style
+// license that can be found in the LICENSE file.
+
+package metrics
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// DefaultBuckets are the histogram buckets in seconds, the same as the prometheus client's
+var DefaultBuckets = []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10}
+
+// Collector writes metrics computed on scrape in the prometheus text format
+type Collector func(w io.Writer)
+
+var mu sync.RWMutex
+var metrics []metric
+var collectors []Collector
+
+type metric interface {
+	write(w io.Writer)
+}
+
+// series holds the values of a metric by their rendered labels
+type series struct {
+	mu     sync.Mutex
+	name   string
+	help   string
+	kind   string
+	values map[string]float64
+}
+
+func newSeries(name, help, kind string) *series {
+	s := &series{name: name, help: help, kind: kind, values: map[string]float64{}}
+	mu.Lock()
+	defer mu.Unlock()
+	metrics = append(metrics, s)
+	return s
+}
+
+func (s *series) add(v float64, labels []string) {
+	key := renderLabels(labels)
+	s.mu.Lock()
+	s.values[key] += v
+	s.mu.Unlock()
+}
+
+func (s *series) set(v float64, labels []string) {
+	key := renderLabels(labels)
+	s.mu.Lock()
+	s.values[key] = v
+	s.mu.Unlock()
+}
+
+func (s *series) write(w io.Writer) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n", s.name, s.help, s.name, s.kind)
+	for _, key := range sortedKeys(s.values) {
+		fmt.Fprintf(w, "%s%s %s\n", s.name, braces(key), formatValue(s.values[key]))
+	}
+}
+
+// Counter is a value that only goes up
+type Counter struct {
+	s *series
+}
+
+// NewCounter registers a counter
+func NewCounter(name, help string) *Counter {
+	return &Counter{newSeries(name, help, "counter")}
+}
+
+// Inc adds one, labels are name and value pairs, e.g: counter.Inc("method", "GET")
+func (c *Counter) Inc(labels ...string) {
+	c.s.add(1, labels)
+}
+
+// Add adds v
+func (c *Counter) Add(v float64, labels ...string) {
+	c.s.add(v, labels)
+}
+
+// Gauge is a value that goes up and down
+type Gauge struct {
+	s *series
+}
+
+// NewGauge registers a gauge
+func NewGauge(name, help string) *Gauge {
+	return &Gauge{newSeries(name, help, "gauge")}
+}
+
+// Add adds v, which may be negative
+func (g *Gauge) Add(v float64, labels ...string) {
+	g.s.add(v, labels)
+}
+
+// Set sets the gauge to v
+func (g *Gauge) Set(v float64, labels ...string) {
+	g.s.set(v, labels)
+}
+
+// Histogram counts observations in buckets
+type Histogram struct {
+	mu      sync.Mutex
+	name    string
+	help    string
+	buckets []float64
+	values  map[string]*histogramValue
+}
+
+type histogramValue struct {
+	counts []uint64
+	count  uint64
+	sum    float64
+}
+
+// NewHistogram registers a histogram, buckets default to DefaultBuckets
+func NewHistogram(name, help string, buckets []float64) *Histogram {
+	if buckets == nil {
+		buckets = DefaultBuckets
+	}
+	h := &Histogram{name: name, help: help, buckets: buckets, values: map[string]*histogramValue{}}
+	mu.Lock()
+	defer mu.Unlock()
+	metrics = append(metrics, h)
+	return h
+}
+
+// Observe records v
+func (h *Histogram) Observe(v float64, labels ...string) {
+	key := renderLabels(labels)
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	value, ok := h.values[key]
+	if !ok {
+		value = &histogramValue{counts: make([]uint64, len(h.buckets))}
+		h.values[key] = value
+	}
+	for i, bound := range h.buckets {
+		if v <= bound {
+			value.counts[i]++
+		}
+	}
+	value.count++
+	value.sum += v
+}
+
+func (h *Histogram) write(w io.Writer) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s histogram\n", h.name, h.help, h.name)
+	keys := make([]string, 0, len(h.values))
+	for key := range h.values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		value := h.values[key]
+		prefix := key
+		if prefix != "" {
+			prefix += ","
+		}
+		for i, bound := range h.buckets {
+			fmt.Fprintf(w, "%s_bucket{%sle=\"%s\"} %d\n", h.name, prefix, formatValue(bound), value.counts[i])
+		}
+		fmt.Fprintf(w, "%s_bucket{%sle=\"+Inf\"} %d\n", h.name, prefix, value.count)
+		fmt.Fprintf(w, "%s_sum%s %s\n", h.name, braces(key), formatValue(value.sum))
+		fmt.Fprintf(w, "%s_count%s %d\n", h.name, braces(key), value.count)
+	}
+}
+
+// RegisterCollector registers a collector run on every scrape
+func RegisterCollector(c Collector) {
+	mu.Lock()
+	defer mu.Unlock()
+	collectors = append(collectors, c)
+}
+
+// Write writes all metrics in the prometheus text format
+func Write(w io.Writer) {
+	mu.RLock()
+	defer mu.RUnlock()
+	for _, m := range metrics {
+		m.write(w)
+	}
+	for _, c := range collectors {
+		c(w)
+	}
+}
+
+// renderLabels renders name and value pairs as name="value",...
+func renderLabels(labels []string) string {
+	var b strings.Builder
+	for i := 0; i+1 < len(labels); i += 2 {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		b.WriteString(labels[i])
+		b.WriteString(`="`)
+		b.WriteString(escape(labels[i+1]))
+		b.WriteByte('"')
+	}
+	return b.String()
+}
+
+var escaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+func escape(value string) string {
+	return escaper.Replace(value)
+}
+
+func braces(labels string) string {
+	if labels == "" {
+		return ""
+	}
+	return "{" + labels + "}"
+}
+
+func formatValue(v float64) string {
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+func sortedKeys(values map[string]float64) []string {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
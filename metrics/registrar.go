@@ -0,0 +1,64 @@
+// Copyright 2021 Harran Ali <harran.m@gmail.com>. All rights reserved.
+// Use of this source code is governed by MIT-style
+// license that can be found in the LICENSE file.
+
+package metrics
+
+import (
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/gocondor/gocondor/listeners"
+	"github.com/gocondor/gocondor/retention"
+	"github.com/gocondor/gocondor/views"
+)
+
+// RegisterCollectors registers the collectors of the app's metrics
+func RegisterCollectors() {
+	RegisterCollector(listenersCollector)
+	RegisterCollector(retentionCollector)
+	RegisterCollector(viewsCollector)
+	// Register your collectors here
+}
+
+// listenersCollector exposes the counters of the tcp and udp listeners
+func listenersCollector(w io.Writer) {
+	fmt.Fprint(w, "# HELP listener_connections_total Number of connections accepted by the tcp listeners.\n# TYPE listener_connections_total counter\n")
+	for _, s := range listeners.All() {
+		fmt.Fprintf(w, "listener_connections_total{listener=\"%s\"} %d\n", escape(s.Name), s.Connections)
+	}
+	fmt.Fprint(w, "# HELP listener_packets_total Number of packets received by the udp listeners.\n# TYPE listener_packets_total counter\n")
+	for _, s := range listeners.All() {
+		fmt.Fprintf(w, "listener_packets_total{listener=\"%s\"} %d\n", escape(s.Name), s.Packets)
+	}
+	fmt.Fprint(w, "# HELP listener_errors_total Number of errors of the listeners.\n# TYPE listener_errors_total counter\n")
+	for _, s := range listeners.All() {
+		fmt.Fprintf(w, "listener_errors_total{listener=\"%s\"} %d\n", escape(s.Name), s.Errors)
+	}
+}
+
+// retentionCollector exposes the rows pruned by the retention policies
+func retentionCollector(w io.Writer) {
+	fmt.Fprint(w, "# HELP retention_pruned_rows_total Number of rows pruned by the retention policies.\n# TYPE retention_pruned_rows_total counter\n")
+	for name, s := range retention.AllStats() {
+		fmt.Fprintf(w, "retention_pruned_rows_total{policy=\"%s\"} %d\n", escape(name), s.Pruned)
+	}
+}
+
+// viewsCollector exposes how stale the derived tables are
+func viewsCollector(w io.Writer) {
+	statuses := views.Statuses()
+	fmt.Fprint(w, "# HELP view_staleness_seconds Seconds since the view's sources changed without a refresh.\n# TYPE view_staleness_seconds gauge\n")
+	for name, s := range statuses {
+		staleness := 0.0
+		if s.Stale {
+			staleness = time.Since(s.StaleSince).Seconds()
+		}
+		fmt.Fprintf(w, "view_staleness_seconds{view=\"%s\"} %g\n", escape(name), staleness)
+	}
+	fmt.Fprint(w, "# HELP view_refresh_duration_seconds Duration of the view's last refresh.\n# TYPE view_refresh_duration_seconds gauge\n")
+	for name, s := range statuses {
+		fmt.Fprintf(w, "view_refresh_duration_seconds{view=\"%s\"} %g\n", escape(name), s.RefreshDuration.Seconds())
+	}
+}
@@ -0,0 +1,18 @@
+// Copyright 2021 Harran Ali <harran.m@gmail.com>. All rights reserved.
+// Use of this source code is governed by MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import "github.com/gocondor/core/database"
+
+// RegisterCallbacks registers the gorm callbacks the models rely on
+func RegisterCallbacks() {
+	db := database.Resolve()
+
+	registerReadOnlyCallbacks(db)
+	registerVersionCallbacks(db)
+	registerSlugCallbacks(db)
+	registerStateCallbacks(db)
+	registerCDCCallbacks(db)
+}
@@ -0,0 +1,393 @@
+// Copyright 2021 Harran Ali <harran.m@gmail.com>. All rights reserved.
+// Use of this source code is governed by MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"context"
+	"database/sql"
+	"reflect"
+	"sync"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// the operations of a change
+const (
+	OpCreate = "create"
+	OpUpdate = "update"
+	OpDelete = "delete"
+)
+
+// oldValues is where the values read before an update or a delete are kept
+const oldValues = "cdc:old"
+
+// oldRows is where the rows read before a batch update or delete are kept
+const oldRows = "cdc:old_rows"
+
+// cdcBatchSize is the number of rows read at a time around a batch update or delete
+const cdcBatchSize = 500
+
+// cdcMaxOldRows is the number of rows whose old values are kept for a batch update or delete,
+// only the primary keys of the rows past it are kept, so their changes have no Old values
+const cdcMaxOldRows = 10000
+
+// batchRows are the rows matched by a batch update or delete, old holds the values of the first ones
+type batchRows struct {
+	ids []interface{}
+	old []map[string]interface{}
+}
+
+// Change describes a change of a captured record, Old is nil on create and
+// New is nil on delete, values are keyed by column name
+type Change struct {
+	Table string
+	Op    string
+	ID    interface{}
+	Old   map[string]interface{}
+	New   map[string]interface{}
+}
+
+// ChangeHandler receives the changes once their transaction is committed, the changes of a rolled
+// back transaction are dropped. It runs in the goroutine committing so it must be fast, e.g: invalidate
+// a cache key or queue a reindex. The changes of a nested transaction rolled back to its savepoint
+// are still emitted when the outer transaction commits
+type ChangeHandler func(change Change)
+
+// Captured marks the models embedding it for change data capture, e.g:
+//
+//	type Post struct {
+//		gorm.Model
+//		models.Captured
+//		Title string
+//	}
+type Captured struct{}
+
+func (Captured) captureChanges() {}
+
+type changeCaptured interface {
+	captureChanges()
+}
+
+var cdcMu sync.RWMutex
+var changeHandlers []ChangeHandler
+
+// OnChange registers a handler of the captured changes
+func OnChange(handler ChangeHandler) {
+	cdcMu.Lock()
+	defer cdcMu.Unlock()
+	changeHandlers = append(changeHandlers, handler)
+}
+
+// registerCDCCallbacks registers the callbacks capturing the changes, and wraps the connection pool
+// so the changes are held by their transaction until it's committed
+func registerCDCCallbacks(db *gorm.DB) {
+	if _, ok := db.ConnPool.(changePool); !ok {
+		db.ConnPool = changePool{db.ConnPool}
+		db.Statement.ConnPool = db.ConnPool
+	}
+	db.Callback().Create().After("gorm:create").Register("cdc:create", captureCreate)
+	db.Callback().Update().Before("gorm:update").Register("cdc:read_update", readOld)
+	db.Callback().Update().After("gorm:update").Register("cdc:update", captureUpdate)
+	db.Callback().Delete().Before("gorm:delete").Register("cdc:read_delete", readOld)
+	db.Callback().Delete().After("gorm:delete").Register("cdc:delete", captureDelete)
+}
+
+func captureCreate(db *gorm.DB) {
+	if !captured(db) {
+		return
+	}
+	forEachRecord(db, func(rv reflect.Value) {
+		if id := primaryKey(db, rv); id != nil {
+			queue(db, Change{Table: db.Statement.Table, Op: OpCreate, ID: id, New: columns(db, rv)})
+		}
+	})
+}
+
+// readOld reads the stored values of the record before it's updated or deleted,
+// or the rows matched by the statement when it has no record, in batches of cdcBatchSize
+func readOld(db *gorm.DB) {
+	if !captured(db) || db.Statement.ReflectValue.Kind() != reflect.Struct {
+		return
+	}
+	id := primaryKey(db, db.Statement.ReflectValue)
+	if id == nil {
+		if db.Statement.Schema.PrioritizedPrimaryField == nil {
+			return
+		}
+		var rows batchRows
+		records := reflect.New(reflect.SliceOf(db.Statement.Schema.ModelType))
+		err := matched(db).FindInBatches(records.Interface(), cdcBatchSize, func(tx *gorm.DB, batch int) error {
+			for i := 0; i < records.Elem().Len(); i++ {
+				record := records.Elem().Index(i)
+				rows.ids = append(rows.ids, primaryKey(db, record))
+				if len(rows.old) < cdcMaxOldRows {
+					rows.old = append(rows.old, columns(db, record))
+				}
+			}
+			return nil
+		}).Error
+		if err == nil {
+			db.InstanceSet(oldRows, rows)
+		}
+		return
+	}
+	old := map[string]interface{}{}
+	err := db.Session(&gorm.Session{NewDB: true}).Table(db.Statement.Table).
+		Where(db.Statement.Schema.PrioritizedPrimaryField.DBName+" = ?", id).Take(&old).Error
+	if err == nil {
+		db.InstanceSet(oldValues, old)
+	}
+}
+
+func captureUpdate(db *gorm.DB) {
+	if !captured(db) || db.RowsAffected == 0 {
+		return
+	}
+	if rows, ok := db.InstanceGet(oldRows); ok {
+		captureBatch(db, OpUpdate, rows.(batchRows))
+		return
+	}
+	forEachRecord(db, func(rv reflect.Value) {
+		change := Change{Table: db.Statement.Table, Op: OpUpdate, ID: primaryKey(db, rv)}
+		if change.ID == nil {
+			return
+		}
+		if old, ok := db.InstanceGet(oldValues); ok {
+			change.Old = old.(map[string]interface{})
+		}
+		change.New = columns(db, rv)
+		// updates with a map only carry the updated columns
+		if updates, ok := db.Statement.Dest.(map[string]interface{}); ok {
+			change.New = map[string]interface{}{}
+			for column, value := range change.Old {
+				change.New[column] = value
+			}
+			for column, value := range updates {
+				if field := db.Statement.Schema.LookUpField(column); field != nil {
+					column = field.DBName
+				}
+				change.New[column] = value
+			}
+		}
+		queue(db, change)
+	})
+}
+
+func captureDelete(db *gorm.DB) {
+	if !captured(db) || db.RowsAffected == 0 {
+		return
+	}
+	if rows, ok := db.InstanceGet(oldRows); ok {
+		captureBatch(db, OpDelete, rows.(batchRows))
+		return
+	}
+	forEachRecord(db, func(rv reflect.Value) {
+		change := Change{Table: db.Statement.Table, Op: OpDelete, ID: primaryKey(db, rv)}
+		if change.ID == nil {
+			return
+		}
+		if old, ok := db.InstanceGet(oldValues); ok {
+			change.Old = old.(map[string]interface{})
+		}
+		queue(db, change)
+	})
+}
+
+// captureBatch queues a change for every row of a batch update or delete, the rows were read
+// before the statement and the updated ones are read again for their new values
+func captureBatch(db *gorm.DB, op string, rows batchRows) {
+	key := db.Statement.Schema.PrioritizedPrimaryField
+	if key == nil || len(rows.ids) == 0 {
+		return
+	}
+
+	updated := map[interface{}]map[string]interface{}{}
+	if op == OpUpdate {
+		for start := 0; start < len(rows.ids); start += cdcBatchSize {
+			end := start + cdcBatchSize
+			if end > len(rows.ids) {
+				end = len(rows.ids)
+			}
+			records := reflect.New(reflect.SliceOf(db.Statement.Schema.ModelType))
+			db.Session(&gorm.Session{NewDB: true}).Table(db.Statement.Table).
+				Where(key.DBName+" IN ?", rows.ids[start:end]).Find(records.Interface())
+			for i := 0; i < records.Elem().Len(); i++ {
+				record := records.Elem().Index(i)
+				updated[primaryKey(db, record)] = columns(db, record)
+			}
+		}
+	}
+
+	for i, id := range rows.ids {
+		if id == nil {
+			continue
+		}
+		change := Change{Table: db.Statement.Table, Op: op, ID: id}
+		if i < len(rows.old) {
+			change.Old = rows.old[i]
+		}
+		if op == OpUpdate {
+			change.New = updated[id]
+		}
+		queue(db, change)
+	}
+}
+
+// matched returns a query of the rows matched by the conditions of a statement without record
+func matched(db *gorm.DB) *gorm.DB {
+	tx := db.Session(&gorm.Session{NewDB: true}).Model(reflect.New(db.Statement.Schema.ModelType).Interface())
+	if db.Statement.Unscoped {
+		tx = tx.Unscoped()
+	}
+	if where, ok := db.Statement.Clauses["WHERE"]; ok {
+		if conditions, ok := where.Expression.(clause.Where); ok {
+			tx.Statement.AddClause(clause.Where{Exprs: conditions.Exprs})
+		}
+	}
+	return tx
+}
+
+// captured reports whether the statement's model embeds Captured
+func captured(db *gorm.DB) bool {
+	if db.Error != nil || db.Statement.Schema == nil {
+		return false
+	}
+	_, ok := reflect.New(db.Statement.Schema.ModelType).Interface().(changeCaptured)
+	return ok
+}
+
+// forEachRecord calls fn with every record of the statement
+func forEachRecord(db *gorm.DB, fn func(rv reflect.Value)) {
+	rv := db.Statement.ReflectValue
+	switch rv.Kind() {
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < rv.Len(); i++ {
+			fn(reflect.Indirect(rv.Index(i)))
+		}
+	case reflect.Struct:
+		fn(rv)
+	}
+}
+
+// columns returns the values of a record by column name
+func columns(db *gorm.DB, rv reflect.Value) map[string]interface{} {
+	values := map[string]interface{}{}
+	for _, field := range db.Statement.Schema.Fields {
+		if field.DBName == "" {
+			continue
+		}
+		value, _ := field.ValueOf(rv)
+		values[field.DBName] = value
+	}
+	return values
+}
+
+// primaryKey returns the primary key of a record, nil when it isn't set
+func primaryKey(db *gorm.DB, rv reflect.Value) interface{} {
+	field := db.Statement.Schema.PrioritizedPrimaryField
+	if field == nil || rv.Kind() != reflect.Struct {
+		return nil
+	}
+	value, zero := field.ValueOf(rv)
+	if zero {
+		return nil
+	}
+	return value
+}
+
+// queue holds the change until the statement's transaction is committed, the statements
+// running outside of a transaction are committed already
+func queue(db *gorm.DB, change Change) {
+	if tx, ok := db.Statement.ConnPool.(*changeTx); ok {
+		tx.mu.Lock()
+		tx.changes = append(tx.changes, change)
+		tx.mu.Unlock()
+		return
+	}
+	emit(change)
+}
+
+func emit(change Change) {
+	cdcMu.RLock()
+	defer cdcMu.RUnlock()
+	for _, handler := range changeHandlers {
+		handler(change)
+	}
+}
+
+// changePool is the connection pool of the database, the transactions it begins hold their changes
+type changePool struct {
+	gorm.ConnPool
+}
+
+// BeginTx begins a transaction holding its changes
+func (pool changePool) BeginTx(ctx context.Context, opts *sql.TxOptions) (gorm.ConnPool, error) {
+	var conn gorm.ConnPool
+	var err error
+	switch beginner := pool.ConnPool.(type) {
+	case gorm.TxBeginner:
+		conn, err = beginner.BeginTx(ctx, opts)
+	case gorm.ConnPoolBeginner:
+		conn, err = beginner.BeginTx(ctx, opts)
+	default:
+		return nil, gorm.ErrInvalidTransaction
+	}
+	if err != nil {
+		return nil, err
+	}
+	committer, ok := conn.(gorm.TxCommitter)
+	if !ok {
+		return nil, gorm.ErrInvalidTransaction
+	}
+	return &changeTx{ConnPool: conn, committer: committer}, nil
+}
+
+// GetDBConn returns the wrapped *sql.DB, so gorm's DB() still finds it
+func (pool changePool) GetDBConn() (*sql.DB, error) {
+	switch conn := pool.ConnPool.(type) {
+	case *sql.DB:
+		return conn, nil
+	case gorm.GetDBConnector:
+		return conn.GetDBConn()
+	}
+	return nil, gorm.ErrInvalidDB
+}
+
+// changeTx is a transaction holding the changes captured in it
+type changeTx struct {
+	gorm.ConnPool
+	committer gorm.TxCommitter
+
+	mu      sync.Mutex
+	changes []Change
+}
+
+// Commit commits the transaction and emits its changes
+func (tx *changeTx) Commit() error {
+	if err := tx.committer.Commit(); err != nil {
+		tx.take()
+		return err
+	}
+	for _, change := range tx.take() {
+		emit(change)
+	}
+	return nil
+}
+
+// Rollback rolls the transaction back and drops its changes
+func (tx *changeTx) Rollback() error {
+	tx.take()
+	return tx.committer.Rollback()
+}
+
+// take returns the changes held by the transaction and forgets them
+func (tx *changeTx) take() []Change {
+	tx.mu.Lock()
+	defer tx.mu.Unlock()
+	changes := tx.changes
+	tx.changes = nil
+	return changes
+}
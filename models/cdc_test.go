@@ -0,0 +1,125 @@
+// Copyright 2021 Harran Ali <harran.m@gmail.com>. All rights reserved.
+// Use of this source code is governed by MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"errors"
+	"testing"
+
+	"gorm.io/gorm"
+)
+
+type capturedPost struct {
+	ID uint
+	Captured
+	Title string
+}
+
+// recordChanges registers a handler keeping the changes of the test
+func recordChanges(t *testing.T) *[]Change {
+	var changes []Change
+	cdcMu.Lock()
+	previous := changeHandlers
+	changeHandlers = []ChangeHandler{func(change Change) { changes = append(changes, change) }}
+	cdcMu.Unlock()
+	t.Cleanup(func() {
+		cdcMu.Lock()
+		changeHandlers = previous
+		cdcMu.Unlock()
+	})
+	return &changes
+}
+
+func TestCaptureOnCommit(t *testing.T) {
+	db := openTestDB(t, &capturedPost{})
+	registerCDCCallbacks(db)
+	changes := recordChanges(t)
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		post := capturedPost{Title: "draft"}
+		if err := tx.Create(&post).Error; err != nil {
+			return err
+		}
+		if len(*changes) != 0 {
+			t.Errorf("%d changes emitted before the commit, want 0", len(*changes))
+		}
+		return tx.Model(&post).Update("title", "published").Error
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(*changes) != 2 {
+		t.Fatalf("%d changes emitted, want 2", len(*changes))
+	}
+	create, update := (*changes)[0], (*changes)[1]
+	if create.Op != OpCreate || create.ID != uint(1) || create.New["title"] != "draft" {
+		t.Errorf("create change = %+v", create)
+	}
+	if update.Op != OpUpdate || update.Old["title"] != "draft" || update.New["title"] != "published" {
+		t.Errorf("update change = %+v", update)
+	}
+}
+
+func TestCaptureOnRollback(t *testing.T) {
+	db := openTestDB(t, &capturedPost{})
+	registerCDCCallbacks(db)
+	changes := recordChanges(t)
+
+	rollback := errors.New("rollback")
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&capturedPost{Title: "draft"}).Error; err != nil {
+			return err
+		}
+		return rollback
+	})
+	if err != rollback {
+		t.Fatalf("Transaction() = %v, want %v", err, rollback)
+	}
+	if len(*changes) != 0 {
+		t.Errorf("the rolled back transaction emitted %+v, want none", *changes)
+	}
+
+	// outside of a transaction the changes are emitted right away
+	db.Create(&capturedPost{Title: "draft"})
+	if len(*changes) != 1 || (*changes)[0].Op != OpCreate {
+		t.Errorf("changes = %+v, want the create", *changes)
+	}
+}
+
+func TestCaptureBatch(t *testing.T) {
+	db := openTestDB(t, &capturedPost{})
+	registerCDCCallbacks(db)
+	posts := make([]capturedPost, cdcBatchSize+2)
+	for i := range posts {
+		posts[i].Title = "draft"
+	}
+	db.CreateInBatches(posts, 100)
+	changes := recordChanges(t)
+
+	if err := db.Model(&capturedPost{}).Where("id > ?", 1).Update("title", "published").Error; err != nil {
+		t.Fatal(err)
+	}
+	if len(*changes) != cdcBatchSize+1 {
+		t.Fatalf("%d changes emitted, want %d", len(*changes), cdcBatchSize+1)
+	}
+	last := (*changes)[cdcBatchSize]
+	if last.ID != uint(cdcBatchSize+2) || last.Old["title"] != "draft" || last.New["title"] != "published" {
+		t.Errorf("change of the last batch = %+v", last)
+	}
+
+	*changes = nil
+	if err := db.Where("title = ?", "published").Delete(&capturedPost{}).Error; err != nil {
+		t.Fatal(err)
+	}
+	if len(*changes) != cdcBatchSize+1 {
+		t.Fatalf("%d changes emitted, want %d", len(*changes), cdcBatchSize+1)
+	}
+	for _, change := range *changes {
+		if change.Op != OpDelete || change.Old["title"] != "published" {
+			t.Errorf("delete change = %+v", change)
+		}
+	}
+}
@@ -0,0 +1,30 @@
+// Copyright 2021 Harran Ali <harran.m@gmail.com>. All rights reserved.
+// Use of this source code is governed by MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"time"
+
+	"github.com/gocondor/gocondor/id"
+	"gorm.io/gorm"
+)
+
+// IDModel replaces gorm.Model for models with generated string ids (uuid, ulid or snowflake),
+// the id is generated on create by the generator selected with ID_GENERATOR.
+// Models defining their own BeforeCreate hook must call IDModel.BeforeCreate
+type IDModel struct {
+	ID        string `gorm:"primaryKey;size:36" json:"id"`
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	DeletedAt gorm.DeletedAt `gorm:"index"`
+}
+
+// BeforeCreate generates the id of new records
+func (m *IDModel) BeforeCreate(tx *gorm.DB) error {
+	if m.ID == "" {
+		m.ID = id.New()
+	}
+	return nil
+}
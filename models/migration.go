@@ -10,5 +10,5 @@ import "github.com/gocondor/core/database"
 func MigrateDB() {
 	db := database.Resolve()
 	// add your models to be auto migrated here
-	db.AutoMigrate(&User{})
+	db.AutoMigrate(&User{}, &QuotaUsage{}, &Tag{}, &Tagging{}, &Setting{}, &SagaRun{}, &ReportRun{}, &ShortLink{})
 }
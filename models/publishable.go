@@ -0,0 +1,152 @@
+// Copyright 2021 Harran Ali <harran.m@gmail.com>. All rights reserved.
+// Use of this source code is governed by MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// the publish statuses of the publishable records
+const (
+	Draft     = "draft"
+	Scheduled = "scheduled"
+	Published = "published"
+)
+
+// ErrNotPublishable is returned when publishing a record of a model not embedding Publishable
+var ErrNotPublishable = errors.New("the record doesn't embed models.Publishable")
+
+// Publishable adds a draft/publish workflow to the models embedding it, new records are drafts,
+// scheduled records count as published once their publish time has passed, e.g:
+//
+//	type Post struct {
+//		gorm.Model
+//		models.Publishable
+//		Title string
+//	}
+//
+//	db.Scopes(models.OnlyPublished).Find(&posts)
+type Publishable struct {
+	PublishStatus string     `gorm:"size:16;index;not null;default:draft" json:"publishStatus"`
+	PublishAt     *time.Time `gorm:"index" json:"publishAt"`
+	PublishedAt   *time.Time `json:"publishedAt"`
+}
+
+func (p *Publishable) publication() *Publishable {
+	return p
+}
+
+type publishable interface {
+	publication() *Publishable
+}
+
+// IsPublished reports whether the record is visible to everyone
+func (p Publishable) IsPublished() bool {
+	switch p.PublishStatus {
+	case Published:
+		return true
+	case Scheduled:
+		return p.PublishAt != nil && !p.PublishAt.After(time.Now())
+	}
+	return false
+}
+
+// Publish publishes a loaded record now, published records keep their publish time
+func Publish(db *gorm.DB, record interface{}) error {
+	if p, ok := record.(publishable); ok && p.publication().PublishStatus == Published {
+		return nil
+	}
+	now := time.Now()
+	return setPublication(db, record, Publishable{PublishStatus: Published, PublishedAt: &now})
+}
+
+// SchedulePublish publishes a loaded record at the given time, it's published by
+// the publishing scheduler and hidden by OnlyPublished until then
+func SchedulePublish(db *gorm.DB, record interface{}, at time.Time) error {
+	return setPublication(db, record, Publishable{PublishStatus: Scheduled, PublishAt: &at})
+}
+
+// Unpublish turns a loaded record back into a draft
+func Unpublish(db *gorm.DB, record interface{}) error {
+	return setPublication(db, record, Publishable{PublishStatus: Draft})
+}
+
+// OnlyPublished is a query scope keeping the published records, scheduled records
+// are kept once their publish time has passed
+func OnlyPublished(db *gorm.DB) *gorm.DB {
+	return db.Where("publish_status = ? OR (publish_status = ? AND publish_at <= ?)", Published, Scheduled, time.Now())
+}
+
+// OnlyDrafts is a query scope keeping the drafts
+func OnlyDrafts(db *gorm.DB) *gorm.DB {
+	return db.Where("publish_status = ?", Draft)
+}
+
+// OnlyScheduled is a query scope keeping the records waiting for their publish time
+func OnlyScheduled(db *gorm.DB) *gorm.DB {
+	return db.Where("publish_status = ? AND publish_at > ?", Scheduled, time.Now())
+}
+
+// VisibleTo returns OnlyPublished, or a scope keeping every record for the users allowed to preview, e.g:
+//
+//	db.Scopes(models.VisibleTo(middlewares.CanPreview(c))).Find(&posts)
+func VisibleTo(preview bool) func(*gorm.DB) *gorm.DB {
+	if preview {
+		return func(db *gorm.DB) *gorm.DB { return db }
+	}
+	return OnlyPublished
+}
+
+// PublishDue publishes the scheduled records of model whose publish time has passed, it returns
+// the ids of the records it published, each due record is claimed with a conditional update so
+// when the scheduler runs on several instances a record is published, and reported, by only one
+func PublishDue(db *gorm.DB, model interface{}) ([]uint, error) {
+	now := time.Now()
+	var due []uint
+	err := db.Model(model).
+		Where("publish_status = ? AND publish_at <= ?", Scheduled, now).
+		Pluck("id", &due).Error
+	if err != nil {
+		return nil, err
+	}
+
+	var published []uint
+	for _, id := range due {
+		res := db.Model(model).
+			Where("id = ? AND publish_status = ?", id, Scheduled).
+			Updates(map[string]interface{}{"publish_status": Published, "published_at": now})
+		if res.Error != nil {
+			return published, res.Error
+		}
+		// another instance claimed it, or it was unscheduled meanwhile
+		if res.RowsAffected == 1 {
+			published = append(published, id)
+		}
+	}
+	return published, nil
+}
+
+// setPublication saves the publication columns of a record
+func setPublication(db *gorm.DB, record interface{}, publication Publishable) error {
+	p, ok := record.(publishable)
+	if !ok {
+		return ErrNotPublishable
+	}
+	previous := *p.publication()
+	*p.publication() = publication
+
+	err := db.Model(record).Updates(map[string]interface{}{
+		"publish_status": publication.PublishStatus,
+		"publish_at":     publication.PublishAt,
+		"published_at":   publication.PublishedAt,
+	}).Error
+	if err != nil {
+		*p.publication() = previous
+	}
+	return err
+}
@@ -0,0 +1,17 @@
+// Copyright 2021 Harran Ali <harran.m@gmail.com>. All rights reserved.
+// Use of this source code is governed by MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"gorm.io/gorm"
+)
+
+// QuotaUsage counts the requests a client made during a quota period
+type QuotaUsage struct {
+	gorm.Model
+	Client string `gorm:"size:191;uniqueIndex:idx_quota_usage_client_period"`
+	Period string `gorm:"size:32;uniqueIndex:idx_quota_usage_client_period"`
+	Count  int64
+}
@@ -0,0 +1,76 @@
+// Copyright 2021 Harran Ali <harran.m@gmail.com>. All rights reserved.
+// Use of this source code is governed by MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"errors"
+	"os"
+	"strings"
+	"sync/atomic"
+
+	"gorm.io/gorm"
+)
+
+// ErrReadOnly is returned for write queries while the database is in read-only mode
+var ErrReadOnly = errors.New("the database is in read-only mode, write queries are blocked")
+
+// readOnly is 1 while the database is in read-only mode
+var readOnly int32
+
+// readQueries are the statements raw queries may start with in read-only mode
+var readQueries = []string{"SELECT", "WITH", "SHOW", "EXPLAIN", "DESCRIBE", "PRAGMA"}
+
+// SetReadOnly turns the read-only mode on or off, it can be toggled at runtime
+func SetReadOnly(on bool) {
+	var v int32
+	if on {
+		v = 1
+	}
+	atomic.StoreInt32(&readOnly, v)
+}
+
+// ReadOnly reports whether the database is in read-only mode
+func ReadOnly() bool {
+	return atomic.LoadInt32(&readOnly) == 1
+}
+
+// registerReadOnlyCallbacks registers the callbacks blocking writes in read-only mode,
+// the mode starts on if DB_READ_ONLY is true
+func registerReadOnlyCallbacks(db *gorm.DB) {
+	SetReadOnly(os.Getenv("DB_READ_ONLY") == "true")
+
+	db.Callback().Create().Before("gorm:create").Register("readonly:create", blockWrite)
+	db.Callback().Update().Before("gorm:update").Register("readonly:update", blockWrite)
+	db.Callback().Delete().Before("gorm:delete").Register("readonly:delete", blockWrite)
+	db.Callback().Raw().Before("gorm:raw").Register("readonly:raw", blockRawWrite)
+	// the raw queries run with Row and Rows go through the row callbacks, e.g: db.Raw("DELETE ...").Rows()
+	db.Callback().Row().Before("gorm:row").Register("readonly:row", blockRawWrite)
+}
+
+// blockWrite fails the query in read-only mode
+func blockWrite(db *gorm.DB) {
+	if ReadOnly() {
+		db.AddError(ErrReadOnly)
+	}
+}
+
+// blockRawWrite fails raw queries that aren't reads in read-only mode
+func blockRawWrite(db *gorm.DB) {
+	if !ReadOnly() {
+		return
+	}
+
+	// the queries built by gorm for Row and Rows are selects, their sql is built later
+	query := strings.ToUpper(strings.TrimSpace(db.Statement.SQL.String()))
+	if query == "" {
+		return
+	}
+	for _, read := range readQueries {
+		if strings.HasPrefix(query, read) {
+			return
+		}
+	}
+	db.AddError(ErrReadOnly)
+}
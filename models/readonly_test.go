@@ -0,0 +1,70 @@
+// Copyright 2021 Harran Ali <harran.m@gmail.com>. All rights reserved.
+// Use of this source code is governed by MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"testing"
+
+	"gorm.io/gorm"
+)
+
+type readOnlyNote struct {
+	ID   uint
+	Body string
+}
+
+func TestReadOnly(t *testing.T) {
+	db := openTestDB(t, &readOnlyNote{})
+	registerReadOnlyCallbacks(db)
+	db.Create(&readOnlyNote{Body: "kept"})
+	SetReadOnly(true)
+	defer SetReadOnly(false)
+
+	tests := []struct {
+		name  string
+		query func(db *gorm.DB) error
+		err   error
+	}{
+		{"create", func(db *gorm.DB) error { return db.Create(&readOnlyNote{Body: "new"}).Error }, ErrReadOnly},
+		{"update", func(db *gorm.DB) error { return db.Model(&readOnlyNote{ID: 1}).Update("body", "changed").Error }, ErrReadOnly},
+		{"delete", func(db *gorm.DB) error { return db.Delete(&readOnlyNote{ID: 1}).Error }, ErrReadOnly},
+		{"exec", func(db *gorm.DB) error { return db.Exec("DELETE FROM read_only_notes").Error }, ErrReadOnly},
+		{"raw rows", func(db *gorm.DB) error {
+			rows, err := db.Raw("DELETE FROM read_only_notes RETURNING id").Rows()
+			if err == nil {
+				rows.Close()
+			}
+			return err
+		}, ErrReadOnly},
+		{"find", func(db *gorm.DB) error { return db.Find(&[]readOnlyNote{}).Error }, nil},
+		{"select rows", func(db *gorm.DB) error {
+			rows, err := db.Model(&readOnlyNote{}).Rows()
+			if err == nil {
+				rows.Close()
+			}
+			return err
+		}, nil},
+		{"raw select rows", func(db *gorm.DB) error {
+			rows, err := db.Raw("SELECT count(*) FROM read_only_notes").Rows()
+			if err == nil {
+				rows.Close()
+			}
+			return err
+		}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.query(db); err != tt.err {
+				t.Errorf("%s = %v, want %v", tt.name, err, tt.err)
+			}
+		})
+	}
+
+	var count int64
+	db.Model(&readOnlyNote{}).Count(&count)
+	if count != 1 {
+		t.Errorf("%d notes left, want 1", count)
+	}
+}
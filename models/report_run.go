@@ -0,0 +1,22 @@
+// Copyright 2021 Harran Ali <harran.m@gmail.com>. All rights reserved.
+// Use of this source code is governed by MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// ReportRun records a generation of a report
+type ReportRun struct {
+	gorm.Model
+	Report     string `gorm:"size:191;index"`
+	Status     string `gorm:"size:32"`
+	Rows       int
+	File       string
+	Error      string
+	FinishedAt time.Time
+}
@@ -0,0 +1,70 @@
+// Copyright 2021 Harran Ali <harran.m@gmail.com>. All rights reserved.
+// Use of this source code is governed by MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"context"
+	"math/rand"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// transientErrors are fragments of the driver errors worth retrying the transaction on
+var transientErrors = []string{
+	"Error 1213",               // mysql: deadlock found when trying to get lock
+	"Error 1205",               // mysql: lock wait timeout exceeded
+	"database is locked",       // sqlite: SQLITE_BUSY
+	"database table is locked", // sqlite: SQLITE_LOCKED
+	"SQLSTATE 40001",           // postgres: serialization failure
+	"SQLSTATE 40P01",           // postgres: deadlock detected
+}
+
+// maxRetryBackoff caps the wait between two attempts
+const maxRetryBackoff = 500 * time.Millisecond
+
+// IsTransient reports whether err is a deadlock or serialization failure that may succeed on retry
+func IsTransient(err error) bool {
+	if err == nil {
+		return false
+	}
+	message := err.Error()
+	for _, fragment := range transientErrors {
+		if strings.Contains(message, fragment) {
+			return true
+		}
+	}
+	return false
+}
+
+// Transaction runs fn in a transaction and runs it again, up to attempts times, when it fails
+// with a transient error, fn must not have side effects outside the transaction, e.g:
+//
+//	err := models.Transaction(c.Request.Context(), db, 3, func(tx *gorm.DB) error {
+//		return tx.Model(&account).Update("balance", gorm.Expr("balance - ?", amount)).Error
+//	})
+func Transaction(ctx context.Context, db *gorm.DB, attempts int, fn func(tx *gorm.DB) error) error {
+	var err error
+	for i := 0; i < attempts; i++ {
+		if err = db.WithContext(ctx).Transaction(fn); !IsTransient(err) {
+			return err
+		}
+		if i == attempts-1 {
+			break
+		}
+		// exponential backoff with full jitter, capped
+		backoff := time.Duration(10<<uint(i)) * time.Millisecond
+		if backoff > maxRetryBackoff {
+			backoff = maxRetryBackoff
+		}
+		select {
+		case <-time.After(time.Duration(rand.Int63n(int64(backoff)) + 1)):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	return err
+}
@@ -0,0 +1,26 @@
+// Copyright 2021 Harran Ali <harran.m@gmail.com>. All rights reserved.
+// Use of this source code is governed by MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// SagaRun is the persisted state of a running workflow
+type SagaRun struct {
+	gorm.Model
+	Workflow string `gorm:"size:191;index"`
+	Status   string `gorm:"size:32;index"`
+	// Step is the next step to run, or while compensating the number of steps left to compensate
+	Step  int
+	Data  string
+	Error string
+	// Owner is the instance executing the run, it holds it until LeaseUntil and renews
+	// the lease while it runs, the runs of an expired lease are resumed by another instance
+	Owner      string     `gorm:"size:191"`
+	LeaseUntil *time.Time `gorm:"index"`
+}
@@ -0,0 +1,17 @@
+// Copyright 2021 Harran Ali <harran.m@gmail.com>. All rights reserved.
+// Use of this source code is governed by MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"gorm.io/gorm"
+)
+
+// Setting represents a stored setting, app wide settings have UserID 0
+type Setting struct {
+	gorm.Model
+	UserID uint   `gorm:"uniqueIndex:idx_settings_user_key" json:"userId"`
+	Key    string `gorm:"size:191;uniqueIndex:idx_settings_user_key" json:"key"`
+	Value  string `json:"value"`
+}
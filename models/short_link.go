@@ -0,0 +1,21 @@
+// Copyright 2021 Harran Ali <harran.m@gmail.com>. All rights reserved.
+// Use of this source code is governed by MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// ShortLink redirects a short code to a url
+type ShortLink struct {
+	gorm.Model
+	Code          string     `gorm:"size:32;uniqueIndex" json:"code"`
+	URL           string     `gorm:"size:2048" json:"url"`
+	ExpiresAt     *time.Time `json:"expiresAt"`
+	Clicks        int64      `json:"clicks"`
+	LastClickedAt *time.Time `json:"lastClickedAt"`
+}
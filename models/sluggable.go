@@ -0,0 +1,106 @@
+// Copyright 2021 Harran Ali <harran.m@gmail.com>. All rights reserved.
+// Use of this source code is governed by MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// SlugSource is implemented by sluggable models, it returns the text the slug is generated from
+type SlugSource interface {
+	SlugSource() string
+}
+
+// Sluggable adds a unique url friendly slug to the models embedding it,
+// the slug is generated on create from the model's SlugSource, e.g:
+//
+//	type Post struct {
+//		gorm.Model
+//		models.Sluggable
+//		Title string
+//	}
+//
+//	func (p *Post) SlugSource() string { return p.Title }
+type Sluggable struct {
+	Slug string `gorm:"size:191;uniqueIndex" json:"slug"`
+}
+
+// registerSlugCallbacks registers the callback generating slugs
+func registerSlugCallbacks(db *gorm.DB) {
+	db.Callback().Create().Before("gorm:create").Register("sluggable:create", generateSlug)
+}
+
+// generateSlug sets a unique slug on new sluggable records
+func generateSlug(db *gorm.DB) {
+	rv := db.Statement.ReflectValue
+	if db.Error != nil || db.Statement.Schema == nil || rv.Kind() != reflect.Struct || !rv.CanAddr() {
+		return
+	}
+	source, ok := rv.Addr().Interface().(SlugSource)
+	if !ok {
+		return
+	}
+	field := db.Statement.Schema.LookUpField("Slug")
+	if field == nil {
+		return
+	}
+	if _, zero := field.ValueOf(rv); !zero {
+		return
+	}
+
+	slug, err := UniqueSlug(db.Session(&gorm.Session{NewDB: true}).Table(db.Statement.Table), Slugify(source.SlugSource()))
+	if err != nil {
+		db.AddError(err)
+		return
+	}
+	db.AddError(field.Set(rv, slug))
+}
+
+// Slugify turns the text into a lowercase url friendly slug
+func Slugify(text string) string {
+	var b strings.Builder
+	dash := false
+	for _, r := range strings.ToLower(text) {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') {
+			b.WriteRune(r)
+			dash = false
+			continue
+		}
+		if !dash && b.Len() > 0 {
+			b.WriteByte('-')
+			dash = true
+		}
+	}
+
+	return strings.TrimSuffix(b.String(), "-")
+}
+
+// UniqueSlug returns the slug, suffixed with -2, -3, ... if it's already taken in the table of db
+func UniqueSlug(db *gorm.DB, slug string) (string, error) {
+	if slug == "" {
+		slug = "n-a"
+	}
+
+	candidate := slug
+	for i := 2; ; i++ {
+		var count int64
+		if err := db.Unscoped().Where("slug = ?", candidate).Count(&count).Error; err != nil {
+			return "", err
+		}
+		if count == 0 {
+			return candidate, nil
+		}
+		candidate = fmt.Sprintf("%s-%d", slug, i)
+	}
+}
+
+// FindBySlug loads the record with the slug into dest
+func FindBySlug(db *gorm.DB, dest interface{}, slug string) error {
+	return db.Where("slug = ?", slug).First(dest).Error
+}
@@ -0,0 +1,294 @@
+// Copyright 2021 Harran Ali <harran.m@gmail.com>. All rights reserved.
+// Use of this source code is governed by MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+)
+
+// ErrIllegalTransition is matched by the errors returned for refused transitions
+var ErrIllegalTransition = errors.New("illegal state transition")
+
+// ErrStateChanged is returned when the state of the record was changed since it was read
+var ErrStateChanged = errors.New("the state was changed by someone else, reload the record and try again")
+
+// transitioning marks the updates made by Transition, the update callback doesn't check them again
+const transitioning = "statemachine:transition"
+
+var machinesMu sync.RWMutex
+
+// the state machines enforced on the updates of the models, by model type
+var machines = map[reflect.Type][]*StateMachine{}
+
+// TransitionError is returned when a transition isn't allowed or a guard refuses it
+type TransitionError struct {
+	Field  string
+	From   string
+	To     string
+	Reason error
+}
+
+// Error implements the error interface
+func (e *TransitionError) Error() string {
+	if e.Reason != nil {
+		return fmt.Sprintf("%s can't change from %s to %s: %v", e.Field, e.From, e.To, e.Reason)
+	}
+	return fmt.Sprintf("%s can't change from %s to %s", e.Field, e.From, e.To)
+}
+
+// Is makes errors.Is(err, ErrIllegalTransition) match
+func (e *TransitionError) Is(target error) bool {
+	return target == ErrIllegalTransition
+}
+
+// Guard decides whether a record may make a transition, returning an error refuses it
+type Guard func(tx *gorm.DB, record interface{}) error
+
+// TransitionHook runs in the transition's transaction after the state was updated,
+// returning an error rolls the transition back
+type TransitionHook func(tx *gorm.DB, record interface{}, from, to string) error
+
+// StateMachine declares the allowed transitions of a string field of a model, e.g:
+//
+//	var OrderStates = models.NewStateMachine("Status").
+//		Allow("pending", "paid", "cancelled").
+//		Allow("paid", "shipped", "refunded").
+//		Guard("paid", "refunded", notShipped).
+//		OnTransition(notifyCustomer)
+//
+//	err := OrderStates.Transition(db, &order, "paid")
+//
+// the updates of a model made without Transition, e.g: db.Save(&order), are only checked
+// against the transitions and the guards once the machine is enforced on the model with Enforce
+type StateMachine struct {
+	Field       string
+	transitions map[string]map[string]bool
+	guards      map[[2]string][]Guard
+	hooks       []TransitionHook
+}
+
+// NewStateMachine creates a state machine for the given struct field
+func NewStateMachine(field string) *StateMachine {
+	return &StateMachine{
+		Field:       field,
+		transitions: map[string]map[string]bool{},
+		guards:      map[[2]string][]Guard{},
+	}
+}
+
+// Allow allows the transitions from a state to the given states
+func (m *StateMachine) Allow(from string, to ...string) *StateMachine {
+	if m.transitions[from] == nil {
+		m.transitions[from] = map[string]bool{}
+	}
+	for _, state := range to {
+		m.transitions[from][state] = true
+	}
+	return m
+}
+
+// Guard adds a guard to the transition from a state to another
+func (m *StateMachine) Guard(from, to string, guard Guard) *StateMachine {
+	key := [2]string{from, to}
+	m.guards[key] = append(m.guards[key], guard)
+	return m
+}
+
+// OnTransition adds a hook run after every transition
+func (m *StateMachine) OnTransition(hook TransitionHook) *StateMachine {
+	m.hooks = append(m.hooks, hook)
+	return m
+}
+
+// Enforce checks the transitions of the model's updates made without Transition, a Save or an Updates
+// changing the state to one that isn't allowed fails with a *TransitionError, the hooks only run
+// with Transition, e.g:
+//
+//	var OrderStates = models.NewStateMachine("Status").Allow("pending", "paid").Enforce(&Order{})
+func (m *StateMachine) Enforce(model interface{}) *StateMachine {
+	t := reflect.TypeOf(model)
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	machinesMu.Lock()
+	defer machinesMu.Unlock()
+	machines[t] = append(machines[t], m)
+	return m
+}
+
+// Can reports whether the transition from a state to another is declared
+func (m *StateMachine) Can(from, to string) bool {
+	return m.transitions[from][to]
+}
+
+// Next returns the states reachable from a state
+func (m *StateMachine) Next(from string) []string {
+	var states []string
+	for state := range m.transitions[from] {
+		states = append(states, state)
+	}
+	return states
+}
+
+// Transition moves the record to the given state, the guards, the update and the hooks
+// run in one transaction, a refused transition fails with a *TransitionError
+func (m *StateMachine) Transition(db *gorm.DB, record interface{}, to string) error {
+	stmt := &gorm.Statement{DB: db}
+	if err := stmt.Parse(record); err != nil {
+		return err
+	}
+	field := stmt.Schema.LookUpField(m.Field)
+	if field == nil {
+		return fmt.Errorf("%s has no field %s", stmt.Schema.Name, m.Field)
+	}
+	value, _ := field.ValueOf(reflectValue(record))
+	from, ok := value.(string)
+	if !ok {
+		return fmt.Errorf("the state field %s must be a string", m.Field)
+	}
+
+	fieldName := strings.Split(field.Tag.Get("json"), ",")[0]
+	if fieldName == "" || fieldName == "-" {
+		fieldName = field.DBName
+	}
+	if !m.Can(from, to) {
+		return &TransitionError{Field: fieldName, From: from, To: to}
+	}
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		for _, guard := range m.guards[[2]string{from, to}] {
+			if err := guard(tx, record); err != nil {
+				return &TransitionError{Field: fieldName, From: from, To: to, Reason: err}
+			}
+		}
+		// the state is only updated if it's still the one the record was read with
+		update := tx.Model(record).Set(transitioning, true).Where(field.DBName+" = ?", from).Update(field.DBName, to)
+		if update.Error != nil {
+			return update.Error
+		}
+		if update.RowsAffected == 0 {
+			return ErrStateChanged
+		}
+		for _, hook := range m.hooks {
+			if err := hook(tx, record, from, to); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		// the update sets the field, put the state back as the transaction was rolled back
+		field.Set(reflectValue(record), from)
+	}
+	return err
+}
+
+// registerStateCallbacks registers the callback checking the updates of the enforced state machines
+func registerStateCallbacks(db *gorm.DB) {
+	db.Callback().Update().Before("gorm:update").Register("statemachine:check", checkTransitions)
+}
+
+// checkTransitions refuses the updates changing the state of the rows to one the machines
+// of the model don't allow from their current state
+func checkTransitions(db *gorm.DB) {
+	if db.Error != nil || db.Statement.Schema == nil {
+		return
+	}
+	if _, ok := db.Get(transitioning); ok {
+		return
+	}
+	machinesMu.RLock()
+	enforced := machines[db.Statement.Schema.ModelType]
+	machinesMu.RUnlock()
+
+	for _, m := range enforced {
+		field := db.Statement.Schema.LookUpField(m.Field)
+		if field == nil {
+			continue
+		}
+		to, ok := updatedState(db, field)
+		if !ok {
+			continue
+		}
+
+		// read the current states of the updated rows in the statement's transaction
+		query := matched(db)
+		if db.Statement.ReflectValue.Kind() == reflect.Struct {
+			if id := primaryKey(db, db.Statement.ReflectValue); id != nil {
+				query = query.Where(db.Statement.Schema.PrioritizedPrimaryField.DBName+" = ?", id)
+			}
+		}
+		var states []string
+		if err := query.Distinct(field.DBName).Pluck(field.DBName, &states).Error; err != nil {
+			db.AddError(err)
+			return
+		}
+
+		fieldName := strings.Split(field.Tag.Get("json"), ",")[0]
+		if fieldName == "" || fieldName == "-" {
+			fieldName = field.DBName
+		}
+		for _, from := range states {
+			if from == to {
+				continue
+			}
+			if !m.Can(from, to) {
+				db.AddError(&TransitionError{Field: fieldName, From: from, To: to})
+				return
+			}
+			// the guards are given the record, they can't run on the updates without one
+			if db.Statement.ReflectValue.Kind() != reflect.Struct {
+				continue
+			}
+			record := db.Statement.ReflectValue.Addr().Interface()
+			for _, guard := range m.guards[[2]string{from, to}] {
+				if err := guard(db.Session(&gorm.Session{NewDB: true}), record); err != nil {
+					db.AddError(&TransitionError{Field: fieldName, From: from, To: to, Reason: err})
+					return
+				}
+			}
+		}
+	}
+}
+
+// updatedState returns the state the update writes, ok is false when it doesn't write the field
+func updatedState(db *gorm.DB, field *schema.Field) (state string, ok bool) {
+	var value interface{}
+	switch dest := db.Statement.Dest.(type) {
+	case map[string]interface{}:
+		if value, ok = dest[field.Name]; !ok {
+			if value, ok = dest[field.DBName]; !ok {
+				return "", false
+			}
+		}
+	default:
+		rv := reflect.Indirect(reflect.ValueOf(dest))
+		if rv.Kind() != reflect.Struct || rv.Type() != db.Statement.Schema.ModelType {
+			return "", false
+		}
+		selected, restricted := db.Statement.SelectAndOmitColumns(false, true)
+		updated, listed := selected[field.DBName]
+		if (listed && !updated) || (restricted && !listed) {
+			return "", false
+		}
+		var zero bool
+		// the zero values are only written when the columns are selected, e.g: by Save
+		if value, zero = field.ValueOf(rv); zero && !updated {
+			return "", false
+		}
+	}
+	rv := reflect.Indirect(reflect.ValueOf(value))
+	if rv.Kind() != reflect.String {
+		return "", false
+	}
+	return rv.String(), true
+}
@@ -0,0 +1,126 @@
+// Copyright 2021 Harran Ali <harran.m@gmail.com>. All rights reserved.
+// Use of this source code is governed by MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"gorm.io/gorm"
+)
+
+type machineOrder struct {
+	ID      uint
+	Status  string `json:"status"`
+	Shipped bool
+}
+
+// errShipped refuses refunding the shipped orders
+var errShipped = errors.New("the order was shipped")
+
+func newOrderMachine(hooks *[]string) *StateMachine {
+	return NewStateMachine("Status").
+		Allow("pending", "paid", "cancelled").
+		Allow("paid", "shipped", "refunded").
+		Guard("paid", "refunded", func(tx *gorm.DB, record interface{}) error {
+			if record.(*machineOrder).Shipped {
+				return errShipped
+			}
+			return nil
+		}).
+		OnTransition(func(tx *gorm.DB, record interface{}, from, to string) error {
+			*hooks = append(*hooks, from+">"+to)
+			return nil
+		})
+}
+
+func TestTransition(t *testing.T) {
+	db := openTestDB(t, &machineOrder{})
+	var hooks []string
+	machine := newOrderMachine(&hooks)
+
+	order := machineOrder{Status: "pending"}
+	db.Create(&order)
+	if err := machine.Transition(db, &order, "paid"); err != nil {
+		t.Fatalf("Transition(paid) = %v", err)
+	}
+	if order.Status != "paid" || len(hooks) != 1 || hooks[0] != "pending>paid" {
+		t.Errorf("status %q with hooks %v, want paid with [pending>paid]", order.Status, hooks)
+	}
+
+	var err error
+	if err = machine.Transition(db, &order, "pending"); !errors.Is(err, ErrIllegalTransition) {
+		t.Errorf("Transition(pending) = %v, want ErrIllegalTransition", err)
+	}
+	order.Shipped = true
+	err = machine.Transition(db, &order, "refunded")
+	var transitionErr *TransitionError
+	if !errors.As(err, &transitionErr) || transitionErr.Reason != errShipped || order.Status != "paid" {
+		t.Errorf("Transition(refunded) = %v with status %q, want the guard's error with status paid", err, order.Status)
+	}
+
+	// a copy read before the state changed can't move it again
+	stale := machineOrder{ID: order.ID, Status: "pending"}
+	if err := machine.Transition(db, &stale, "cancelled"); err != ErrStateChanged || stale.Status != "pending" {
+		t.Errorf("Transition(cancelled) of the stale copy = %v with status %q, want ErrStateChanged with status pending", err, stale.Status)
+	}
+}
+
+func TestEnforcedUpdates(t *testing.T) {
+	db := openTestDB(t, &machineOrder{})
+	registerStateCallbacks(db)
+	var hooks []string
+	newOrderMachine(&hooks).Enforce(&machineOrder{})
+	defer func() {
+		machinesMu.Lock()
+		delete(machines, reflect.TypeOf(machineOrder{}))
+		machinesMu.Unlock()
+	}()
+
+	db.Create(&machineOrder{Status: "pending"})
+	db.Create(&machineOrder{Status: "paid"})
+
+	tests := []struct {
+		name   string
+		update func(db *gorm.DB) error
+		err    error
+	}{
+		{"save allowed", func(db *gorm.DB) error {
+			return db.Save(&machineOrder{ID: 1, Status: "paid"}).Error
+		}, nil},
+		{"save illegal", func(db *gorm.DB) error {
+			return db.Save(&machineOrder{ID: 1, Status: "pending"}).Error
+		}, ErrIllegalTransition},
+		{"updates illegal", func(db *gorm.DB) error {
+			return db.Model(&machineOrder{ID: 2}).Updates(map[string]interface{}{"status": "cancelled"}).Error
+		}, ErrIllegalTransition},
+		{"batch illegal", func(db *gorm.DB) error {
+			return db.Model(&machineOrder{}).Where("id > ?", 0).Update("status", "cancelled").Error
+		}, ErrIllegalTransition},
+		{"guard refuses", func(db *gorm.DB) error {
+			return db.Save(&machineOrder{ID: 2, Status: "refunded", Shipped: true}).Error
+		}, ErrIllegalTransition},
+		{"other column", func(db *gorm.DB) error {
+			return db.Model(&machineOrder{ID: 2}).Update("shipped", true).Error
+		}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.update(db); !errors.Is(err, tt.err) {
+				t.Errorf("%s = %v, want %v", tt.name, err, tt.err)
+			}
+		})
+	}
+
+	var orders []machineOrder
+	db.Order("id").Find(&orders)
+	if orders[0].Status != "paid" || orders[1].Status != "paid" {
+		t.Errorf("statuses %q and %q, want paid and paid", orders[0].Status, orders[1].Status)
+	}
+	if len(hooks) != 0 {
+		t.Errorf("the hooks ran %v on the plain updates, want none", hooks)
+	}
+}
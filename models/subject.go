@@ -0,0 +1,35 @@
+// Copyright 2021 Harran Ali <harran.m@gmail.com>. All rights reserved.
+// Use of this source code is governed by MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"errors"
+	"reflect"
+
+	"gorm.io/gorm"
+)
+
+// ErrUnsavedRecord is returned by Subject for the records without a saved uint primary key
+var ErrUnsavedRecord = errors.New("the record must be saved and have a uint primary key")
+
+// Subject returns the table and the id identifying a record of any model, the modules
+// attaching rows to the records of other models, e.g: comments, store them as their subject
+func Subject(db *gorm.DB, record interface{}) (table string, id uint, err error) {
+	stmt := &gorm.Statement{DB: db}
+	if err := stmt.Parse(record); err != nil {
+		return "", 0, err
+	}
+
+	field := stmt.Schema.PrioritizedPrimaryField
+	if field == nil {
+		return "", 0, gorm.ErrPrimaryKeyRequired
+	}
+	value, zero := field.ValueOf(reflect.Indirect(reflect.ValueOf(record)))
+	id, ok := value.(uint)
+	if zero || !ok {
+		return "", 0, ErrUnsavedRecord
+	}
+	return stmt.Schema.Table, id, nil
+}
@@ -0,0 +1,136 @@
+// Copyright 2021 Harran Ali <harran.m@gmail.com>. All rights reserved.
+// Use of this source code is governed by MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"errors"
+	"reflect"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Tag represents a label that can be attached to records of any model
+type Tag struct {
+	gorm.Model
+	Name string `gorm:"size:191;uniqueIndex" form:"name" json:"name" binding:"required"`
+}
+
+// Tagging attaches a tag to a record, the record is identified by its table and id
+type Tagging struct {
+	ID           uint   `gorm:"primarykey"`
+	TagID        uint   `gorm:"uniqueIndex:idx_taggings_tag_taggable"`
+	TaggableType string `gorm:"size:64;uniqueIndex:idx_taggings_tag_taggable;index:idx_taggings_taggable"`
+	TaggableID   uint   `gorm:"uniqueIndex:idx_taggings_tag_taggable;index:idx_taggings_taggable"`
+	CreatedAt    time.Time
+}
+
+// AttachTags attaches the tags to the record, creating the missing tags,
+// record is a pointer to a saved model with a uint primary key
+func AttachTags(db *gorm.DB, record interface{}, names ...string) error {
+	taggableType, taggableID, err := taggable(db, record)
+	if err != nil {
+		return err
+	}
+
+	return db.Transaction(func(tx *gorm.DB) error {
+		for _, name := range names {
+			tag := Tag{Name: name}
+			if err := tx.Where("name = ?", name).FirstOrCreate(&tag).Error; err != nil {
+				return err
+			}
+
+			tagging := Tagging{TagID: tag.ID, TaggableType: taggableType, TaggableID: taggableID}
+			if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&tagging).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// DetachTags removes the tags from the record, without names all the tags are removed
+func DetachTags(db *gorm.DB, record interface{}, names ...string) error {
+	taggableType, taggableID, err := taggable(db, record)
+	if err != nil {
+		return err
+	}
+
+	query := db.Where("taggable_type = ? AND taggable_id = ?", taggableType, taggableID)
+	if len(names) > 0 {
+		query = query.Where("tag_id IN (?)", db.Model(&Tag{}).Select("id").Where("name IN ?", names))
+	}
+	return query.Delete(&Tagging{}).Error
+}
+
+// SyncTags makes the given tags the only tags attached to the record
+func SyncTags(db *gorm.DB, record interface{}, names ...string) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := DetachTags(tx, record); err != nil {
+			return err
+		}
+		return AttachTags(tx, record, names...)
+	})
+}
+
+// TagsOf returns the tags attached to the record
+func TagsOf(db *gorm.DB, record interface{}) ([]Tag, error) {
+	taggableType, taggableID, err := taggable(db, record)
+	if err != nil {
+		return nil, err
+	}
+
+	var tags []Tag
+	res := db.Joins("JOIN taggings ON taggings.tag_id = tags.id").
+		Where("taggings.taggable_type = ? AND taggings.taggable_id = ?", taggableType, taggableID).
+		Order("tags.name").
+		Find(&tags)
+	return tags, res.Error
+}
+
+// WithTags is a query scope keeping the records of model tagged with any of the tags, e.g:
+//
+//	db.Scopes(models.WithTags(&Post{}, "go", "web")).Find(&posts)
+func WithTags(model interface{}, names ...string) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		stmt := &gorm.Statement{DB: db}
+		if err := stmt.Parse(model); err != nil {
+			db.AddError(err)
+			return db
+		}
+
+		tagged := db.Session(&gorm.Session{NewDB: true}).Model(&Tagging{}).
+			Select("taggings.taggable_id").
+			Joins("JOIN tags ON tags.id = taggings.tag_id").
+			Where("taggings.taggable_type = ? AND tags.name IN ?", stmt.Schema.Table, names)
+		return db.Where(stmt.Schema.Table+".id IN (?)", tagged)
+	}
+}
+
+// taggable returns the table and the id identifying the record
+func taggable(db *gorm.DB, record interface{}) (string, uint, error) {
+	stmt := &gorm.Statement{DB: db}
+	if err := stmt.Parse(record); err != nil {
+		return "", 0, err
+	}
+
+	field := stmt.Schema.PrioritizedPrimaryField
+	if field == nil {
+		return "", 0, gorm.ErrPrimaryKeyRequired
+	}
+	value, zero := field.ValueOf(reflectValue(record))
+	id, ok := value.(uint)
+	if zero || !ok {
+		return "", 0, errors.New("taggable records must be saved and have a uint primary key")
+	}
+
+	return stmt.Schema.Table, id, nil
+}
+
+// reflectValue returns the struct value the record points to
+func reflectValue(record interface{}) reflect.Value {
+	return reflect.Indirect(reflect.ValueOf(record))
+}
@@ -0,0 +1,114 @@
+// Copyright 2021 Harran Ali <harran.m@gmail.com>. All rights reserved.
+// Use of this source code is governed by MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"errors"
+	"fmt"
+
+	"gorm.io/gorm"
+)
+
+// ErrTreeCycle is returned when moving a node under itself or one of its descendants
+var ErrTreeCycle = errors.New("a node can't be moved under itself or its descendants")
+
+// Tree adds a parent reference to the models embedding it, for hierarchical data
+// like categories or org charts, nodes without a parent are roots
+type Tree struct {
+	ParentID *uint `gorm:"index" json:"parentId"`
+}
+
+// Roots loads the nodes without a parent into dest, a pointer to a slice of the model
+func Roots(db *gorm.DB, dest interface{}) error {
+	return db.Where("parent_id IS NULL").Find(dest).Error
+}
+
+// Children loads the direct children of the node into dest, a pointer to a slice of the model
+func Children(db *gorm.DB, dest interface{}, id uint) error {
+	return db.Where("parent_id = ?", id).Find(dest).Error
+}
+
+// Descendants loads all the nodes under the node into dest, a pointer to a slice of the model
+func Descendants(db *gorm.DB, dest interface{}, id uint) error {
+	table, scope, err := treeTable(db, dest)
+	if err != nil {
+		return err
+	}
+
+	query := fmt.Sprintf(`WITH RECURSIVE tree(id) AS (
+		SELECT id FROM %[1]s WHERE parent_id = ?
+		UNION ALL
+		SELECT %[1]s.id FROM %[1]s JOIN tree ON %[1]s.parent_id = tree.id
+	)
+	SELECT %[1]s.* FROM %[1]s WHERE %[1]s.id IN (SELECT id FROM tree)%[2]s`, table, scope)
+
+	return db.Raw(query, id).Scan(dest).Error
+}
+
+// Ancestors loads the nodes above the node into dest, a pointer to a slice of the model,
+// ordered from the root down to the node's parent
+func Ancestors(db *gorm.DB, dest interface{}, id uint) error {
+	table, scope, err := treeTable(db, dest)
+	if err != nil {
+		return err
+	}
+
+	query := fmt.Sprintf(`WITH RECURSIVE tree(id, parent_id, depth) AS (
+		SELECT id, parent_id, 0 FROM %[1]s WHERE id = ?
+		UNION ALL
+		SELECT %[1]s.id, %[1]s.parent_id, tree.depth + 1 FROM %[1]s JOIN tree ON %[1]s.id = tree.parent_id
+	)
+	SELECT %[1]s.* FROM %[1]s JOIN tree ON %[1]s.id = tree.id WHERE tree.depth > 0%[2]s ORDER BY tree.depth DESC`, table, scope)
+
+	return db.Raw(query, id).Scan(dest).Error
+}
+
+// Move puts the node under a new parent, a nil parent makes the node a root,
+// model is a pointer to the model or a slice of it and only used to find the table
+func Move(db *gorm.DB, model interface{}, id uint, parentID *uint) error {
+	table, _, err := treeTable(db, model)
+	if err != nil {
+		return err
+	}
+
+	return db.Transaction(func(tx *gorm.DB) error {
+		if parentID != nil {
+			if *parentID == id {
+				return ErrTreeCycle
+			}
+
+			// the new parent can't be one of the node's descendants
+			var count int64
+			query := fmt.Sprintf(`WITH RECURSIVE tree(id) AS (
+				SELECT id FROM %[1]s WHERE parent_id = ?
+				UNION ALL
+				SELECT %[1]s.id FROM %[1]s JOIN tree ON %[1]s.parent_id = tree.id
+			)
+			SELECT COUNT(*) FROM tree WHERE id = ?`, table)
+			if err := tx.Raw(query, id, *parentID).Scan(&count).Error; err != nil {
+				return err
+			}
+			if count > 0 {
+				return ErrTreeCycle
+			}
+		}
+
+		return tx.Table(table).Where("id = ?", id).Update("parent_id", parentID).Error
+	})
+}
+
+// treeTable returns the table of the model and the condition excluding soft deleted rows
+func treeTable(db *gorm.DB, model interface{}) (string, string, error) {
+	stmt := &gorm.Statement{DB: db}
+	if err := stmt.Parse(model); err != nil {
+		return "", "", err
+	}
+
+	scope := ""
+	if field := stmt.Schema.LookUpField("DeletedAt"); field != nil && !db.Statement.Unscoped {
+		scope = fmt.Sprintf(" AND %s.%s IS NULL", stmt.Schema.Table, field.DBName)
+	}
+	return stmt.Schema.Table, scope, nil
+}
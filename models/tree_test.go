@@ -0,0 +1,82 @@
+// Copyright 2021 Harran Ali <harran.m@gmail.com>. All rights reserved.
+// Use of this source code is governed by MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"reflect"
+	"testing"
+
+	"gorm.io/gorm"
+)
+
+type treeNode struct {
+	gorm.Model
+	Tree
+	Name string
+}
+
+// names returns the names of the nodes
+func names(nodes []treeNode) []string {
+	got := []string{}
+	for _, node := range nodes {
+		got = append(got, node.Name)
+	}
+	return got
+}
+
+func TestTree(t *testing.T) {
+	db := openTestDB(t, &treeNode{})
+	parent := func(id uint) *uint { return &id }
+	// 1 root, 2 books under root, 3 novels under books, 4 poems under books, 5 music
+	for _, node := range []treeNode{
+		{Name: "root"},
+		{Name: "books", Tree: Tree{ParentID: parent(1)}},
+		{Name: "novels", Tree: Tree{ParentID: parent(2)}},
+		{Name: "poems", Tree: Tree{ParentID: parent(2)}},
+		{Name: "music"},
+	} {
+		db.Create(&node)
+	}
+
+	var nodes []treeNode
+	if err := Roots(db, &nodes); err != nil || !reflect.DeepEqual(names(nodes), []string{"root", "music"}) {
+		t.Errorf("Roots() = %v, %v", names(nodes), err)
+	}
+	nodes = nil
+	if err := Children(db, &nodes, 2); err != nil || !reflect.DeepEqual(names(nodes), []string{"novels", "poems"}) {
+		t.Errorf("Children(2) = %v, %v", names(nodes), err)
+	}
+	nodes = nil
+	if err := Descendants(db, &nodes, 1); err != nil || len(nodes) != 3 {
+		t.Errorf("Descendants(1) = %v, %v", names(nodes), err)
+	}
+	nodes = nil
+	if err := Ancestors(db, &nodes, 3); err != nil || !reflect.DeepEqual(names(nodes), []string{"root", "books"}) {
+		t.Errorf("Ancestors(3) = %v, %v", names(nodes), err)
+	}
+
+	if err := Move(db, &treeNode{}, 1, parent(3)); err != ErrTreeCycle {
+		t.Errorf("Move() under a descendant = %v, want ErrTreeCycle", err)
+	}
+	if err := Move(db, &treeNode{}, 2, parent(2)); err != ErrTreeCycle {
+		t.Errorf("Move() under itself = %v, want ErrTreeCycle", err)
+	}
+	if err := Move(db, &treeNode{}, 2, parent(5)); err != nil {
+		t.Fatal(err)
+	}
+	nodes = nil
+	Ancestors(db, &nodes, 4)
+	if !reflect.DeepEqual(names(nodes), []string{"music", "books"}) {
+		t.Errorf("Ancestors(4) once moved = %v, want [music books]", names(nodes))
+	}
+
+	// the soft deleted nodes are left out
+	db.Delete(&treeNode{}, 4)
+	nodes = nil
+	Descendants(db, &nodes, 5)
+	if !reflect.DeepEqual(names(nodes), []string{"books", "novels"}) {
+		t.Errorf("Descendants(5) = %v, want [books novels]", names(nodes))
+	}
+}
@@ -0,0 +1,126 @@
+// Copyright 2021 Harran Ali <harran.m@gmail.com>. All rights reserved.
+// Use of this source code is governed by MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+	"reflect"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+)
+
+// ErrStaleVersion is returned when updating a versioned record that was modified since it was read
+var ErrStaleVersion = errors.New("the record was modified by someone else, reload it and try again")
+
+// versionChecked is where the version the record was read with is kept once it's checked
+const versionChecked = "versioned:checked"
+
+// Versioned adds optimistic locking to the models embedding it,
+// updating a record only succeeds if its version column still holds the version
+// the record was read with, otherwise the update fails with ErrStaleVersion
+type Versioned struct {
+	Version uint `gorm:"not null;default:1" form:"version" json:"version"`
+}
+
+// registerVersionCallbacks registers the callbacks maintaining the version column
+func registerVersionCallbacks(db *gorm.DB) {
+	db.Callback().Create().Before("gorm:create").Register("versioned:create", initVersion)
+	db.Callback().Update().Before("gorm:update").Register("versioned:check", checkVersion)
+	db.Callback().Update().After("gorm:update").Register("versioned:conflict", detectConflict)
+}
+
+// initVersion sets the version of new records to 1
+func initVersion(db *gorm.DB) {
+	field := versionField(db)
+	if field == nil {
+		return
+	}
+	if _, zero := field.ValueOf(db.Statement.ReflectValue); zero {
+		db.AddError(field.Set(db.Statement.ReflectValue, uint(1)))
+	}
+}
+
+// checkVersion restricts the update to the version the record was read with and bumps it
+func checkVersion(db *gorm.DB) {
+	field := versionField(db)
+	if field == nil {
+		return
+	}
+
+	// the version isn't known when updating without a loaded record
+	value, zero := field.ValueOf(db.Statement.ReflectValue)
+	if zero {
+		return
+	}
+	next, err := nextVersion(value)
+	if err != nil {
+		db.AddError(fmt.Errorf("%s: %w", db.Statement.Schema.Name, err))
+		return
+	}
+
+	db.Statement.AddClause(clause.Where{Exprs: []clause.Expression{
+		clause.Eq{Column: clause.Column{Table: clause.CurrentTable, Name: field.DBName}, Value: value},
+	}})
+	db.Statement.SetColumn(field.Name, next)
+	db.InstanceSet(versionChecked, value)
+}
+
+// nextVersion returns the version after the given one in the same type, the models
+// may declare their own Version field with any integer type
+func nextVersion(version interface{}) (interface{}, error) {
+	current := reflect.ValueOf(version)
+	next := reflect.New(current.Type()).Elem()
+	switch current.Kind() {
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		next.SetUint(current.Uint() + 1)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		next.SetInt(current.Int() + 1)
+	default:
+		return nil, fmt.Errorf("the Version field is a %T, it must be an integer", version)
+	}
+	return next.Interface(), nil
+}
+
+// detectConflict fails the update if no row matched the version, the record is given back
+// the version it was read with when it isn't updated so it can't overwrite the row later
+func detectConflict(db *gorm.DB) {
+	read, ok := db.InstanceGet(versionChecked)
+	if !ok || (db.Error == nil && db.RowsAffected != 0) {
+		return
+	}
+	if field := db.Statement.Schema.LookUpField("Version"); field != nil {
+		field.Set(db.Statement.ReflectValue, read)
+	}
+	if db.Error == nil {
+		db.AddError(ErrStaleVersion)
+	}
+}
+
+// versionField returns the version field of a single versioned record
+func versionField(db *gorm.DB) *schema.Field {
+	if db.Error != nil || db.Statement.Schema == nil || db.Statement.ReflectValue.Kind() != reflect.Struct {
+		return nil
+	}
+	return db.Statement.Schema.LookUpField("Version")
+}
+
+// RetryOnConflict runs fn up to attempts times while it fails with ErrStaleVersion,
+// fn must reload the record it updates on every attempt
+func RetryOnConflict(attempts int, fn func() error) error {
+	var err error
+	for i := 0; i < attempts; i++ {
+		if err = fn(); !errors.Is(err, ErrStaleVersion) {
+			return err
+		}
+		// back off a little so the competing writers don't collide again
+		time.Sleep(time.Duration(rand.Intn(10*(i+1))) * time.Millisecond)
+	}
+	return err
+}
@@ -0,0 +1,111 @@
+// Copyright 2021 Harran Ali <harran.m@gmail.com>. All rights reserved.
+// Use of this source code is governed by MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"errors"
+	"testing"
+)
+
+type versionedDoc struct {
+	ID uint
+	Versioned
+	Body string
+}
+
+type smallVersionDoc struct {
+	ID      uint
+	Version int8
+	Body    string
+}
+
+func TestVersionedUpdate(t *testing.T) {
+	db := openTestDB(t, &versionedDoc{})
+	registerVersionCallbacks(db)
+
+	doc := versionedDoc{Body: "first"}
+	if err := db.Create(&doc).Error; err != nil {
+		t.Fatal(err)
+	}
+	if doc.Version != 1 {
+		t.Errorf("created with version %d, want 1", doc.Version)
+	}
+
+	var stale versionedDoc
+	db.First(&stale, doc.ID)
+
+	doc.Body = "second"
+	if err := db.Save(&doc).Error; err != nil {
+		t.Fatal(err)
+	}
+	if doc.Version != 2 {
+		t.Errorf("saved with version %d, want 2", doc.Version)
+	}
+
+	stale.Body = "lost"
+	if err := db.Save(&stale).Error; !errors.Is(err, ErrStaleVersion) {
+		t.Errorf("Save() of the stale copy = %v, want ErrStaleVersion", err)
+	}
+	if err := db.Model(&stale).Update("body", "lost").Error; !errors.Is(err, ErrStaleVersion) {
+		t.Errorf("Update() of the stale copy = %v, want ErrStaleVersion", err)
+	}
+
+	var stored versionedDoc
+	db.First(&stored, doc.ID)
+	if stored.Body != "second" || stored.Version != 2 {
+		t.Errorf("stored %q at version %d, want \"second\" at version 2", stored.Body, stored.Version)
+	}
+}
+
+func TestRetryOnConflict(t *testing.T) {
+	db := openTestDB(t, &versionedDoc{})
+	registerVersionCallbacks(db)
+	db.Create(&versionedDoc{Body: "first"})
+
+	attempts := 0
+	err := RetryOnConflict(3, func() error {
+		attempts++
+		var doc versionedDoc
+		db.First(&doc)
+		if attempts == 1 {
+			// a competing writer updates the record in between
+			db.Model(&versionedDoc{}).Where("id = ?", doc.ID).Update("version", doc.Version+1)
+		}
+		doc.Body = "retried"
+		return db.Save(&doc).Error
+	})
+	if err != nil || attempts != 2 {
+		t.Errorf("RetryOnConflict() = %v after %d attempts, want nil after 2", err, attempts)
+	}
+}
+
+func TestNextVersion(t *testing.T) {
+	tests := []struct {
+		version interface{}
+		want    interface{}
+	}{
+		{uint(1), uint(2)},
+		{int8(7), int8(8)},
+		{int64(41), int64(42)},
+		{uint32(9), uint32(10)},
+	}
+	for _, tt := range tests {
+		if got, err := nextVersion(tt.version); got != tt.want || err != nil {
+			t.Errorf("nextVersion(%#v) = %#v, %v, want %#v", tt.version, got, err, tt.want)
+		}
+	}
+	if _, err := nextVersion("1"); err == nil {
+		t.Error("nextVersion(\"1\") = nil error, want an error")
+	}
+
+	db := openTestDB(t, &smallVersionDoc{})
+	registerVersionCallbacks(db)
+	doc := smallVersionDoc{Version: 3, Body: "first"}
+	db.Create(&doc)
+	doc.Body = "second"
+	if err := db.Save(&doc).Error; err != nil || doc.Version != 4 {
+		t.Errorf("Save() = %v with version %d, want nil with version 4", err, doc.Version)
+	}
+}
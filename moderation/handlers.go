@@ -0,0 +1,132 @@
+// Copyright 2021 Harran Ali <harran.m@gmail.com>. All rights reserved.
+// Use of this source code is governed by MIT-style
+// license that can be found in the LICENSE file.
+
+package moderation
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gocondor/core/database"
+	"github.com/gocondor/gocondor/http/authentication"
+	"github.com/gocondor/gocondor/http/input"
+	"github.com/gocondor/gocondor/http/response"
+	"github.com/gocondor/gocondor/i18n"
+	"gorm.io/gorm"
+)
+
+// QueueInput filters the queue, it lists the pending flags by default
+type QueueInput struct {
+	input.Page
+	Status string `form:"status" json:"status" binding:"omitempty,oneof=pending approved rejected"`
+}
+
+// DecisionInput is the reason of a decision, rejections must give one
+type DecisionInput struct {
+	Reason string `form:"reason" json:"reason" binding:"max=255"`
+}
+
+// reviewerID returns the id of the moderator making the request, nil when it's not a logged in user
+func reviewerID(c *gin.Context) *uint {
+	id, ok := authentication.UserID(c)
+	if !ok {
+		return nil
+	}
+	return &id
+}
+
+// QueueIndex lists the flags, most reported first
+func QueueIndex(c *gin.Context) {
+	var in QueueInput
+	if err := c.ShouldBindQuery(&in); err != nil {
+		response.AbortWithValidationError(c, err)
+		return
+	}
+	if in.Status == "" {
+		in.Status = Pending
+	}
+
+	var flags []Flag
+	err := database.Resolve().Scopes(in.Page.Scope).
+		Where("status = ?", in.Status).
+		Order("reports DESC, id").
+		Find(&flags).Error
+	if err != nil {
+		response.AbortWithDBError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": flags,
+	})
+}
+
+// FlagShow shows a flag and its audit trail
+func FlagShow(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		response.AbortNotFound(c)
+		return
+	}
+	db := database.Resolve()
+	var flag Flag
+	if err := db.First(&flag, id).Error; err != nil {
+		response.AbortWithDBError(c, err)
+		return
+	}
+	history, err := History(db, flag.ID)
+	if err != nil {
+		response.AbortWithDBError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": gin.H{
+			"flag":    flag,
+			"history": history,
+		},
+	})
+}
+
+// FlagApprove keeps the flagged record
+func FlagApprove(c *gin.Context) {
+	decideFlag(c, Approve, false)
+}
+
+// FlagReject takes the flagged record down, the reason is required
+func FlagReject(c *gin.Context) {
+	decideFlag(c, Reject, true)
+}
+
+func decideFlag(c *gin.Context, decide func(*gorm.DB, uint, *uint, string) (*Decision, error), reasonRequired bool) {
+	var in DecisionInput
+	if err := c.ShouldBind(&in); err != nil {
+		response.AbortWithValidationError(c, err)
+		return
+	}
+	if reasonRequired && in.Reason == "" {
+		message := i18n.T(i18n.Locale(c), "validation.required", map[string]string{"field": "reason"})
+		c.AbortWithStatusJSON(http.StatusUnprocessableEntity, gin.H{
+			"message": message,
+			"errors":  gin.H{"reason": message},
+		})
+		return
+	}
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		response.AbortNotFound(c)
+		return
+	}
+
+	decision, err := decide(database.Resolve(), uint(id), reviewerID(c), in.Reason)
+	if err != nil {
+		response.AbortWithDBError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": decision.Flag,
+	})
+}
@@ -0,0 +1,45 @@
+// Copyright 2021 Harran Ali <harran.m@gmail.com>. All rights reserved.
+// Use of this source code is governed by MIT-style
+// license that can be found in the LICENSE file.
+
+package moderation
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Flag is the moderation case of a record of any model, identified by its table and id,
+// the reports of the same record are counted on one flag
+type Flag struct {
+	gorm.Model
+	FlaggableType  string     `gorm:"size:64;uniqueIndex:idx_moderation_flags_flaggable" json:"flaggableType"`
+	FlaggableID    uint       `gorm:"uniqueIndex:idx_moderation_flags_flaggable" json:"flaggableId"`
+	Status         string     `gorm:"size:16;index" json:"status"`
+	Reports        int        `json:"reports"`
+	Reason         string     `gorm:"size:255" json:"reason"`
+	ReviewerID     *uint      `json:"reviewerId"`
+	DecisionReason string     `gorm:"size:255" json:"decisionReason"`
+	DecidedAt      *time.Time `json:"decidedAt"`
+}
+
+// TableName names the table of the flags
+func (Flag) TableName() string {
+	return "moderation_flags"
+}
+
+// Action is an entry of the audit trail of a flag
+type Action struct {
+	ID        uint      `gorm:"primarykey" json:"id"`
+	FlagID    uint      `gorm:"index" json:"flagId"`
+	Action    string    `gorm:"size:16" json:"action"`
+	ActorID   *uint     `json:"actorId"`
+	Reason    string    `gorm:"size:255" json:"reason"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// TableName names the table of the actions
+func (Action) TableName() string {
+	return "moderation_actions"
+}
@@ -0,0 +1,171 @@
+// Copyright 2021 Harran Ali <harran.m@gmail.com>. All rights reserved.
+// Use of this source code is governed by MIT-style
+// license that can be found in the LICENSE file.
+
+package moderation
+
+import (
+	"time"
+
+	"github.com/gocondor/gocondor/events"
+	"github.com/gocondor/gocondor/models"
+	"gorm.io/gorm"
+)
+
+// the statuses of the flags
+const (
+	Pending  = "pending"
+	Approved = "approved"
+	Rejected = "rejected"
+)
+
+// the actions of the audit trail besides approved and rejected
+const (
+	Flagged    = "flagged"
+	Reopened   = "reopened"
+	Overturned = "overturned"
+)
+
+// States are the allowed moves of a flag, approved records are reopened when flagged again
+// and rejections can be overturned on appeal
+var States = models.NewStateMachine("Status").
+	Allow(Pending, Approved, Rejected).
+	Allow(Approved, Pending).
+	Allow(Rejected, Approved).
+	Enforce(&Flag{})
+
+// Decision is the payload of the moderation.approved and moderation.rejected events
+type Decision struct {
+	Flag   Flag
+	Action Action
+}
+
+// Report flags a saved record to the moderators, reporterID may be nil for system reports,
+// the moderation.flagged event is dispatched with the flag, e.g:
+//
+//	flag, err := moderation.Report(db, &post, &userID, "spam")
+func Report(db *gorm.DB, record interface{}, reporterID *uint, reason string) (*Flag, error) {
+	flaggableType, flaggableID, err := models.Subject(db, record)
+	if err != nil {
+		return nil, err
+	}
+
+	var flag Flag
+	err = db.Transaction(func(tx *gorm.DB) error {
+		res := tx.Where("flaggable_type = ? AND flaggable_id = ?", flaggableType, flaggableID).Limit(1).Find(&flag)
+		if res.Error != nil {
+			return res.Error
+		}
+
+		action := Action{Action: Flagged, ActorID: reporterID, Reason: reason}
+		if res.RowsAffected == 0 {
+			flag = Flag{FlaggableType: flaggableType, FlaggableID: flaggableID, Status: Pending, Reason: reason}
+			if err := tx.Create(&flag).Error; err != nil {
+				return err
+			}
+		} else if flag.Status == Approved {
+			if err := States.Transition(tx, &flag, Pending); err != nil {
+				return err
+			}
+			action.Action = Reopened
+		}
+
+		flag.Reports++
+		if err := tx.Model(&flag).UpdateColumn("reports", flag.Reports).Error; err != nil {
+			return err
+		}
+		action.FlagID = flag.ID
+		return tx.Create(&action).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	events.DispatchAsync("moderation.flagged", flag)
+	return &flag, nil
+}
+
+// Approve keeps the flagged record, the moderation.approved event is dispatched
+func Approve(db *gorm.DB, flagID uint, reviewerID *uint, reason string) (*Decision, error) {
+	return decide(db, flagID, Approved, reviewerID, reason)
+}
+
+// Reject takes the flagged record down, the moderation.rejected event is dispatched
+func Reject(db *gorm.DB, flagID uint, reviewerID *uint, reason string) (*Decision, error) {
+	return decide(db, flagID, Rejected, reviewerID, reason)
+}
+
+// History returns the audit trail of a flag, oldest first
+func History(db *gorm.DB, flagID uint) ([]Action, error) {
+	var actions []Action
+	err := db.Where("flag_id = ?", flagID).Order("id").Find(&actions).Error
+	return actions, err
+}
+
+// FlagOf returns the flag of a record, gorm.ErrRecordNotFound when it was never flagged
+func FlagOf(db *gorm.DB, record interface{}) (*Flag, error) {
+	flaggableType, flaggableID, err := models.Subject(db, record)
+	if err != nil {
+		return nil, err
+	}
+	var flag Flag
+	err = db.Where("flaggable_type = ? AND flaggable_id = ?", flaggableType, flaggableID).First(&flag).Error
+	return &flag, err
+}
+
+// WithoutRejected is a query scope dropping the records of model that were rejected, e.g:
+//
+//	db.Scopes(moderation.WithoutRejected(&Post{})).Find(&posts)
+func WithoutRejected(model interface{}) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		stmt := &gorm.Statement{DB: db}
+		if err := stmt.Parse(model); err != nil {
+			db.AddError(err)
+			return db
+		}
+
+		rejected := db.Session(&gorm.Session{NewDB: true}).Model(&Flag{}).
+			Select("flaggable_id").
+			Where("flaggable_type = ? AND status = ?", stmt.Schema.Table, Rejected)
+		return db.Where(stmt.Schema.Table+".id NOT IN (?)", rejected)
+	}
+}
+
+// decide moves the flag to the decided status and records the decision in the audit trail
+func decide(db *gorm.DB, flagID uint, to string, reviewerID *uint, reason string) (*Decision, error) {
+	var decision Decision
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.First(&decision.Flag, flagID).Error; err != nil {
+			return err
+		}
+		action := to
+		if decision.Flag.Status == Rejected {
+			action = Overturned
+		}
+		if err := States.Transition(tx, &decision.Flag, to); err != nil {
+			return err
+		}
+
+		now := time.Now()
+		decision.Flag.ReviewerID = reviewerID
+		decision.Flag.DecisionReason = reason
+		decision.Flag.DecidedAt = &now
+		err := tx.Model(&decision.Flag).Updates(map[string]interface{}{
+			"reviewer_id":     reviewerID,
+			"decision_reason": reason,
+			"decided_at":      now,
+		}).Error
+		if err != nil {
+			return err
+		}
+
+		decision.Action = Action{FlagID: flagID, Action: action, ActorID: reviewerID, Reason: reason}
+		return tx.Create(&decision.Action).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	events.DispatchAsync("moderation."+to, decision)
+	return &decision, nil
+}
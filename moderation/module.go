@@ -0,0 +1,41 @@
+// Copyright 2021 Harran Ali <harran.m@gmail.com>. All rights reserved.
+// Use of this source code is governed by MIT-style
+// license that can be found in the LICENSE file.
+
+package moderation
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/gocondor/core/routing"
+	"github.com/gocondor/gocondor/commands"
+	"github.com/gocondor/gocondor/http/middlewares"
+)
+
+// Module plugs the moderation queue into the app, register it in modules.RegisterModules
+type Module struct{}
+
+// Name identifies the module
+func (Module) Name() string {
+	return "moderation"
+}
+
+// Routes registers the reviewer endpoints, they require the X-Admin-Token header
+func (Module) Routes() {
+	router := routing.Resolve()
+
+	router.Get("/admin/moderation", middlewares.AdminToken, QueueIndex)
+	router.Get("/admin/moderation/:id", middlewares.AdminToken, FlagShow)
+	router.Post("/admin/moderation/:id/approve", middlewares.AdminToken, FlagApprove)
+	router.Post("/admin/moderation/:id/reject", middlewares.AdminToken, FlagReject)
+}
+
+// Middlewares returns no middlewares
+func (Module) Middlewares() []gin.HandlerFunc { return nil }
+
+// Migrations returns the flags and their audit trail
+func (Module) Migrations() []interface{} {
+	return []interface{}{&Flag{}, &Action{}}
+}
+
+// Commands returns no commands
+func (Module) Commands() map[string]commands.Command { return nil }
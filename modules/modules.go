@@ -0,0 +1,116 @@
+// Copyright 2021 Harran Ali <harran.m@gmail.com>. All rights reserved.
+// Use of this source code is governed by MIT-style
+// license that can be found in the LICENSE file.
+
+package modules
+
+import (
+	"log"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gocondor/core/database"
+	"github.com/gocondor/core/middlewares"
+	"github.com/gocondor/gocondor/commands"
+)
+
+// Module is a reusable feature package (blog, billing, ...) wired into the app at boot,
+// embed Base to only implement the parts the module needs
+type Module interface {
+	// Name identifies the module
+	Name() string
+	// Routes registers the module's routes, using routing.Resolve() like http/routes.go
+	Routes()
+	// Middlewares returns the global middlewares the module needs
+	Middlewares() []gin.HandlerFunc
+	// Migrations returns the models the module auto migrates
+	Migrations() []interface{}
+	// Commands returns the cli commands of the module by name
+	Commands() map[string]commands.Command
+}
+
+// Starter is implemented by the modules running work in the background, e.g: workers
+// draining a queue, they're started once the database is migrated, and not for the cli commands
+type Starter interface {
+	// Start starts the module's background work, it must not block
+	Start()
+}
+
+// Base implements every part of Module but Name as a no-op
+type Base struct{}
+
+// Routes registers no routes
+func (Base) Routes() {}
+
+// Middlewares returns no middlewares
+func (Base) Middlewares() []gin.HandlerFunc { return nil }
+
+// Migrations returns no models
+func (Base) Migrations() []interface{} { return nil }
+
+// Commands returns no commands
+func (Base) Commands() map[string]commands.Command { return nil }
+
+var registered []Module
+
+// Register adds the module to the app, modules usually call it from their init function
+// so importing the module's package is enough to enable it
+func Register(m Module) {
+	for _, existing := range registered {
+		if existing.Name() == m.Name() {
+			log.Fatalf("module \"%s\" is registered twice", m.Name())
+		}
+	}
+	registered = append(registered, m)
+}
+
+// Registered returns the registered modules in registration order
+func Registered() []Module {
+	return registered
+}
+
+// RegisterCommands registers the cli commands of the modules
+func RegisterCommands() {
+	for _, m := range registered {
+		for name, cmd := range m.Commands() {
+			commands.Register(name, cmd)
+		}
+	}
+}
+
+// RegisterMiddlewares attaches the global middlewares of the modules
+func RegisterMiddlewares() {
+	mwUtil := middlewares.Resolve()
+	for _, m := range registered {
+		for _, mw := range m.Middlewares() {
+			mwUtil.Attach(mw)
+		}
+	}
+}
+
+// RegisterRoutes registers the routes of the modules
+func RegisterRoutes() {
+	for _, m := range registered {
+		m.Routes()
+	}
+}
+
+// Migrate auto migrates the models of the modules
+func Migrate() {
+	db := database.Resolve()
+	for _, m := range registered {
+		if migrations := m.Migrations(); len(migrations) > 0 {
+			if err := db.AutoMigrate(migrations...); err != nil {
+				log.Fatalf("failed to migrate module \"%s\": %v", m.Name(), err)
+			}
+		}
+	}
+}
+
+// Start starts the background work of the modules implementing Starter
+func Start() {
+	for _, m := range registered {
+		if starter, ok := m.(Starter); ok {
+			starter.Start()
+		}
+	}
+}
@@ -0,0 +1,24 @@
+// Copyright 2021 Harran Ali <harran.m@gmail.com>. All rights reserved.
+// Use of this source code is governed by MIT-style
+// license that can be found in the LICENSE file.
+
+package modules
+
+// RegisterModules helps you add modules to the app
+func RegisterModules() {
+	// Register your modules here, e.g: Register(blog.Module{})
+	// modules registering themselves in init only need to be imported
+
+	// the bundled modules migrate their tables and mount their routes once registered, e.g:
+	// Register(moderation.Module{})
+	// Register(comments.Module{})
+	// Register(reactions.Module{})
+	// Register(media.Module{})
+	// Register(revisions.Module{})
+
+	// then make your models commentable, reactable, attach files to them and keep their revisions, e.g:
+	// comments.Register("posts", &models.Post{}, comments.Policy{})
+	// reactions.Register("posts", &models.Post{})
+	// media.Register("posts", &models.Post{}, media.Policy{Manage: isAuthor})
+	// revisions.Register("posts", &models.Post{}, revisions.Policy{Keep: 50})
+}
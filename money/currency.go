@@ -0,0 +1,42 @@
+// Copyright 2021 Harran Ali <harran.m@gmail.com>. All rights reserved.
+// Use of this source code is governed by MIT-style
+// license that can be found in the LICENSE file.
+
+package money
+
+import "sync"
+
+// currencies maps ISO 4217 codes to the number of digits of their minor unit
+var currencies = map[string]int{
+	"AED": 2, "ARS": 2, "AUD": 2, "BGN": 2, "BHD": 3, "BRL": 2, "CAD": 2, "CHF": 2,
+	"CLP": 0, "CNY": 2, "COP": 2, "CZK": 2, "DKK": 2, "EGP": 2, "EUR": 2, "GBP": 2,
+	"HKD": 2, "HUF": 2, "IDR": 2, "ILS": 2, "INR": 2, "ISK": 0, "JOD": 3, "JPY": 0,
+	"KRW": 0, "KWD": 3, "MAD": 2, "MXN": 2, "MYR": 2, "NGN": 2, "NOK": 2, "NZD": 2,
+	"OMR": 3, "PHP": 2, "PKR": 2, "PLN": 2, "QAR": 2, "RON": 2, "RUB": 2, "SAR": 2,
+	"SEK": 2, "SGD": 2, "THB": 2, "TND": 3, "TRY": 2, "TWD": 2, "UAH": 2, "USD": 2,
+	"VND": 0, "ZAR": 2,
+}
+
+// currenciesMu guards currencies, RegisterCurrency may run while amounts are parsed
+var currenciesMu sync.RWMutex
+
+// ValidCurrency reports whether the code is a supported ISO 4217 currency code
+func ValidCurrency(code string) bool {
+	_, ok := minorDigits(code)
+	return ok
+}
+
+// RegisterCurrency adds a currency with the number of digits of its minor unit
+func RegisterCurrency(code string, digits int) {
+	currenciesMu.Lock()
+	defer currenciesMu.Unlock()
+	currencies[code] = digits
+}
+
+// minorDigits returns the number of digits of the currency's minor unit
+func minorDigits(code string) (int, bool) {
+	currenciesMu.RLock()
+	defer currenciesMu.RUnlock()
+	digits, ok := currencies[code]
+	return digits, ok
+}
@@ -0,0 +1,260 @@
+// Copyright 2021 Harran Ali <harran.m@gmail.com>. All rights reserved.
+// Use of this source code is governed by MIT-style
+// license that can be found in the LICENSE file.
+
+package money
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"math/bits"
+	"strconv"
+	"strings"
+
+	"github.com/gocondor/gocondor/i18n"
+)
+
+var (
+	// ErrCurrencyMismatch is returned when combining amounts of different currencies
+	ErrCurrencyMismatch = errors.New("money: currencies don't match")
+	// ErrUnknownCurrency is returned for currency codes that aren't supported
+	ErrUnknownCurrency = errors.New("money: unknown currency")
+	// ErrInvalidAmount is returned for amounts that can't be parsed or have too many decimals
+	ErrInvalidAmount = errors.New("money: invalid amount")
+	// ErrOverflow is returned when the result doesn't fit in the minor units of an int64
+	ErrOverflow = errors.New("money: the amount overflows")
+	// ErrNegativeRatio is returned when allocating with a negative ratio
+	ErrNegativeRatio = errors.New("money: the ratios can't be negative")
+)
+
+// Money is an amount stored as an integer number of the currency's minor unit (e.g cents),
+// so it never suffers float rounding. Embed it in models with
+//
+//	Price money.Money `gorm:"embedded;embeddedPrefix:price_"`
+//
+// and it's stored in the price_amount and price_currency columns
+type Money struct {
+	Amount   int64  `gorm:"not null;default:0"`
+	Currency string `gorm:"size:3"`
+}
+
+// New returns the amount in minor units of the currency
+func New(amount int64, currency string) Money {
+	return Money{Amount: amount, Currency: currency}
+}
+
+// Parse parses a decimal amount like "12.34" in the currency
+func Parse(amount string, currency string) (Money, error) {
+	digits, ok := minorDigits(currency)
+	if !ok {
+		return Money{}, ErrUnknownCurrency
+	}
+
+	amount = strings.TrimSpace(amount)
+	sign := ""
+	if strings.HasPrefix(amount, "-") {
+		sign, amount = "-", amount[1:]
+	}
+
+	integer, fraction := amount, ""
+	if i := strings.IndexByte(amount, '.'); i >= 0 {
+		integer, fraction = amount[:i], amount[i+1:]
+	}
+	// only the digits are checked here, strconv would take a second sign, e.g "--5" or "+5"
+	if !isDigits(integer) || (fraction != "" && !isDigits(fraction)) || len(fraction) > digits {
+		return Money{}, ErrInvalidAmount
+	}
+	fraction += strings.Repeat("0", digits-len(fraction))
+
+	// the sign is parsed with the digits so the smallest int64 is still accepted
+	units, err := strconv.ParseInt(sign+integer+fraction, 10, 64)
+	if err != nil {
+		return Money{}, ErrInvalidAmount
+	}
+	return Money{Amount: units, Currency: currency}, nil
+}
+
+// isDigits reports whether s is a non empty run of ascii digits
+func isDigits(s string) bool {
+	if s == "" {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// Add returns m + o
+func (m Money) Add(o Money) (Money, error) {
+	if m.Currency != o.Currency {
+		return Money{}, ErrCurrencyMismatch
+	}
+	sum := m.Amount + o.Amount
+	if (o.Amount > 0 && sum < m.Amount) || (o.Amount < 0 && sum > m.Amount) {
+		return Money{}, ErrOverflow
+	}
+	return Money{Amount: sum, Currency: m.Currency}, nil
+}
+
+// Sub returns m - o
+func (m Money) Sub(o Money) (Money, error) {
+	if m.Currency != o.Currency {
+		return Money{}, ErrCurrencyMismatch
+	}
+	difference := m.Amount - o.Amount
+	if (o.Amount > 0 && difference > m.Amount) || (o.Amount < 0 && difference < m.Amount) {
+		return Money{}, ErrOverflow
+	}
+	return Money{Amount: difference, Currency: m.Currency}, nil
+}
+
+// Mul returns m multiplied by n
+func (m Money) Mul(n int64) (Money, error) {
+	if m.Amount == 0 || n == 0 {
+		return Money{Currency: m.Currency}, nil
+	}
+	product := m.Amount * n
+	if product/n != m.Amount || (m.Amount == -1 && n == math.MinInt64) || (n == -1 && m.Amount == math.MinInt64) {
+		return Money{}, ErrOverflow
+	}
+	return Money{Amount: product, Currency: m.Currency}, nil
+}
+
+// Neg returns -m, the smallest int64 has no opposite
+func (m Money) Neg() (Money, error) {
+	if m.Amount == math.MinInt64 {
+		return Money{}, ErrOverflow
+	}
+	return Money{Amount: -m.Amount, Currency: m.Currency}, nil
+}
+
+// Allocate splits m by the ratios without losing a minor unit, the remainder
+// is spread one unit at a time over the first parts, e.g 10.00 split 1:1:1 is 3.34, 3.33, 3.33
+func (m Money) Allocate(ratios ...int64) ([]Money, error) {
+	var total int64
+	for _, r := range ratios {
+		if r < 0 {
+			return nil, ErrNegativeRatio
+		}
+		if total > math.MaxInt64-r {
+			return nil, ErrOverflow
+		}
+		total += r
+	}
+
+	parts := make([]Money, len(ratios))
+	if total == 0 {
+		for i := range parts {
+			parts[i] = Money{Currency: m.Currency}
+		}
+		return parts, nil
+	}
+
+	// the share is computed on 128 bits, it's never more than the amount so it fits back in an int64
+	amount, negative := uint64(m.Amount), m.Amount < 0
+	if negative {
+		amount = -amount
+	}
+	remainder := m.Amount
+	for i, r := range ratios {
+		hi, lo := bits.Mul64(amount, uint64(r))
+		share, _ := bits.Div64(hi, lo, uint64(total))
+		parts[i] = Money{Amount: int64(share), Currency: m.Currency}
+		if negative {
+			parts[i].Amount = -int64(share)
+		}
+		remainder -= parts[i].Amount
+	}
+	step := int64(1)
+	if remainder < 0 {
+		step = -1
+	}
+	for i := 0; remainder != 0; i++ {
+		parts[i%len(parts)].Amount += step
+		remainder -= step
+	}
+	return parts, nil
+}
+
+// Cmp compares m and o, it returns -1, 0 or 1
+func (m Money) Cmp(o Money) (int, error) {
+	if m.Currency != o.Currency {
+		return 0, ErrCurrencyMismatch
+	}
+	switch {
+	case m.Amount < o.Amount:
+		return -1, nil
+	case m.Amount > o.Amount:
+		return 1, nil
+	}
+	return 0, nil
+}
+
+// IsZero reports whether the amount is zero
+func (m Money) IsZero() bool {
+	return m.Amount == 0
+}
+
+// Decimal returns the amount as a decimal string, e.g "12.34"
+func (m Money) Decimal() string {
+	digits, _ := minorDigits(m.Currency)
+	// the magnitude is unsigned, the smallest int64 has no positive counterpart
+	amount := uint64(m.Amount)
+	sign := ""
+	if m.Amount < 0 {
+		sign, amount = "-", -amount
+	}
+
+	s := strconv.FormatUint(amount, 10)
+	if digits == 0 {
+		return sign + s
+	}
+	if len(s) <= digits {
+		s = strings.Repeat("0", digits-len(s)+1) + s
+	}
+	return sign + s[:len(s)-digits] + "." + s[len(s)-digits:]
+}
+
+// String returns the amount and the currency, e.g "12.34 EUR"
+func (m Money) String() string {
+	return m.Decimal() + " " + m.Currency
+}
+
+// Format returns the amount written the way the locale writes numbers, e.g "1.234,50 EUR" in de
+func (m Money) Format(locale string) string {
+	return i18n.FormatDecimal(locale, m.Decimal()) + " " + m.Currency
+}
+
+// jsonMoney is the json form of money, the amount is a decimal string to keep its precision
+type jsonMoney struct {
+	Amount   json.Number `json:"amount"`
+	Currency string      `json:"currency"`
+}
+
+// MarshalJSON encodes the money as {"amount": "12.34", "currency": "EUR"}
+func (m Money) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		Amount   string `json:"amount"`
+		Currency string `json:"currency"`
+	}{m.Decimal(), m.Currency})
+}
+
+// UnmarshalJSON decodes {"amount": "12.34", "currency": "EUR"}, the amount may be a string or a number
+func (m *Money) UnmarshalJSON(data []byte) error {
+	var v jsonMoney
+	if err := json.Unmarshal(data, &v); err != nil {
+		return fmt.Errorf("money: %v", err)
+	}
+
+	parsed, err := Parse(string(v.Amount), strings.ToUpper(v.Currency))
+	if err != nil {
+		return err
+	}
+	*m = parsed
+	return nil
+}
@@ -0,0 +1,157 @@
+// Copyright 2021 Harran Ali <harran.m@gmail.com>. All rights reserved.
+// Use of this source code is governed by MIT-style
+// license that can be found in the LICENSE file.
+
+package money
+
+import (
+	"math"
+	"reflect"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		amount   string
+		currency string
+		want     Money
+		err      error
+	}{
+		{"12.34", "EUR", New(1234, "EUR"), nil},
+		{"12.3", "EUR", New(1230, "EUR"), nil},
+		{" 12 ", "EUR", New(1200, "EUR"), nil},
+		{"-0.05", "USD", New(-5, "USD"), nil},
+		{"1.234", "KWD", New(1234, "KWD"), nil},
+		{"500", "JPY", New(500, "JPY"), nil},
+		{"-92233720368547758.08", "EUR", New(math.MinInt64, "EUR"), nil},
+		{"92233720368547758.08", "EUR", Money{}, ErrInvalidAmount},
+		{"12.345", "EUR", Money{}, ErrInvalidAmount},
+		{"5.", "EUR", New(500, "EUR"), nil},
+		{".5", "EUR", Money{}, ErrInvalidAmount},
+		{"--5", "EUR", Money{}, ErrInvalidAmount},
+		{"+-5", "EUR", Money{}, ErrInvalidAmount},
+		{"+5", "EUR", Money{}, ErrInvalidAmount},
+		{"1.-5", "EUR", Money{}, ErrInvalidAmount},
+		{"1e3", "EUR", Money{}, ErrInvalidAmount},
+		{"12", "XXX", Money{}, ErrUnknownCurrency},
+	}
+	for _, tt := range tests {
+		t.Run(tt.amount, func(t *testing.T) {
+			got, err := Parse(tt.amount, tt.currency)
+			if got != tt.want || err != tt.err {
+				t.Errorf("Parse(%q, %q) = %v, %v, want %v, %v", tt.amount, tt.currency, got, err, tt.want, tt.err)
+			}
+		})
+	}
+}
+
+func TestDecimal(t *testing.T) {
+	tests := []struct {
+		money Money
+		want  string
+	}{
+		{New(1234, "EUR"), "12.34"},
+		{New(5, "EUR"), "0.05"},
+		{New(-5, "EUR"), "-0.05"},
+		{New(500, "JPY"), "500"},
+		{New(math.MinInt64, "EUR"), "-92233720368547758.08"},
+		{New(math.MaxInt64, "EUR"), "92233720368547758.07"},
+	}
+	for _, tt := range tests {
+		if got := tt.money.Decimal(); got != tt.want {
+			t.Errorf("%#v.Decimal() = %q, want %q", tt.money, got, tt.want)
+		}
+	}
+}
+
+func TestAdd(t *testing.T) {
+	tests := []struct {
+		name string
+		m, o Money
+		want Money
+		err  error
+	}{
+		{"sum", New(150, "EUR"), New(275, "EUR"), New(425, "EUR"), nil},
+		{"negative", New(150, "EUR"), New(-275, "EUR"), New(-125, "EUR"), nil},
+		{"mismatch", New(150, "EUR"), New(275, "USD"), Money{}, ErrCurrencyMismatch},
+		{"overflow", New(math.MaxInt64, "EUR"), New(1, "EUR"), Money{}, ErrOverflow},
+		{"underflow", New(math.MinInt64, "EUR"), New(-1, "EUR"), Money{}, ErrOverflow},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.m.Add(tt.o)
+			if got != tt.want || err != tt.err {
+				t.Errorf("Add() = %v, %v, want %v, %v", got, err, tt.want, tt.err)
+			}
+		})
+	}
+}
+
+func TestMul(t *testing.T) {
+	tests := []struct {
+		name string
+		m    Money
+		n    int64
+		want Money
+		err  error
+	}{
+		{"product", New(150, "EUR"), 3, New(450, "EUR"), nil},
+		{"negative", New(150, "EUR"), -2, New(-300, "EUR"), nil},
+		{"zero", New(math.MaxInt64, "EUR"), 0, New(0, "EUR"), nil},
+		{"overflow", New(math.MaxInt64/2+1, "EUR"), 2, Money{}, ErrOverflow},
+		{"smallest by -1", New(math.MinInt64, "EUR"), -1, Money{}, ErrOverflow},
+		{"-1 by smallest", New(-1, "EUR"), math.MinInt64, Money{}, ErrOverflow},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.m.Mul(tt.n)
+			if got != tt.want || err != tt.err {
+				t.Errorf("Mul(%d) = %v, %v, want %v, %v", tt.n, got, err, tt.want, tt.err)
+			}
+		})
+	}
+}
+
+func TestNeg(t *testing.T) {
+	if got, err := New(150, "EUR").Neg(); got != New(-150, "EUR") || err != nil {
+		t.Errorf("Neg() = %v, %v, want -1.50 EUR, nil", got, err)
+	}
+	if _, err := New(math.MinInt64, "EUR").Neg(); err != ErrOverflow {
+		t.Errorf("Neg() of the smallest int64 = %v, want ErrOverflow", err)
+	}
+}
+
+func TestAllocate(t *testing.T) {
+	tests := []struct {
+		name   string
+		m      Money
+		ratios []int64
+		want   []int64
+		err    error
+	}{
+		{"thirds", New(1000, "EUR"), []int64{1, 1, 1}, []int64{334, 333, 333}, nil},
+		{"negative thirds", New(-1000, "EUR"), []int64{1, 1, 1}, []int64{-334, -333, -333}, nil},
+		{"weighted", New(100, "EUR"), []int64{70, 30}, []int64{70, 30}, nil},
+		{"zero ratio", New(5, "EUR"), []int64{0, 1}, []int64{0, 5}, nil},
+		{"zero total", New(5, "EUR"), []int64{0, 0}, []int64{0, 0}, nil},
+		{"largest", New(math.MaxInt64, "EUR"), []int64{1, 1}, []int64{math.MaxInt64/2 + 1, math.MaxInt64 / 2}, nil},
+		{"smallest", New(math.MinInt64, "EUR"), []int64{1, 1}, []int64{math.MinInt64 / 2, math.MinInt64 / 2}, nil},
+		{"negative ratio", New(100, "EUR"), []int64{1, -1}, nil, ErrNegativeRatio},
+		{"ratios overflow", New(100, "EUR"), []int64{math.MaxInt64, 1}, nil, ErrOverflow},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			parts, err := tt.m.Allocate(tt.ratios...)
+			if err != tt.err {
+				t.Fatalf("Allocate(%v) = %v, want %v", tt.ratios, err, tt.err)
+			}
+			var got []int64
+			for _, part := range parts {
+				got = append(got, part.Amount)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Allocate(%v) = %v, want %v", tt.ratios, got, tt.want)
+			}
+		})
+	}
+}
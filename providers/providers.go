@@ -0,0 +1,34 @@
+// Copyright 2021 Harran Ali <harran.m@gmail.com>. All rights reserved.
+// Use of this source code is governed by MIT-style
+// license that can be found in the LICENSE file.
+
+package providers
+
+// ServiceProvider splits the app's bootstrapping into parts, Register binds the provider's
+// services in the container, Boot runs once every provider registered so it can use
+// the services bound by the others
+type ServiceProvider interface {
+	Register()
+	Boot()
+}
+
+var providers []ServiceProvider
+
+// Add adds a provider, providers register and boot in the order they were added
+func Add(p ServiceProvider) {
+	providers = append(providers, p)
+}
+
+// Register runs the register phase of the providers
+func Register() {
+	for _, p := range providers {
+		p.Register()
+	}
+}
+
+// Boot runs the boot phase of the providers
+func Boot() {
+	for _, p := range providers {
+		p.Boot()
+	}
+}
@@ -0,0 +1,11 @@
+// Copyright 2021 Harran Ali <harran.m@gmail.com>. All rights reserved.
+// Use of this source code is governed by MIT-style
+// license that can be found in the LICENSE file.
+
+package providers
+
+// RegisterProviders adds the app's service providers
+func RegisterProviders() {
+	// Register your service providers here, e.g:
+	// Add(billing.Provider{})
+}
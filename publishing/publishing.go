@@ -0,0 +1,74 @@
+// Copyright 2021 Harran Ali <harran.m@gmail.com>. All rights reserved.
+// Use of this source code is governed by MIT-style
+// license that can be found in the LICENSE file.
+
+package publishing
+
+import (
+	"log"
+	"sync"
+	"time"
+
+	"github.com/gocondor/gocondor/events"
+	"github.com/gocondor/gocondor/models"
+	"gorm.io/gorm"
+)
+
+// Publication is the payload of the <table>.published events
+type Publication struct {
+	Table string
+	ID    uint
+}
+
+var mu sync.RWMutex
+var publishables []interface{}
+
+// Register registers a model embedding models.Publishable, e.g:
+//
+//	publishing.Register(&models.Post{})
+func Register(model interface{}) {
+	mu.Lock()
+	defer mu.Unlock()
+	publishables = append(publishables, model)
+}
+
+// Run publishes the scheduled records whose publish time has passed, a <table>.published
+// event is dispatched for every published record, e.g: posts.published
+func Run(db *gorm.DB) (int, error) {
+	mu.RLock()
+	registered := make([]interface{}, len(publishables))
+	copy(registered, publishables)
+	mu.RUnlock()
+
+	published := 0
+	for _, model := range registered {
+		ids, err := models.PublishDue(db, model)
+		if err != nil {
+			return published, err
+		}
+		published += len(ids)
+
+		stmt := &gorm.Statement{DB: db}
+		if err := stmt.Parse(model); err != nil {
+			return published, err
+		}
+		for _, id := range ids {
+			events.DispatchAsync(stmt.Schema.Table+".published", Publication{Table: stmt.Schema.Table, ID: id})
+		}
+	}
+	return published, nil
+}
+
+// Schedule publishes the due records every interval
+func Schedule(db *gorm.DB, interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for range ticker.C {
+			if _, err := Run(db); err != nil {
+				log.Printf("publishing: scheduled run failed: %v", err)
+			}
+		}
+	}()
+}
@@ -0,0 +1,11 @@
+// Copyright 2021 Harran Ali <harran.m@gmail.com>. All rights reserved.
+// Use of this source code is governed by MIT-style
+// license that can be found in the LICENSE file.
+
+package publishing
+
+// RegisterModels registers the publishable models whose scheduled records are published by Schedule
+func RegisterModels() {
+	// Register your publishable models here, e.g:
+	// Register(&models.Post{})
+}
@@ -0,0 +1,136 @@
+// Copyright 2021 Harran Ali <harran.m@gmail.com>. All rights reserved.
+// Use of this source code is governed by MIT-style
+// license that can be found in the LICENSE file.
+
+package reactions
+
+import (
+	"errors"
+	"net/http"
+	"reflect"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gocondor/core/database"
+	"github.com/gocondor/gocondor/http/authentication"
+	"github.com/gocondor/gocondor/http/input"
+	"github.com/gocondor/gocondor/http/response"
+	"github.com/gocondor/gocondor/i18n"
+)
+
+// ReactionInput is the reaction to set
+type ReactionInput struct {
+	Kind string `form:"kind" json:"kind" binding:"required"`
+}
+
+var resourcesMu sync.RWMutex
+var resources = map[string]reflect.Type{}
+
+// Register makes the records of model reactable under name in the routes, e.g:
+//
+//	reactions.Register("posts", &models.Post{})
+func Register(name string, model interface{}) {
+	resourcesMu.Lock()
+	defer resourcesMu.Unlock()
+	resources[name] = reflect.Indirect(reflect.ValueOf(model)).Type()
+}
+
+// ReactionsShow shows the counts and the score of a record, and the reaction of the user
+func ReactionsShow(c *gin.Context) {
+	record, ok := routeRecord(c)
+	if !ok {
+		return
+	}
+	db := database.Resolve()
+	counts, err := CountsOf(db, record)
+	if err != nil {
+		response.AbortWithDBError(c, err)
+		return
+	}
+
+	mine := ""
+	if userID, authenticated := authentication.UserID(c); authenticated {
+		if mine, err = ReactionOf(db, record, userID); err != nil {
+			response.AbortWithDBError(c, err)
+			return
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": gin.H{
+			"counts":   counts,
+			"score":    Score(counts),
+			"reaction": mine,
+		},
+	})
+}
+
+// ReactionsUpdate sets the reaction of the user to a record
+func ReactionsUpdate(c *gin.Context) {
+	record, ok := routeRecord(c)
+	if !ok {
+		return
+	}
+	userID, authenticated := authentication.UserID(c)
+	if !authenticated {
+		response.AbortForbidden(c)
+		return
+	}
+	var in ReactionInput
+	if err := c.ShouldBind(&in); err != nil {
+		response.AbortWithValidationError(c, err)
+		return
+	}
+
+	err := React(database.Resolve(), record, userID, in.Kind)
+	if errors.Is(err, ErrUnknownKind) {
+		message := i18n.T(i18n.Locale(c), "validation.invalid", map[string]string{"field": "kind"})
+		c.AbortWithStatusJSON(http.StatusUnprocessableEntity, gin.H{
+			"message": message,
+			"errors":  gin.H{"kind": message},
+		})
+		return
+	}
+	if err != nil {
+		response.AbortWithDBError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "reaction saved successfully",
+	})
+}
+
+// ReactionsDestroy removes the reaction of the user to a record
+func ReactionsDestroy(c *gin.Context) {
+	record, ok := routeRecord(c)
+	if !ok {
+		return
+	}
+	userID, authenticated := authentication.UserID(c)
+	if !authenticated {
+		response.AbortForbidden(c)
+		return
+	}
+	if err := Unreact(database.Resolve(), record, userID); err != nil {
+		response.AbortWithDBError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "reaction removed successfully",
+	})
+}
+
+// routeRecord loads the record of the route, the request is aborted when ok is false
+func routeRecord(c *gin.Context) (record interface{}, ok bool) {
+	resourcesMu.RLock()
+	model, registered := resources[c.Param("resource")]
+	resourcesMu.RUnlock()
+	if !registered {
+		response.AbortNotFound(c)
+		return nil, false
+	}
+	record, _, ok = input.RouteRecord(c, database.Resolve(), model)
+	return record, ok
+}
@@ -0,0 +1,47 @@
+// Copyright 2021 Harran Ali <harran.m@gmail.com>. All rights reserved.
+// Use of this source code is governed by MIT-style
+// license that can be found in the LICENSE file.
+
+package reactions
+
+import (
+	"time"
+)
+
+// Reaction is the reaction of a user to a record of any model, identified by its table and id,
+// users have one reaction per record
+type Reaction struct {
+	ID            uint      `gorm:"primarykey" json:"id"`
+	ReactableType string    `gorm:"size:64;uniqueIndex:idx_reactions_user" json:"reactableType"`
+	ReactableID   uint      `gorm:"uniqueIndex:idx_reactions_user" json:"reactableId"`
+	UserID        uint      `gorm:"uniqueIndex:idx_reactions_user" json:"userId"`
+	Kind          string    `gorm:"size:32" json:"kind"`
+	CreatedAt     time.Time `json:"createdAt"`
+	UpdatedAt     time.Time `json:"updatedAt"`
+}
+
+// Count is the number of reactions of a kind to a record, maintained in the background
+type Count struct {
+	ReactableType string `gorm:"primaryKey;size:64" json:"-"`
+	ReactableID   uint   `gorm:"primaryKey" json:"-"`
+	Kind          string `gorm:"primaryKey;size:32" json:"kind"`
+	Count         int64  `json:"count"`
+}
+
+// TableName names the table of the counts
+func (Count) TableName() string {
+	return "reaction_counts"
+}
+
+// PendingRecount queues the recount of a record's counts, it's saved with the reaction
+// so the recount isn't lost when the app stops before the worker ran it
+type PendingRecount struct {
+	ReactableType string    `gorm:"primaryKey;size:64"`
+	ReactableID   uint      `gorm:"primaryKey"`
+	QueuedAt      time.Time `gorm:"index"`
+}
+
+// TableName names the table of the queued recounts
+func (PendingRecount) TableName() string {
+	return "reaction_recounts"
+}
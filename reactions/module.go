@@ -0,0 +1,69 @@
+// Copyright 2021 Harran Ali <harran.m@gmail.com>. All rights reserved.
+// Use of this source code is governed by MIT-style
+// license that can be found in the LICENSE file.
+
+package reactions
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gocondor/core/database"
+	"github.com/gocondor/core/routing"
+	"github.com/gocondor/gocondor/commands"
+)
+
+// Module plugs the reactions into the app, register it in modules.RegisterModules
+// and the reactable models with Register
+type Module struct{}
+
+// Name identifies the module
+func (Module) Name() string {
+	return "reactions"
+}
+
+// Routes registers the reactions endpoints of the registered resources
+func (Module) Routes() {
+	router := routing.Resolve()
+
+	router.Get("/reactions/:resource/:id", ReactionsShow)
+	router.Put("/reactions/:resource/:id", ReactionsUpdate)
+	router.Delete("/reactions/:resource/:id", ReactionsDestroy)
+}
+
+// Middlewares returns no middlewares
+func (Module) Middlewares() []gin.HandlerFunc { return nil }
+
+// Migrations returns the reactions, their counts and the queued recounts
+func (Module) Migrations() []interface{} {
+	return []interface{}{&Reaction{}, &Count{}, &PendingRecount{}}
+}
+
+// Start runs the queued recounts every CountEvery in the background
+func (Module) Start() {
+	db := database.Resolve()
+	go func() {
+		ticker := time.NewTicker(CountEvery)
+		defer ticker.Stop()
+		for range ticker.C {
+			recountPending(db)
+		}
+	}()
+}
+
+// Commands returns the recount command
+func (Module) Commands() map[string]commands.Command {
+	return map[string]commands.Command{
+		"reactions:recount": {
+			Description: "rebuild the reaction counts from the reactions",
+			Run: func(args []string) error {
+				if err := RecountAll(database.Resolve()); err != nil {
+					return err
+				}
+				fmt.Println("reaction counts rebuilt")
+				return nil
+			},
+		},
+	}
+}
@@ -0,0 +1,204 @@
+// Copyright 2021 Harran Ali <harran.m@gmail.com>. All rights reserved.
+// Use of this source code is governed by MIT-style
+// license that can be found in the LICENSE file.
+
+package reactions
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/gocondor/gocondor/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Kinds are the accepted reactions and their weight in the score, e.g: up and down votes
+var Kinds = map[string]int64{
+	"like":  1,
+	"love":  1,
+	"laugh": 1,
+	"up":    1,
+	"down":  -1,
+}
+
+// CountEvery is how often the counts of the records reacted to are brought up to date
+var CountEvery = time.Second
+
+// ErrUnknownKind is returned when reacting with a kind missing from Kinds
+var ErrUnknownKind = errors.New("unknown reaction")
+
+// target identifies a record
+type target struct {
+	Type string
+	ID   uint
+}
+
+// recountBatch is the number of queued recounts run per tick
+const recountBatch = 100
+
+// React sets the reaction of the user to a saved record, replacing their previous one
+func React(db *gorm.DB, record interface{}, userID uint, kind string) error {
+	if _, ok := Kinds[kind]; !ok {
+		return fmt.Errorf("%w: %s", ErrUnknownKind, kind)
+	}
+	t, err := subject(db, record)
+	if err != nil {
+		return err
+	}
+
+	reaction := Reaction{ReactableType: t.Type, ReactableID: t.ID, UserID: userID, Kind: kind}
+	err = db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "reactable_type"}, {Name: "reactable_id"}, {Name: "user_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"kind", "updated_at"}),
+	}).Create(&reaction).Error
+	if err != nil {
+		return err
+	}
+	return schedule(db, t)
+}
+
+// Unreact removes the reaction of the user to the record
+func Unreact(db *gorm.DB, record interface{}, userID uint) error {
+	t, err := subject(db, record)
+	if err != nil {
+		return err
+	}
+	err = db.Where("reactable_type = ? AND reactable_id = ? AND user_id = ?", t.Type, t.ID, userID).
+		Delete(&Reaction{}).Error
+	if err != nil {
+		return err
+	}
+	return schedule(db, t)
+}
+
+// ReactionOf returns the kind of the user's reaction to the record, empty when they didn't react
+func ReactionOf(db *gorm.DB, record interface{}, userID uint) (string, error) {
+	t, err := subject(db, record)
+	if err != nil {
+		return "", err
+	}
+	var kinds []string
+	err = db.Model(&Reaction{}).
+		Where("reactable_type = ? AND reactable_id = ? AND user_id = ?", t.Type, t.ID, userID).
+		Limit(1).Pluck("kind", &kinds).Error
+	if err != nil || len(kinds) == 0 {
+		return "", err
+	}
+	return kinds[0], nil
+}
+
+// CountsOf returns the number of reactions of each kind to the record, they lag the
+// reactions by up to CountEvery
+func CountsOf(db *gorm.DB, record interface{}) (map[string]int64, error) {
+	t, err := subject(db, record)
+	if err != nil {
+		return nil, err
+	}
+	var counts []Count
+	if err := db.Where("reactable_type = ? AND reactable_id = ?", t.Type, t.ID).Find(&counts).Error; err != nil {
+		return nil, err
+	}
+	byKind := make(map[string]int64, len(counts))
+	for _, c := range counts {
+		byKind[c.Kind] = c.Count
+	}
+	return byKind, nil
+}
+
+// Score sums the counts weighted by Kinds, e.g: up votes minus down votes
+func Score(counts map[string]int64) int64 {
+	var score int64
+	for kind, count := range counts {
+		score += Kinds[kind] * count
+	}
+	return score
+}
+
+// MostReacted is a query scope ordering the records of model by their number of reactions
+// of the kind, e.g:
+//
+//	db.Scopes(reactions.MostReacted(&Post{}, "like")).Limit(10).Find(&posts)
+func MostReacted(model interface{}, kind string) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		stmt := &gorm.Statement{DB: db}
+		if err := stmt.Parse(model); err != nil {
+			db.AddError(err)
+			return db
+		}
+		table := stmt.Schema.Table
+		return db.Joins("LEFT JOIN reaction_counts ON reaction_counts.reactable_type = ? AND reaction_counts.reactable_id = "+table+".id AND reaction_counts.kind = ?", table, kind).
+			Order("COALESCE(reaction_counts.count, 0) DESC")
+	}
+}
+
+// Recount rebuilds the counts of a record from its reactions
+func Recount(db *gorm.DB, reactableType string, reactableID uint) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Where("reactable_type = ? AND reactable_id = ?", reactableType, reactableID).Delete(&Count{}).Error
+		if err != nil {
+			return err
+		}
+		var counts []Count
+		err = tx.Model(&Reaction{}).
+			Select("reactable_type, reactable_id, kind, COUNT(*) AS count").
+			Where("reactable_type = ? AND reactable_id = ?", reactableType, reactableID).
+			Group("reactable_type, reactable_id, kind").
+			Scan(&counts).Error
+		if err != nil || len(counts) == 0 {
+			return err
+		}
+		return tx.Create(&counts).Error
+	})
+}
+
+// RecountAll rebuilds the counts of every record, it repairs the counts after the reactions
+// were changed outside of React and Unreact
+func RecountAll(db *gorm.DB) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Session(&gorm.Session{AllowGlobalUpdate: true}).Delete(&Count{}).Error; err != nil {
+			return err
+		}
+		return tx.Exec("INSERT INTO reaction_counts (reactable_type, reactable_id, kind, count) " +
+			"SELECT reactable_type, reactable_id, kind, COUNT(*) FROM reactions GROUP BY reactable_type, reactable_id, kind").Error
+	})
+}
+
+// schedule queues the recount of the record with db, so it's queued in the transaction of
+// the reaction when there's one, the module's worker runs the queued recounts every CountEvery
+func schedule(db *gorm.DB, t target) error {
+	return db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "reactable_type"}, {Name: "reactable_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"queued_at"}),
+	}).Create(&PendingRecount{ReactableType: t.Type, ReactableID: t.ID, QueuedAt: time.Now()}).Error
+}
+
+// recountPending runs the oldest queued recounts, a recount is dequeued only when it succeeded
+// and the record wasn't queued again meanwhile, the failed ones are retried on the next tick
+func recountPending(db *gorm.DB) {
+	var batch []PendingRecount
+	if err := db.Order("queued_at").Limit(recountBatch).Find(&batch).Error; err != nil {
+		log.Printf("reactions: reading the queued recounts: %v", err)
+		return
+	}
+
+	for _, p := range batch {
+		if err := Recount(db, p.ReactableType, p.ReactableID); err != nil {
+			log.Printf("reactions: recount %s %d: %v", p.ReactableType, p.ReactableID, err)
+			continue
+		}
+		err := db.Where("reactable_type = ? AND reactable_id = ? AND queued_at = ?", p.ReactableType, p.ReactableID, p.QueuedAt).
+			Delete(&PendingRecount{}).Error
+		if err != nil {
+			log.Printf("reactions: dequeue recount %s %d: %v", p.ReactableType, p.ReactableID, err)
+		}
+	}
+}
+
+// subject returns the table and the id identifying the record
+func subject(db *gorm.DB, record interface{}) (target, error) {
+	table, id, err := models.Subject(db, record)
+	return target{Type: table, ID: id}, err
+}
@@ -0,0 +1,137 @@
+// Copyright 2021 Harran Ali <harran.m@gmail.com>. All rights reserved.
+// Use of this source code is governed by MIT-style
+// license that can be found in the LICENSE file.
+
+package reports
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"mime"
+	"mime/multipart"
+	"net"
+	"net/smtp"
+	"net/textproto"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Email mails the report's file as an attachment to its Recipients through the MAIL_HOST
+// smtp server, e.g:
+//
+//	Register(Report{Name: "weekly-signups", Every: 7 * 24 * time.Hour, Query: weeklySignups,
+//		Recipients: []string{"growth@example.com"}, Deliver: Email})
+func Email(report Report, file string) error {
+	if len(report.Recipients) == 0 {
+		return fmt.Errorf("report %s: no recipients to mail it to", report.Name)
+	}
+	host := os.Getenv("MAIL_HOST")
+	if host == "" {
+		return errors.New("reports: MAIL_HOST isn't set")
+	}
+	port := os.Getenv("MAIL_PORT")
+	if port == "" {
+		port = "587"
+	}
+	from := os.Getenv("MAIL_FROM")
+
+	message, err := emailMessage(report, file, from)
+	if err != nil {
+		return err
+	}
+	var auth smtp.Auth
+	if username := os.Getenv("MAIL_USERNAME"); username != "" {
+		auth = smtp.PlainAuth("", username, os.Getenv("MAIL_PASSWORD"), host)
+	}
+	return smtp.SendMail(net.JoinHostPort(host, port), auth, from, report.Recipients, message)
+}
+
+// StoreIn copies the report's file to dir, e.g: a mounted bucket or a shared volume
+func StoreIn(dir string) Deliverer {
+	return func(report Report, file string) error {
+		if err := os.MkdirAll(dir, 0750); err != nil {
+			return err
+		}
+		src, err := os.Open(file)
+		if err != nil {
+			return err
+		}
+		defer src.Close()
+		dst, err := os.OpenFile(filepath.Join(dir, filepath.Base(file)), os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0640)
+		if err != nil {
+			return err
+		}
+		if _, err := io.Copy(dst, src); err != nil {
+			dst.Close()
+			return err
+		}
+		return dst.Close()
+	}
+}
+
+// All delivers the report with each deliverer in order, it stops at the first failing, e.g:
+//
+//	Deliver: reports.All(reports.StoreIn("/mnt/reports"), reports.Email)
+func All(deliverers ...Deliverer) Deliverer {
+	return func(report Report, file string) error {
+		for _, deliver := range deliverers {
+			if err := deliver(report, file); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
+// emailMessage builds the mail of the report with the file attached
+func emailMessage(report Report, file string, from string) ([]byte, error) {
+	content, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+
+	var body bytes.Buffer
+	parts := multipart.NewWriter(&body)
+	fmt.Fprintf(&body, "From: %s\r\n", from)
+	fmt.Fprintf(&body, "To: %s\r\n", strings.Join(report.Recipients, ", "))
+	fmt.Fprintf(&body, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", "Report: "+report.Name))
+	fmt.Fprintf(&body, "MIME-Version: 1.0\r\n")
+	fmt.Fprintf(&body, "Content-Type: multipart/mixed; boundary=%s\r\n\r\n", parts.Boundary())
+
+	text, err := parts.CreatePart(textproto.MIMEHeader{"Content-Type": {"text/plain; charset=utf-8"}})
+	if err != nil {
+		return nil, err
+	}
+	fmt.Fprintf(text, "The %s report is attached.\r\n", report.Name)
+
+	name := filepath.Base(file)
+	contentType := mime.TypeByExtension(filepath.Ext(name))
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+	attachment, err := parts.CreatePart(textproto.MIMEHeader{
+		"Content-Type":              {contentType},
+		"Content-Transfer-Encoding": {"base64"},
+		"Content-Disposition":       {mime.FormatMediaType("attachment", map[string]string{"filename": name})},
+	})
+	if err != nil {
+		return nil, err
+	}
+	// base64 in lines of 76 characters as mail requires
+	encoded := base64.StdEncoding.EncodeToString(content)
+	for len(encoded) > 76 {
+		fmt.Fprintf(attachment, "%s\r\n", encoded[:76])
+		encoded = encoded[76:]
+	}
+	fmt.Fprintf(attachment, "%s\r\n", encoded)
+
+	if err := parts.Close(); err != nil {
+		return nil, err
+	}
+	return body.Bytes(), nil
+}
@@ -0,0 +1,62 @@
+// Copyright 2021 Harran Ali <harran.m@gmail.com>. All rights reserved.
+// Use of this source code is governed by MIT-style
+// license that can be found in the LICENSE file.
+
+package reports
+
+import (
+	"encoding/csv"
+	"html/template"
+	"io"
+)
+
+// Renderer writes the table of a report in a format
+type Renderer func(w io.Writer, report Report, table Table) error
+
+// Formats are the renderers of the reports by format, the format is the extension of the
+// generated files, add one to render another format, e.g: a pdf renderer
+//
+//	reports.Formats["pdf"] = renderPDF
+var Formats = map[string]Renderer{
+	"csv":  renderCSV,
+	"html": renderHTML,
+}
+
+// the html rendering of the reports without a Template
+var defaultTemplate = template.Must(template.New("report").Parse(`<!DOCTYPE html>
+<html>
+<head><meta charset="utf-8"><title>{{.Report.Name}}</title></head>
+<body>
+<h1>{{.Report.Name}}</h1>
+<table>
+{{if .Table.Header}}<thead><tr>{{range .Table.Header}}<th>{{.}}</th>{{end}}</tr></thead>{{end}}
+<tbody>
+{{range .Table.Rows}}<tr>{{range .}}<td>{{.}}</td>{{end}}</tr>
+{{end}}</tbody>
+</table>
+</body>
+</html>
+`))
+
+func renderCSV(w io.Writer, report Report, table Table) error {
+	writer := csv.NewWriter(w)
+	if len(table.Header) > 0 {
+		writer.Write(table.Header)
+	}
+	writer.WriteAll(table.Rows)
+	return writer.Error()
+}
+
+func renderHTML(w io.Writer, report Report, table Table) error {
+	tmpl := defaultTemplate
+	if report.Template != "" {
+		var err error
+		if tmpl, err = template.ParseFiles(report.Template); err != nil {
+			return err
+		}
+	}
+	return tmpl.Execute(w, struct {
+		Report Report
+		Table  Table
+	}{report, table})
+}
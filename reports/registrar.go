@@ -0,0 +1,13 @@
+// Copyright 2021 Harran Ali <harran.m@gmail.com>. All rights reserved.
+// Use of this source code is governed by MIT-style
+// license that can be found in the LICENSE file.
+
+package reports
+
+// RegisterReports registers the app's reports
+func RegisterReports() {
+	// Register your reports here, e.g:
+	// Register(Report{Name: "weekly-signups", Every: 7 * 24 * time.Hour, Query: weeklySignups, Deliver: uploadToBucket})
+	// Register(Report{Name: "daily-orders", Every: 24 * time.Hour, Query: dailyOrders, Format: "html",
+	// 	Recipients: []string{"sales@example.com"}, Deliver: All(StoreIn("/mnt/reports"), Email)})
+}
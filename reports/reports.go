@@ -0,0 +1,187 @@
+// Copyright 2021 Harran Ali <harran.m@gmail.com>. All rights reserved.
+// Use of this source code is governed by MIT-style
+// license that can be found in the LICENSE file.
+
+package reports
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/gocondor/gocondor/models"
+	"gorm.io/gorm"
+)
+
+// Table is the data of a report
+type Table struct {
+	Header []string
+	Rows   [][]string
+}
+
+// Deliverer delivers a generated report file, e.g: uploads it or mails it to the recipients
+type Deliverer func(report Report, file string) error
+
+// Report declares a report generated every interval
+type Report struct {
+	Name  string
+	Every time.Duration
+	// Recipients are the addresses Email mails the report to
+	Recipients []string
+	Query      func(db *gorm.DB) (Table, error)
+	// Format names the renderer of Formats writing the file, csv when empty
+	Format string
+	// Template is the html/template file rendering the html format, it gets .Report and .Table,
+	// a plain table is rendered when empty
+	Template string
+	// Deliver is called with the generated file, the file stays in REPORTS_DIR when nil
+	Deliver Deliverer
+}
+
+// ErrUnknownReport is returned when generating a report that isn't registered
+var ErrUnknownReport = errors.New("unknown report")
+
+// CheckEvery is how often Schedule looks for the reports due
+var CheckEvery = time.Minute
+
+var mu sync.RWMutex
+var reports = map[string]Report{}
+
+// Register registers a report
+func Register(r Report) {
+	mu.Lock()
+	defer mu.Unlock()
+	reports[r.Name] = r
+}
+
+// Generate generates a report now, writes it in its format to REPORTS_DIR, delivers it
+// and records the run
+func Generate(db *gorm.DB, name string) (*models.ReportRun, error) {
+	mu.RLock()
+	r, ok := reports[name]
+	mu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownReport, name)
+	}
+
+	run := &models.ReportRun{Report: name, Status: "running"}
+	if err := db.Create(run).Error; err != nil {
+		return nil, err
+	}
+
+	err := generate(db, r, run)
+	run.FinishedAt = time.Now()
+	run.Status = "succeeded"
+	if err != nil {
+		run.Status = "failed"
+		run.Error = err.Error()
+	}
+	if saveErr := db.Save(run).Error; saveErr != nil && err == nil {
+		err = saveErr
+	}
+	return run, err
+}
+
+func generate(db *gorm.DB, r Report, run *models.ReportRun) error {
+	table, err := r.Query(db)
+	if err != nil {
+		return err
+	}
+
+	dir := os.Getenv("REPORTS_DIR")
+	if dir == "" {
+		dir = "storage/reports"
+	}
+	if err := os.MkdirAll(dir, 0750); err != nil {
+		return err
+	}
+	format := r.Format
+	if format == "" {
+		format = "csv"
+	}
+	render, ok := Formats[format]
+	if !ok {
+		return fmt.Errorf("report %s: unknown format %s", r.Name, format)
+	}
+	// the run's id tells apart the files of the runs within the same second
+	run.File = filepath.Join(dir, fmt.Sprintf("%s-%s-%d.%s", r.Name, time.Now().Format("20060102-150405"), run.ID, format))
+	run.Rows = len(table.Rows)
+
+	f, err := os.OpenFile(run.File, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0640)
+	if err != nil {
+		return err
+	}
+	if err := render(f, r, table); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+
+	if r.Deliver != nil {
+		return r.Deliver(r, run.File)
+	}
+	return nil
+}
+
+// Schedule generates the registered reports on their interval, a report is due Every after its
+// last run recorded in the report runs, so the schedule carries over the restarts of the app
+func Schedule(db *gorm.DB) {
+	go func() {
+		ticker := time.NewTicker(CheckEvery)
+		defer ticker.Stop()
+
+		for {
+			runDue(db, time.Now())
+			<-ticker.C
+		}
+	}()
+}
+
+// NextRun returns the time the report is due, Every after its last run, now when it never ran
+func NextRun(db *gorm.DB, r Report, now time.Time) (time.Time, error) {
+	var last models.ReportRun
+	err := db.Where("report = ?", r.Name).Order("created_at DESC").Limit(1).Find(&last).Error
+	if err != nil || last.ID == 0 {
+		return now, err
+	}
+	return last.CreatedAt.Add(r.Every), nil
+}
+
+// runDue generates the reports due at now
+func runDue(db *gorm.DB, now time.Time) {
+	mu.RLock()
+	var scheduled []Report
+	for _, r := range reports {
+		if r.Every > 0 {
+			scheduled = append(scheduled, r)
+		}
+	}
+	mu.RUnlock()
+
+	for _, r := range scheduled {
+		next, err := NextRun(db, r, now)
+		if err != nil {
+			log.Printf("reports: reading the last run of %s: %v", r.Name, err)
+			continue
+		}
+		if next.After(now) {
+			continue
+		}
+		if _, err := Generate(db, r.Name); err != nil {
+			log.Printf("reports: %s failed: %v", r.Name, err)
+		}
+	}
+}
+
+// History returns the latest runs of a report
+func History(db *gorm.DB, name string, limit int) ([]models.ReportRun, error) {
+	var runs []models.ReportRun
+	err := db.Where("report = ?", name).Order("id DESC").Limit(limit).Find(&runs).Error
+	return runs, err
+}
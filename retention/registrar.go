@@ -0,0 +1,11 @@
+// Copyright 2021 Harran Ali <harran.m@gmail.com>. All rights reserved.
+// Use of this source code is governed by MIT-style
+// license that can be found in the LICENSE file.
+
+package retention
+
+// RegisterPolicies registers the app's retention policies
+func RegisterPolicies() {
+	// Register your retention policies here, e.g:
+	// Register(Policy{Table: "users", SoftDeleted: true, OlderThan: 30 * 24 * time.Hour})
+}
@@ -0,0 +1,172 @@
+// Copyright 2021 Harran Ali <harran.m@gmail.com>. All rights reserved.
+// Use of this source code is governed by MIT-style
+// license that can be found in the LICENSE file.
+
+package retention
+
+import (
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Policy prunes the rows of a table older than a duration, e.g:
+//
+//	retention.Register(retention.Policy{Table: "users", SoftDeleted: true, OlderThan: 30 * 24 * time.Hour})
+//	retention.Register(retention.Policy{Table: "quota_usages", Column: "updated_at", OlderThan: 90 * 24 * time.Hour})
+type Policy struct {
+	// Name defaults to the table name
+	Name  string
+	Table string
+	// Column is the timestamp compared to OlderThan, defaults to created_at, or deleted_at for SoftDeleted
+	Column    string
+	OlderThan time.Duration
+	// SoftDeleted only prunes the soft deleted rows
+	SoftDeleted bool
+	// Where narrows the rows, e.g: "status = 'archived'"
+	Where     string
+	BatchSize int
+}
+
+// Result is the outcome of running a policy
+type Result struct {
+	Policy string
+	Rows   int64
+	DryRun bool
+}
+
+// Stats are the counters of a policy
+type Stats struct {
+	Runs      int64
+	Pruned    int64
+	LastRun   time.Time
+	LastError string
+}
+
+// defaultBatchSize is used when a policy has no batch size
+const defaultBatchSize = 1000
+
+var mu sync.Mutex
+var policies []Policy
+var stats = map[string]*Stats{}
+
+// Register registers a retention policy
+func Register(p Policy) {
+	mu.Lock()
+	defer mu.Unlock()
+	if p.Name == "" {
+		p.Name = p.Table
+	}
+	if p.Column == "" {
+		p.Column = "created_at"
+		if p.SoftDeleted {
+			p.Column = "deleted_at"
+		}
+	}
+	if p.BatchSize <= 0 {
+		p.BatchSize = defaultBatchSize
+	}
+	policies = append(policies, p)
+	stats[p.Name] = &Stats{}
+}
+
+// Run runs all policies, with dryRun the matching rows are only counted
+func Run(db *gorm.DB, dryRun bool) ([]Result, error) {
+	mu.Lock()
+	registered := make([]Policy, len(policies))
+	copy(registered, policies)
+	mu.Unlock()
+
+	var results []Result
+	for _, p := range registered {
+		rows, err := run(db, p, dryRun)
+		results = append(results, Result{Policy: p.Name, Rows: rows, DryRun: dryRun})
+		if !dryRun {
+			record(p.Name, rows, err)
+		}
+		if err != nil {
+			return results, fmt.Errorf("retention %s: %w", p.Name, err)
+		}
+	}
+	return results, nil
+}
+
+// run prunes the rows of a policy in batches so the table isn't locked for long
+func run(db *gorm.DB, p Policy, dryRun bool) (int64, error) {
+	scope := func() *gorm.DB {
+		q := db.Table(p.Table).Where(p.Column+" < ?", time.Now().Add(-p.OlderThan))
+		if p.SoftDeleted {
+			q = q.Where(p.Column + " IS NOT NULL")
+		}
+		if p.Where != "" {
+			q = q.Where(p.Where)
+		}
+		return q
+	}
+
+	if dryRun {
+		var count int64
+		err := scope().Count(&count).Error
+		return count, err
+	}
+
+	var total int64
+	for {
+		var ids []uint
+		if err := scope().Limit(p.BatchSize).Pluck("id", &ids).Error; err != nil {
+			return total, err
+		}
+		if len(ids) == 0 {
+			return total, nil
+		}
+		deleted := db.Exec("DELETE FROM "+p.Table+" WHERE id IN ?", ids)
+		if deleted.Error != nil {
+			return total, deleted.Error
+		}
+		total += deleted.RowsAffected
+		if len(ids) < p.BatchSize {
+			return total, nil
+		}
+	}
+}
+
+func record(name string, rows int64, err error) {
+	mu.Lock()
+	defer mu.Unlock()
+	s := stats[name]
+	s.Runs++
+	s.Pruned += rows
+	s.LastRun = time.Now()
+	s.LastError = ""
+	if err != nil {
+		s.LastError = err.Error()
+	}
+}
+
+// AllStats returns the counters of the policies by name
+func AllStats() map[string]Stats {
+	mu.Lock()
+	defer mu.Unlock()
+	all := make(map[string]Stats, len(stats))
+	for name, s := range stats {
+		all[name] = *s
+	}
+	return all
+}
+
+// Schedule runs the policies every interval
+func Schedule(db *gorm.DB, interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for range ticker.C {
+			if _, err := Run(db, false); err != nil {
+				log.Printf("retention: scheduled run failed: %v", err)
+			}
+		}
+	}()
+}
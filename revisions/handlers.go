@@ -0,0 +1,134 @@
+// Copyright 2021 Harran Ali <harran.m@gmail.com>. All rights reserved.
+// Use of this source code is governed by MIT-style
+// license that can be found in the LICENSE file.
+
+package revisions
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gocondor/core/database"
+	"github.com/gocondor/gocondor/http/authentication"
+	"github.com/gocondor/gocondor/http/input"
+	"github.com/gocondor/gocondor/http/response"
+)
+
+// CompareInput picks the revision a revision is compared to, the previous one by default
+type CompareInput struct {
+	Against uint `form:"against" json:"against"`
+}
+
+// RevisionsIndex lists the revisions of a record, latest first
+func RevisionsIndex(c *gin.Context) {
+	record, ok := routeRecord(c)
+	if !ok {
+		return
+	}
+	revisions, err := History(database.Resolve(), record)
+	if err != nil {
+		response.AbortWithDBError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": revisions,
+	})
+}
+
+// RevisionShow shows a revision and the columns that changed since the previous revision,
+// or since the revision given with ?against=
+func RevisionShow(c *gin.Context) {
+	record, ok := routeRecord(c)
+	if !ok {
+		return
+	}
+	number, ok := routeNumber(c)
+	if !ok {
+		return
+	}
+	var in CompareInput
+	if err := c.ShouldBindQuery(&in); err != nil {
+		response.AbortWithValidationError(c, err)
+		return
+	}
+
+	db := database.Resolve()
+	revision, err := RevisionOf(db, record, number)
+	if err != nil {
+		response.AbortWithDBError(c, err)
+		return
+	}
+
+	// the first revision is compared to nothing
+	against := Snapshot{}
+	if in.Against == 0 && number > 1 {
+		in.Against = number - 1
+	}
+	if in.Against != 0 {
+		other, err := RevisionOf(db, record, in.Against)
+		if err != nil {
+			response.AbortWithDBError(c, err)
+			return
+		}
+		against = other.Snapshot
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": gin.H{
+			"revision": revision,
+			"against":  in.Against,
+			"changes":  Diff(against, revision.Snapshot),
+		},
+	})
+}
+
+// RevisionRestore sets a record back to a revision
+func RevisionRestore(c *gin.Context) {
+	record, ok := routeRecord(c)
+	if !ok {
+		return
+	}
+	number, ok := routeNumber(c)
+	if !ok {
+		return
+	}
+
+	db := database.Resolve()
+	if authorID, ok := authentication.UserID(c); ok {
+		db = db.Scopes(By(authorID))
+	}
+	if err := Restore(db, record, number); err != nil {
+		response.AbortWithDBError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": record,
+	})
+}
+
+// routeRecord loads the record of the route, soft deleted records included so they can
+// be restored, the request is aborted when ok is false
+func routeRecord(c *gin.Context) (record interface{}, ok bool) {
+	trackedMu.RLock()
+	t, registered := byName[c.Param("resource")]
+	trackedMu.RUnlock()
+	if !registered {
+		response.AbortNotFound(c)
+		return nil, false
+	}
+	record, _, ok = input.RouteRecord(c, database.Resolve().Unscoped(), t.model)
+	return record, ok
+}
+
+// routeNumber parses the revision number of the route, the request is aborted when ok is false
+func routeNumber(c *gin.Context) (uint, bool) {
+	number, err := strconv.ParseUint(c.Param("number"), 10, 64)
+	if err != nil || number == 0 {
+		response.AbortNotFound(c)
+		return 0, false
+	}
+	return uint(number), true
+}
@@ -0,0 +1,54 @@
+// Copyright 2021 Harran Ali <harran.m@gmail.com>. All rights reserved.
+// Use of this source code is governed by MIT-style
+// license that can be found in the LICENSE file.
+
+package revisions
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+// Revision is a snapshot of a record of any model, identified by its table and id,
+// the revisions of a record are numbered from 1, the numbers are unique per record
+type Revision struct {
+	ID               uint      `gorm:"primarykey" json:"id"`
+	RevisionableType string    `gorm:"size:64;uniqueIndex:idx_revisions_number" json:"revisionableType"`
+	RevisionableID   uint      `gorm:"uniqueIndex:idx_revisions_number" json:"revisionableId"`
+	Number           uint      `gorm:"uniqueIndex:idx_revisions_number" json:"number"`
+	Op               string    `gorm:"size:16" json:"op"`
+	Snapshot         Snapshot  `gorm:"type:text" json:"snapshot"`
+	AuthorID         *uint     `json:"authorId"`
+	CreatedAt        time.Time `gorm:"index" json:"createdAt"`
+}
+
+// Snapshot maps the columns of a record to their values
+type Snapshot map[string]interface{}
+
+// Value stores the snapshot as json
+func (s Snapshot) Value() (driver.Value, error) {
+	if len(s) == 0 {
+		return "{}", nil
+	}
+	data, err := json.Marshal(s)
+	return string(data), err
+}
+
+// Scan reads the snapshot stored as json
+func (s *Snapshot) Scan(value interface{}) error {
+	var data []byte
+	switch value := value.(type) {
+	case nil:
+		*s = Snapshot{}
+		return nil
+	case string:
+		data = []byte(value)
+	case []byte:
+		data = value
+	default:
+		return errors.New("snapshots must be stored as text")
+	}
+	return json.Unmarshal(data, s)
+}
@@ -0,0 +1,58 @@
+// Copyright 2021 Harran Ali <harran.m@gmail.com>. All rights reserved.
+// Use of this source code is governed by MIT-style
+// license that can be found in the LICENSE file.
+
+package revisions
+
+import (
+	"fmt"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gocondor/core/database"
+	"github.com/gocondor/core/routing"
+	"github.com/gocondor/gocondor/commands"
+	"github.com/gocondor/gocondor/http/middlewares"
+)
+
+// Module plugs the revisions into the app, register it in modules.RegisterModules
+// and the revisioned models with Register
+type Module struct{}
+
+// Name identifies the module
+func (Module) Name() string {
+	return "revisions"
+}
+
+// Routes registers the endpoints browsing and restoring the revisions, they require the X-Admin-Token header
+func (Module) Routes() {
+	router := routing.Resolve()
+
+	router.Get("/admin/revisions/:resource/:id", middlewares.AdminToken, RevisionsIndex)
+	router.Get("/admin/revisions/:resource/:id/:number", middlewares.AdminToken, RevisionShow)
+	router.Post("/admin/revisions/:resource/:id/:number/restore", middlewares.AdminToken, RevisionRestore)
+}
+
+// Middlewares returns no middlewares
+func (Module) Middlewares() []gin.HandlerFunc { return nil }
+
+// Migrations returns the revisions
+func (Module) Migrations() []interface{} {
+	return []interface{}{&Revision{}}
+}
+
+// Commands returns the prune command
+func (Module) Commands() map[string]commands.Command {
+	return map[string]commands.Command{
+		"revisions:prune": {
+			Description: "drop the revisions over the Keep of their models",
+			Run: func(args []string) error {
+				rows, err := Prune(database.Resolve())
+				if err != nil {
+					return err
+				}
+				fmt.Printf("%d revisions pruned\n", rows)
+				return nil
+			},
+		},
+	}
+}
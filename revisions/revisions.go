@@ -0,0 +1,447 @@
+// Copyright 2021 Harran Ali <harran.m@gmail.com>. All rights reserved.
+// Use of this source code is governed by MIT-style
+// license that can be found in the LICENSE file.
+
+package revisions
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"reflect"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/gocondor/core/database"
+	"github.com/gocondor/gocondor/models"
+	"github.com/gocondor/gocondor/retention"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+)
+
+// Policy tunes the revisions of a model
+type Policy struct {
+	// Keep is how many revisions are kept per record, the older ones are pruned, 0 keeps them all
+	Keep int
+	// OlderThan prunes the revisions older than the duration with the retention policies,
+	// the latest revision of each record is kept, 0 keeps them all
+	OlderThan time.Duration
+	// Ignore lists the columns left out of the snapshots besides the version and the update time,
+	// changes to these columns alone don't make a revision and restoring leaves them as they are
+	Ignore []string
+}
+
+// Change is a column that differs between two revisions
+type Change struct {
+	Column string      `json:"column"`
+	From   interface{} `json:"from"`
+	To     interface{} `json:"to"`
+}
+
+// the setting holding the author of the changes, see By
+const authorKey = "revisions:author"
+
+// the setting holding the values read before a delete
+const deletedValues = "revisions:deleted"
+
+// how many times a revision is numbered again when concurrent writes take its number
+const numberAttempts = 5
+
+type tracked struct {
+	name   string
+	table  string
+	model  reflect.Type
+	policy Policy
+	ignore map[string]bool
+}
+
+var trackedMu sync.RWMutex
+var byName = map[string]*tracked{}
+var byType = map[reflect.Type]*tracked{}
+
+// Register keeps the revisions of the records of model, they're listed, compared and restored
+// under name in the admin routes, the models without a registration have no revisions, e.g:
+//
+//	revisions.Register("posts", &models.Post{}, revisions.Policy{Keep: 50, Ignore: []string{"views"}})
+func Register(name string, model interface{}, policy Policy) {
+	stmt := &gorm.Statement{DB: database.Resolve()}
+	if err := stmt.Parse(model); err != nil {
+		log.Fatalf("revisions: %s: %v", name, err)
+	}
+
+	t := &tracked{
+		name:   name,
+		table:  stmt.Schema.Table,
+		model:  stmt.Schema.ModelType,
+		policy: policy,
+		ignore: map[string]bool{},
+	}
+	for _, column := range policy.Ignore {
+		t.ignore[column] = true
+	}
+	// they change on every save
+	for _, field := range stmt.Schema.Fields {
+		if field.AutoUpdateTime > 0 || field.Name == "Version" {
+			t.ignore[field.DBName] = true
+		}
+	}
+
+	trackedMu.Lock()
+	byName[name] = t
+	byType[t.model] = t
+	trackedMu.Unlock()
+
+	if policy.OlderThan > 0 {
+		retention.Register(retention.Policy{
+			Name:      "revisions:" + name,
+			Table:     "revisions",
+			OlderThan: policy.OlderThan,
+			Where: fmt.Sprintf("revisionable_type = '%s' AND id NOT IN (SELECT MAX(id) FROM revisions "+
+				"WHERE revisionable_type = '%s' GROUP BY revisionable_id)", t.table, t.table),
+		})
+	}
+}
+
+// RegisterCallbacks registers the callbacks snapshotting the records of the registered models
+func RegisterCallbacks() {
+	db := database.Resolve()
+
+	db.Callback().Create().After("gorm:create").Register("revisions:create", snapshotCreate)
+	db.Callback().Update().After("gorm:update").Register("revisions:update", snapshotUpdate)
+	db.Callback().Delete().Before("gorm:delete").Register("revisions:read_delete", readDeleted)
+	db.Callback().Delete().After("gorm:delete").Register("revisions:delete", snapshotDelete)
+}
+
+// By is a query scope naming the author of the revisions made by the query, e.g:
+//
+//	db.Scopes(revisions.By(userID)).Save(&post)
+func By(authorID uint) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		return db.Set(authorKey, authorID)
+	}
+}
+
+// History returns the revisions of a record, latest first
+func History(db *gorm.DB, record interface{}) ([]Revision, error) {
+	revisionableType, revisionableID, err := models.Subject(db, record)
+	if err != nil {
+		return nil, err
+	}
+	var revisions []Revision
+	err = db.Where("revisionable_type = ? AND revisionable_id = ?", revisionableType, revisionableID).
+		Order("number DESC").
+		Find(&revisions).Error
+	return revisions, err
+}
+
+// RevisionOf returns a revision of a record by number, gorm.ErrRecordNotFound when it doesn't exist
+func RevisionOf(db *gorm.DB, record interface{}, number uint) (*Revision, error) {
+	revisionableType, revisionableID, err := models.Subject(db, record)
+	if err != nil {
+		return nil, err
+	}
+	var revision Revision
+	err = db.Where("revisionable_type = ? AND revisionable_id = ? AND number = ?", revisionableType, revisionableID, number).
+		First(&revision).Error
+	return &revision, err
+}
+
+// Compare lists the columns of a record that changed from a revision to another
+func Compare(db *gorm.DB, record interface{}, from uint, to uint) ([]Change, error) {
+	a, err := RevisionOf(db, record, from)
+	if err != nil {
+		return nil, err
+	}
+	b, err := RevisionOf(db, record, to)
+	if err != nil {
+		return nil, err
+	}
+	return Diff(a.Snapshot, b.Snapshot), nil
+}
+
+// Diff lists the columns that differ between two snapshots, by column name
+func Diff(from Snapshot, to Snapshot) []Change {
+	var changes []Change
+	for column, value := range to {
+		if old, ok := from[column]; !ok || !reflect.DeepEqual(old, value) {
+			changes = append(changes, Change{Column: column, From: old, To: value})
+		}
+	}
+	for column, old := range from {
+		if _, ok := to[column]; !ok {
+			changes = append(changes, Change{Column: column, From: old})
+		}
+	}
+	sort.Slice(changes, func(i, j int) bool {
+		return changes[i].Column < changes[j].Column
+	})
+	return changes
+}
+
+// Restore sets the columns of a loaded record back to a revision and saves it, the primary key,
+// the creation time and the ignored columns are left as they are, restoring makes
+// a new revision and brings back soft deleted records, e.g:
+//
+//	db.Unscoped().First(&post, id)
+//	err := revisions.Restore(db.Scopes(revisions.By(userID)), &post, 3)
+func Restore(db *gorm.DB, record interface{}, number uint) error {
+	// the conditions of the queries below mustn't pile up on the caller's scoped db
+	db = db.Session(&gorm.Session{})
+	revision, err := RevisionOf(db, record, number)
+	if err != nil {
+		return err
+	}
+
+	stmt := &gorm.Statement{DB: db}
+	if err := stmt.Parse(record); err != nil {
+		return err
+	}
+	rv := reflect.Indirect(reflect.ValueOf(record))
+	for _, field := range stmt.Schema.Fields {
+		if field.DBName == "" || field.PrimaryKey || field.AutoCreateTime > 0 {
+			continue
+		}
+		// the columns added since the revision keep their values
+		value, ok := revision.Snapshot[field.DBName]
+		if !ok {
+			continue
+		}
+		if err := field.Set(rv, restorable(field, value)); err != nil {
+			return fmt.Errorf("revisions: restore %s: %w", field.DBName, err)
+		}
+	}
+	return db.Unscoped().Save(record).Error
+}
+
+// Prune drops the revisions over the Keep of the registered models, they're pruned as
+// the records change, it's needed after lowering a Keep
+func Prune(db *gorm.DB) (int64, error) {
+	trackedMu.RLock()
+	var all []*tracked
+	for _, t := range byName {
+		all = append(all, t)
+	}
+	trackedMu.RUnlock()
+
+	var total int64
+	for _, t := range all {
+		if t.policy.Keep <= 0 {
+			continue
+		}
+		var latest []struct {
+			RevisionableID uint
+			Number         uint
+		}
+		err := db.Model(&Revision{}).
+			Select("revisionable_id, MAX(number) AS number").
+			Where("revisionable_type = ?", t.table).
+			Group("revisionable_id").
+			Having("COUNT(*) > ?", t.policy.Keep).
+			Scan(&latest).Error
+		if err != nil {
+			return total, err
+		}
+		for _, l := range latest {
+			rows, err := prune(db, t, l.RevisionableID, l.Number)
+			if err != nil {
+				return total, err
+			}
+			total += rows
+		}
+	}
+	return total, nil
+}
+
+func snapshotCreate(db *gorm.DB) {
+	t := trackedOf(db)
+	if t == nil {
+		return
+	}
+	forEachRecord(db, func(rv reflect.Value) {
+		record(db, t, models.OpCreate, rv, nil)
+	})
+}
+
+func snapshotUpdate(db *gorm.DB) {
+	t := trackedOf(db)
+	if t == nil || db.RowsAffected == 0 {
+		return
+	}
+	// updates without a loaded record (db.Model(&Post{}).Where(...).Update) aren't snapshotted
+	forEachRecord(db, func(rv reflect.Value) {
+		record(db, t, models.OpUpdate, rv, nil)
+	})
+}
+
+// readDeleted reads the stored values of the record before it's deleted
+func readDeleted(db *gorm.DB) {
+	t := trackedOf(db)
+	if t == nil || db.Statement.ReflectValue.Kind() != reflect.Struct {
+		return
+	}
+	if row := stored(db, t, db.Statement.ReflectValue); row != nil {
+		db.InstanceSet(deletedValues, row)
+	}
+}
+
+func snapshotDelete(db *gorm.DB) {
+	t := trackedOf(db)
+	if t == nil || db.RowsAffected == 0 {
+		return
+	}
+	if row, ok := db.InstanceGet(deletedValues); ok {
+		record(db, t, models.OpDelete, db.Statement.ReflectValue, row.(Snapshot))
+	}
+}
+
+// record adds a revision of the record, within the statement's transaction,
+// the values are read back from the table when row is nil
+func record(db *gorm.DB, t *tracked, op string, rv reflect.Value, row Snapshot) {
+	id, ok := primaryKey(db, rv)
+	if !ok {
+		return
+	}
+	if row == nil {
+		if row = stored(db, t, rv); row == nil {
+			return
+		}
+	}
+
+	tx := db.Session(&gorm.Session{NewDB: true})
+	var revision Revision
+	// the number is unique per record, when a concurrent write took it first the insert
+	// is a no-op and the latest number is read again
+	for attempt := 1; ; attempt++ {
+		var latest Revision
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("revisionable_type = ? AND revisionable_id = ?", t.table, id).
+			Order("number DESC").Limit(1).Find(&latest).Error
+		if err != nil {
+			db.AddError(err)
+			return
+		}
+		// saving a record without changing it doesn't make a revision
+		if op == models.OpUpdate && latest.ID != 0 && latest.Op != models.OpDelete && sameSnapshot(latest.Snapshot, row) {
+			return
+		}
+
+		revision = Revision{
+			RevisionableType: t.table,
+			RevisionableID:   id,
+			Number:           latest.Number + 1,
+			Op:               op,
+			Snapshot:         row,
+		}
+		if author, ok := db.Get(authorKey); ok {
+			if authorID, ok := author.(uint); ok {
+				revision.AuthorID = &authorID
+			}
+		}
+		res := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&revision)
+		if res.Error != nil {
+			db.AddError(res.Error)
+			return
+		}
+		if res.RowsAffected > 0 {
+			break
+		}
+		if attempt == numberAttempts {
+			db.AddError(fmt.Errorf("revisions: %s %d: the revision %d was taken %d times by concurrent writes", t.table, id, revision.Number, numberAttempts))
+			return
+		}
+	}
+
+	if t.policy.Keep > 0 && revision.Number > uint(t.policy.Keep) {
+		if _, err := prune(tx, t, id, revision.Number); err != nil {
+			db.AddError(err)
+		}
+	}
+}
+
+// prune drops the revisions of a record over the Keep of its model
+func prune(db *gorm.DB, t *tracked, id uint, latest uint) (int64, error) {
+	res := db.Where("revisionable_type = ? AND revisionable_id = ? AND number <= ?", t.table, id, latest-uint(t.policy.Keep)).
+		Delete(&Revision{})
+	return res.RowsAffected, res.Error
+}
+
+// stored reads the record's row as stored, without the ignored columns
+func stored(db *gorm.DB, t *tracked, rv reflect.Value) Snapshot {
+	id, ok := primaryKey(db, rv)
+	if !ok {
+		return nil
+	}
+	row := map[string]interface{}{}
+	err := db.Session(&gorm.Session{NewDB: true}).Table(t.table).
+		Where(db.Statement.Schema.PrioritizedPrimaryField.DBName+" = ?", id).Take(&row).Error
+	if err != nil {
+		return nil
+	}
+	for column := range t.ignore {
+		delete(row, column)
+	}
+	return row
+}
+
+// sameSnapshot compares the snapshots as they're stored
+func sameSnapshot(a Snapshot, b Snapshot) bool {
+	x, err := json.Marshal(a)
+	if err != nil {
+		return false
+	}
+	y, err := json.Marshal(b)
+	return err == nil && string(x) == string(y)
+}
+
+// restorable converts the value decoded from the json of a snapshot to one the field accepts
+func restorable(field *schema.Field, value interface{}) interface{} {
+	switch v := value.(type) {
+	case float64:
+		if field.DataType != schema.Float && v == float64(int64(v)) {
+			return int64(v)
+		}
+	case string:
+		if field.DataType == schema.Time {
+			if t, err := time.Parse(time.RFC3339Nano, v); err == nil {
+				return t
+			}
+		}
+	}
+	return value
+}
+
+// trackedOf returns the registration of the statement's model, nil when it isn't registered
+func trackedOf(db *gorm.DB) *tracked {
+	if db.Error != nil || db.Statement.Schema == nil {
+		return nil
+	}
+	trackedMu.RLock()
+	defer trackedMu.RUnlock()
+	return byType[db.Statement.Schema.ModelType]
+}
+
+// forEachRecord calls fn with every record of the statement
+func forEachRecord(db *gorm.DB, fn func(rv reflect.Value)) {
+	rv := db.Statement.ReflectValue
+	switch rv.Kind() {
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < rv.Len(); i++ {
+			fn(reflect.Indirect(rv.Index(i)))
+		}
+	case reflect.Struct:
+		fn(rv)
+	}
+}
+
+// primaryKey returns the uint primary key of a record of the statement's model
+func primaryKey(db *gorm.DB, rv reflect.Value) (uint, bool) {
+	field := db.Statement.Schema.PrioritizedPrimaryField
+	if field == nil || rv.Kind() != reflect.Struct {
+		return 0, false
+	}
+	value, zero := field.ValueOf(rv)
+	id, ok := value.(uint)
+	return id, ok && !zero
+}
@@ -0,0 +1,15 @@
+// Copyright 2021 Harran Ali <harran.m@gmail.com>. All rights reserved.
+// Use of this source code is governed by MIT-style
+// license that can be found in the LICENSE file.
+
+package saga
+
+// RegisterWorkflows registers the app's workflows
+func RegisterWorkflows() {
+	// Register your workflows here, e.g:
+	// Register(Workflow{Name: "checkout", Steps: []Step{
+	// 	{Name: "reserve stock", Run: reserveStock, Compensate: releaseStock},
+	// 	{Name: "charge card", Run: chargeCard, Compensate: refundCard},
+	// 	{Name: "ship", Run: ship},
+	// }})
+}
@@ -0,0 +1,274 @@
+// Copyright 2021 Harran Ali <harran.m@gmail.com>. All rights reserved.
+// Use of this source code is governed by MIT-style
+// license that can be found in the LICENSE file.
+
+package saga
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/gocondor/gocondor/id"
+	"github.com/gocondor/gocondor/models"
+	"gorm.io/gorm"
+)
+
+// the statuses of a run
+const (
+	Running      = "running"
+	Compensating = "compensating"
+	Completed    = "completed"
+	Failed       = "failed"
+)
+
+// Data is the state shared by the steps of a run, it's persisted after every step
+type Data map[string]interface{}
+
+// Step is a step of a workflow, Compensate undoes Run when a later step fails,
+// both may run more than once after a crash so they must be idempotent
+type Step struct {
+	Name       string
+	Run        func(ctx context.Context, data Data) error
+	Compensate func(ctx context.Context, data Data) error
+}
+
+// Workflow is a named list of steps
+type Workflow struct {
+	Name  string
+	Steps []Step
+}
+
+// ErrUnknownWorkflow is returned when starting or resuming a workflow that isn't registered
+var ErrUnknownWorkflow = errors.New("unknown workflow")
+
+// ErrLeaseLost is returned when another instance took over a run whose lease expired
+var ErrLeaseLost = errors.New("the lease of the run was taken over by another instance")
+
+// LeaseFor is how long an instance holds the runs it executes without renewing their lease,
+// it's renewed every third of it while they run, so only the runs of a stopped instance expire
+var LeaseFor = 5 * time.Minute
+
+var ownerOnce sync.Once
+var owner string
+
+var mu sync.RWMutex
+var workflows = map[string]Workflow{}
+
+// Register registers a workflow
+func Register(w Workflow) {
+	mu.Lock()
+	defer mu.Unlock()
+	workflows[w.Name] = w
+}
+
+func lookup(name string) (Workflow, bool) {
+	mu.RLock()
+	defer mu.RUnlock()
+	w, ok := workflows[name]
+	return w, ok
+}
+
+// Start persists a new run of the workflow and executes it, the returned run tells
+// whether it completed or failed and was compensated
+func Start(ctx context.Context, db *gorm.DB, name string, data Data) (*models.SagaRun, error) {
+	w, run, err := create(db, name, data)
+	if err != nil {
+		return nil, err
+	}
+	return run, execute(ctx, db, w, run)
+}
+
+// StartAsync persists a new run of the workflow and executes it in the background
+func StartAsync(db *gorm.DB, name string, data Data) (*models.SagaRun, error) {
+	w, run, err := create(db, name, data)
+	if err != nil {
+		return nil, err
+	}
+	go func() {
+		if err := execute(context.Background(), db, w, run); err != nil {
+			log.Printf("saga %s #%d: %v", name, run.ID, err)
+		}
+	}()
+	return run, nil
+}
+
+// Resume picks up the runs left running or compensating by a crash or a restart, a run is
+// claimed once its lease expired so the instances resuming at the same time don't share it
+func Resume(ctx context.Context, db *gorm.DB) error {
+	var runs []models.SagaRun
+	err := db.Where("status IN ?", []string{Running, Compensating}).
+		Where("lease_until IS NULL OR lease_until < ?", time.Now()).Order("id").Find(&runs).Error
+	if err != nil {
+		return err
+	}
+	for i := range runs {
+		run := &runs[i]
+		w, ok := lookup(run.Workflow)
+		if !ok {
+			log.Printf("saga #%d: %v: %s", run.ID, ErrUnknownWorkflow, run.Workflow)
+			continue
+		}
+		claimed, err := claim(db, run)
+		if err != nil {
+			log.Printf("saga %s #%d: %v", run.Workflow, run.ID, err)
+			continue
+		}
+		if !claimed {
+			continue
+		}
+		if err := execute(ctx, db, w, run); err != nil {
+			log.Printf("saga %s #%d: %v", run.Workflow, run.ID, err)
+		}
+	}
+	return nil
+}
+
+// Schedule resumes the interrupted runs now and then every LeaseFor in the background, so the runs
+// of the instances that stopped are picked up once their lease expired
+func Schedule(db *gorm.DB) {
+	go func() {
+		ticker := time.NewTicker(LeaseFor)
+		defer ticker.Stop()
+		for {
+			if err := Resume(context.Background(), db); err != nil {
+				log.Printf("saga: resuming the runs failed: %v", err)
+			}
+			<-ticker.C
+		}
+	}()
+}
+
+// create persists a new run of the workflow, owned by this instance
+func create(db *gorm.DB, name string, data Data) (Workflow, *models.SagaRun, error) {
+	w, ok := lookup(name)
+	if !ok {
+		return Workflow{}, nil, fmt.Errorf("%w: %s", ErrUnknownWorkflow, name)
+	}
+	encoded, err := json.Marshal(data)
+	if err != nil {
+		return Workflow{}, nil, err
+	}
+	until := time.Now().Add(LeaseFor)
+	run := &models.SagaRun{Workflow: name, Status: Running, Data: string(encoded), Owner: instance(), LeaseUntil: &until}
+	if err := db.Create(run).Error; err != nil {
+		return Workflow{}, nil, err
+	}
+	return w, run, nil
+}
+
+// claim takes the run over when its lease is still expired, ok is false when another instance claimed it first
+func claim(db *gorm.DB, run *models.SagaRun) (ok bool, err error) {
+	now := time.Now()
+	until := now.Add(LeaseFor)
+	update := db.Model(&models.SagaRun{}).
+		Where("id = ? AND status IN ?", run.ID, []string{Running, Compensating}).
+		Where("lease_until IS NULL OR lease_until < ?", now).
+		UpdateColumns(map[string]interface{}{"owner": instance(), "lease_until": until})
+	if update.Error != nil || update.RowsAffected == 0 {
+		return false, update.Error
+	}
+	run.Owner, run.LeaseUntil = instance(), &until
+	return true, nil
+}
+
+// renew extends the lease of the run while it's executed, until stop is closed
+func renew(db *gorm.DB, run *models.SagaRun, stop <-chan struct{}) {
+	ticker := time.NewTicker(LeaseFor / 3)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+			err := db.Model(&models.SagaRun{}).Where("id = ? AND owner = ?", run.ID, instance()).
+				UpdateColumn("lease_until", time.Now().Add(LeaseFor)).Error
+			if err != nil {
+				log.Printf("saga %s #%d: renewing the lease: %v", run.Workflow, run.ID, err)
+			}
+		}
+	}
+}
+
+// instance returns the name of this instance as the owner of the runs
+func instance() string {
+	ownerOnce.Do(func() {
+		host, _ := os.Hostname()
+		owner = host + "-" + id.New()
+	})
+	return owner
+}
+
+// execute runs the steps of the run from where it stopped, a failing step triggers
+// the compensation of the steps that ran before it, in reverse order
+func execute(ctx context.Context, db *gorm.DB, w Workflow, run *models.SagaRun) error {
+	stop := make(chan struct{})
+	defer close(stop)
+	go renew(db, run, stop)
+
+	data := Data{}
+	if run.Data != "" {
+		if err := json.Unmarshal([]byte(run.Data), &data); err != nil {
+			return err
+		}
+	}
+
+	for run.Status == Running && run.Step < len(w.Steps) {
+		step := w.Steps[run.Step]
+		if err := step.Run(ctx, data); err != nil {
+			run.Status = Compensating
+			run.Error = fmt.Sprintf("%s: %v", step.Name, err)
+		} else {
+			run.Step++
+		}
+		if err := save(db, run, data); err != nil {
+			return err
+		}
+	}
+	if run.Status == Running {
+		run.Status = Completed
+		return save(db, run, data)
+	}
+
+	for run.Status == Compensating && run.Step > 0 {
+		step := w.Steps[run.Step-1]
+		if step.Compensate != nil {
+			if err := step.Compensate(ctx, data); err != nil {
+				// leave the run compensating so Resume tries again
+				return fmt.Errorf("compensating %s: %w", step.Name, err)
+			}
+		}
+		run.Step--
+		if err := save(db, run, data); err != nil {
+			return err
+		}
+	}
+	if run.Status == Compensating {
+		run.Status = Failed
+		return save(db, run, data)
+	}
+	return nil
+}
+
+// save persists the progress of the run while this instance still owns it
+func save(db *gorm.DB, run *models.SagaRun, data Data) error {
+	encoded, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+	run.Data = string(encoded)
+	update := db.Model(run).Where("owner = ?", instance()).
+		Select("status", "step", "data", "error", "updated_at").Updates(run)
+	if update.Error != nil {
+		return update.Error
+	}
+	if update.RowsAffected == 0 {
+		return ErrLeaseLost
+	}
+	return nil
+}
@@ -0,0 +1,12 @@
+// Copyright 2021 Harran Ali <harran.m@gmail.com>. All rights reserved.
+// Use of this source code is governed by MIT-style
+// license that can be found in the LICENSE file.
+
+//go:build !race
+// +build !race
+
+package scrubber
+
+// raceEnabled reports whether the tests run with the race detector, it makes sync.Pool drop
+// items at random so the allocations aren't counted then
+const raceEnabled = false
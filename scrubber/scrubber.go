@@ -0,0 +1,183 @@
+// Copyright 2021 Harran Ali <harran.m@gmail.com>. All rights reserved.
+// Use of this source code is governed by MIT-style
+// license that can be found in the LICENSE file.
+
+package scrubber
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// Redacted replaces the scrubbed values
+const Redacted = "[REDACTED]"
+
+// rule replaces the matches of a pattern, the ones valid rejects are kept
+type rule struct {
+	pattern     *regexp.Regexp
+	replacement string
+	valid       func(match string) bool
+}
+
+// Scrubber removes personal data and secrets from text
+type Scrubber struct {
+	mu    sync.RWMutex
+	rules []rule
+}
+
+var (
+	scrubber *Scrubber
+	once     sync.Once
+)
+
+// builtinPatterns match values that are sensitive wherever they appear
+var builtinPatterns = []string{
+	// emails
+	`[A-Za-z0-9._%+\-]+@[A-Za-z0-9.\-]+\.[A-Za-z]{2,}`,
+	// jwt tokens
+	`eyJ[A-Za-z0-9_\-]+\.[A-Za-z0-9_\-]+\.[A-Za-z0-9_\-]*`,
+	// bearer tokens
+	`(?i)bearer\s+[A-Za-z0-9._~+/\-]+=*`,
+}
+
+// cardPattern matches the card numbers, 13 to 19 digits optionally separated by spaces or dashes,
+// only the ones passing the luhn check are scrubbed so the ids and timestamps are kept
+const cardPattern = `\b(?:\d[ \-]?){12,18}\d\b`
+
+// defaultFields are the field names whose values are always scrubbed
+var defaultFields = []string{"password", "token", "secret", "authorization", "api_key", "apiKey"}
+
+// New creates a scrubber with the built-in patterns and the default fields
+func New() *Scrubber {
+	s := &Scrubber{}
+	for _, p := range builtinPatterns {
+		s.rules = append(s.rules, rule{pattern: regexp.MustCompile(p), replacement: Redacted})
+	}
+	s.rules = append(s.rules, rule{pattern: regexp.MustCompile(cardPattern), replacement: Redacted, valid: luhn})
+	s.AddFields(defaultFields...)
+
+	return s
+}
+
+// Resolve returns the scrubber, the fields listed in the comma separated
+// SCRUB_FIELDS are scrubbed in addition to the default ones
+func Resolve() *Scrubber {
+	once.Do(func() {
+		scrubber = New()
+		for _, field := range strings.Split(os.Getenv("SCRUB_FIELDS"), ",") {
+			if field = strings.TrimSpace(field); field != "" {
+				scrubber.AddFields(field)
+			}
+		}
+	})
+
+	return scrubber
+}
+
+// AddPattern scrubs every match of the regular expression
+func (s *Scrubber) AddPattern(expr string) error {
+	pattern, err := regexp.Compile(expr)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	s.rules = append(s.rules, rule{pattern: pattern, replacement: Redacted})
+	s.mu.Unlock()
+
+	return nil
+}
+
+// AddFields scrubs the values of the named fields, in json ("password":"x")
+// as well as in key value form (password=x)
+func (s *Scrubber) AddFields(names ...string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, name := range names {
+		pattern := regexp.MustCompile(fmt.Sprintf(`(?i)("?%s"?\s*[:=]\s*"?)[^"&,;\s}]+`, regexp.QuoteMeta(name)))
+		s.rules = append(s.rules, rule{pattern: pattern, replacement: "${1}" + Redacted})
+	}
+}
+
+// Scrub returns the text with the sensitive values redacted
+func (s *Scrubber) Scrub(text string) string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for _, r := range s.rules {
+		if r.valid == nil {
+			text = r.pattern.ReplaceAllString(text, r.replacement)
+			continue
+		}
+		text = r.pattern.ReplaceAllStringFunc(text, func(match string) string {
+			if !r.valid(match) {
+				return match
+			}
+			return r.pattern.ReplaceAllString(match, r.replacement)
+		})
+	}
+	return text
+}
+
+// matches reports whether any rule matches b, regexp doesn't allocate to find a match
+func (s *Scrubber) matches(b []byte) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for _, r := range s.rules {
+		if r.pattern.Match(b) {
+			return true
+		}
+	}
+	return false
+}
+
+// luhn reports whether the digits of the number pass the luhn checksum of the card numbers
+func luhn(number string) bool {
+	sum, double := 0, false
+	for i := len(number) - 1; i >= 0; i-- {
+		if number[i] < '0' || number[i] > '9' {
+			continue
+		}
+		digit := int(number[i] - '0')
+		if double {
+			if digit *= 2; digit > 9 {
+				digit -= 9
+			}
+		}
+		sum += digit
+		double = !double
+	}
+	return sum%10 == 0
+}
+
+// Writer returns a writer scrubbing everything written to w
+func (s *Scrubber) Writer(w io.Writer) io.Writer {
+	return &writer{s, w}
+}
+
+// writer scrubs the written data before passing it on
+type writer struct {
+	scrubber *Scrubber
+	w        io.Writer
+}
+
+// Write scrubs p and writes it to the underlying writer, the lines without sensitive values
+// are the most of the logs so they're passed on as they are, without allocating a copy
+func (w *writer) Write(p []byte) (int, error) {
+	if !w.scrubber.matches(p) {
+		if _, err := w.w.Write(p); err != nil {
+			return 0, err
+		}
+		return len(p), nil
+	}
+	if _, err := io.WriteString(w.w, w.scrubber.Scrub(string(p))); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
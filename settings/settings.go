@@ -0,0 +1,181 @@
+// Copyright 2021 Harran Ali <harran.m@gmail.com>. All rights reserved.
+// Use of this source code is governed by MIT-style
+// license that can be found in the LICENSE file.
+
+package settings
+
+import (
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gocondor/core/database"
+	"github.com/gocondor/gocondor/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// cacheTTL is how long a setting is served from the cache before it's read again
+const cacheTTL = time.Minute
+
+// cached is a cached setting value, found is false for settings that aren't stored
+type cached struct {
+	value     string
+	found     bool
+	expiresAt time.Time
+}
+
+// Store reads and writes the settings stored in the database,
+// reads go through an in-memory cache
+type Store struct {
+	db    *gorm.DB
+	mu    sync.RWMutex
+	cache map[string]cached
+}
+
+// Scope gives access to the app wide settings or to the settings of one user
+type Scope struct {
+	store  *Store
+	userID uint
+}
+
+var (
+	store *Store
+	once  sync.Once
+)
+
+// New creates a settings store on the database
+func New(db *gorm.DB) *Store {
+	return &Store{
+		db:    db,
+		cache: map[string]cached{},
+	}
+}
+
+// Resolve returns the settings store of the app database
+func Resolve() *Store {
+	once.Do(func() {
+		store = New(database.Resolve())
+	})
+	return store
+}
+
+// App returns the app wide settings
+func (s *Store) App() *Scope {
+	return &Scope{store: s}
+}
+
+// User returns the settings of the user
+func (s *Store) User(userID uint) *Scope {
+	return &Scope{store: s, userID: userID}
+}
+
+// Get returns the setting's value and whether it's stored
+func (sc *Scope) Get(key string) (string, bool, error) {
+	s := sc.store
+	cacheKey := fmt.Sprintf("%d:%s", sc.userID, key)
+
+	s.mu.RLock()
+	entry, ok := s.cache[cacheKey]
+	s.mu.RUnlock()
+	if ok && time.Now().Before(entry.expiresAt) {
+		return entry.value, entry.found, nil
+	}
+
+	var setting models.Setting
+	res := s.db.Where("user_id = ? AND `key` = ?", sc.userID, key).Limit(1).Find(&setting)
+	if res.Error != nil {
+		return "", false, res.Error
+	}
+
+	entry = cached{value: setting.Value, found: res.RowsAffected > 0, expiresAt: time.Now().Add(cacheTTL)}
+	s.mu.Lock()
+	s.cache[cacheKey] = entry
+	s.mu.Unlock()
+
+	return entry.value, entry.found, nil
+}
+
+// String returns the setting's value, or def if it's not stored
+func (sc *Scope) String(key string, def string) string {
+	value, found, err := sc.Get(key)
+	if err != nil || !found {
+		return def
+	}
+	return value
+}
+
+// Int returns the setting's value as an int, or def if it's not stored or not an int
+func (sc *Scope) Int(key string, def int) int {
+	value, err := strconv.Atoi(sc.String(key, ""))
+	if err != nil {
+		return def
+	}
+	return value
+}
+
+// Bool returns the setting's value as a bool, or def if it's not stored or not a bool
+func (sc *Scope) Bool(key string, def bool) bool {
+	value, err := strconv.ParseBool(sc.String(key, ""))
+	if err != nil {
+		return def
+	}
+	return value
+}
+
+// Duration returns the setting's value as a duration (e.g "1h30m"), or def if it's not stored or not a duration
+func (sc *Scope) Duration(key string, def time.Duration) time.Duration {
+	value, err := time.ParseDuration(sc.String(key, ""))
+	if err != nil {
+		return def
+	}
+	return value
+}
+
+// Set stores the setting's value
+func (sc *Scope) Set(key string, value string) error {
+	setting := models.Setting{UserID: sc.userID, Key: key, Value: value}
+	res := sc.store.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "user_id"}, {Name: "key"}},
+		DoUpdates: clause.AssignmentColumns([]string{"value", "updated_at"}),
+	}).Create(&setting)
+	if res.Error != nil {
+		return res.Error
+	}
+
+	sc.forget(key)
+	return nil
+}
+
+// Delete removes the setting
+func (sc *Scope) Delete(key string) error {
+	res := sc.store.db.Unscoped().Where("user_id = ? AND `key` = ?", sc.userID, key).Delete(&models.Setting{})
+	if res.Error != nil {
+		return res.Error
+	}
+
+	sc.forget(key)
+	return nil
+}
+
+// All returns all the settings of the scope
+func (sc *Scope) All() (map[string]string, error) {
+	var settings []models.Setting
+	if err := sc.store.db.Where("user_id = ?", sc.userID).Find(&settings).Error; err != nil {
+		return nil, err
+	}
+
+	all := make(map[string]string, len(settings))
+	for _, setting := range settings {
+		all[setting.Key] = setting.Value
+	}
+	return all, nil
+}
+
+// forget removes the setting from the cache
+func (sc *Scope) forget(key string) {
+	sc.store.mu.Lock()
+	delete(sc.store.cache, fmt.Sprintf("%d:%s", sc.userID, key))
+	sc.store.mu.Unlock()
+}
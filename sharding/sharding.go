@@ -0,0 +1,94 @@
+// Copyright 2021 Harran Ali <harran.m@gmail.com>. All rights reserved.
+// Use of this source code is governed by MIT-style
+// license that can be found in the LICENSE file.
+
+package sharding
+
+import (
+	"hash/fnv"
+	"sync"
+
+	"gorm.io/gorm"
+)
+
+// Resolver picks the shard a key (tenant id, user id, ...) belongs to
+type Resolver interface {
+	Shard(key uint64, shards int) int
+}
+
+// ModuloResolver maps keys to shards by the key modulo the number of shards
+type ModuloResolver struct{}
+
+// Shard returns the shard of the key
+func (ModuloResolver) Shard(key uint64, shards int) int {
+	return int(key % uint64(shards))
+}
+
+// Shards routes queries to one of several database connections
+type Shards struct {
+	conns    []*gorm.DB
+	resolver Resolver
+}
+
+var shards *Shards
+
+// New creates the shards over the given connections, the order of the connections
+// must never change as the resolver maps keys to their position
+func New(resolver Resolver, conns ...*gorm.DB) *Shards {
+	shards = &Shards{
+		conns:    conns,
+		resolver: resolver,
+	}
+	return shards
+}
+
+// Resolve returns the shards created with New
+func Resolve() *Shards {
+	return shards
+}
+
+// For returns the connection of the shard the key belongs to
+func (s *Shards) For(key uint64) *gorm.DB {
+	return s.conns[s.resolver.Shard(key, len(s.conns))]
+}
+
+// ForString returns the connection of the shard a string key belongs to
+func (s *Shards) ForString(key string) *gorm.DB {
+	h := fnv.New64a()
+	h.Write([]byte(key))
+	return s.For(h.Sum64())
+}
+
+// All returns the connections of all the shards
+func (s *Shards) All() []*gorm.DB {
+	return s.conns
+}
+
+// FanOut runs fn on every shard concurrently and returns the first error,
+// fn receives the shard number and its connection
+func (s *Shards) FanOut(fn func(shard int, db *gorm.DB) error) error {
+	var wg sync.WaitGroup
+	errs := make([]error, len(s.conns))
+	for i, db := range s.conns {
+		wg.Add(1)
+		go func(i int, db *gorm.DB) {
+			defer wg.Done()
+			errs[i] = fn(i, db)
+		}(i, db)
+	}
+	wg.Wait()
+
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// AutoMigrate migrates the models on every shard
+func (s *Shards) AutoMigrate(models ...interface{}) error {
+	return s.FanOut(func(shard int, db *gorm.DB) error {
+		return db.AutoMigrate(models...)
+	})
+}
@@ -0,0 +1,68 @@
+// Copyright 2021 Harran Ali <harran.m@gmail.com>. All rights reserved.
+// Use of this source code is governed by MIT-style
+// license that can be found in the LICENSE file.
+
+package spam
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+var client = &http.Client{Timeout: 5 * time.Second}
+
+// Akismet asks akismet.com whether the submission is spam
+type Akismet struct {
+	Key string
+	// Site is the url of the app, e.g: https://example.com
+	Site string
+}
+
+// Name returns the name of the checker
+func (a Akismet) Name() string {
+	return "akismet"
+}
+
+// IsSpam submits the submission to akismet's comment-check
+func (a Akismet) IsSpam(ctx context.Context, s Submission) (bool, error) {
+	form := url.Values{
+		"blog":                 {a.Site},
+		"user_ip":              {s.IP},
+		"user_agent":           {s.UserAgent},
+		"referrer":             {s.Referrer},
+		"comment_type":         {s.Kind},
+		"comment_author":       {s.Author},
+		"comment_author_email": {s.Email},
+		"comment_content":      {s.Content},
+	}
+	endpoint := fmt.Sprintf("https://%s.rest.akismet.com/1.1/comment-check", a.Key)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return false, err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1024))
+	if err != nil {
+		return false, err
+	}
+
+	// akismet answers "true" or "false", anything else is an error explained in a header
+	switch strings.TrimSpace(string(body)) {
+	case "true":
+		return true, nil
+	case "false":
+		return false, nil
+	}
+	return false, fmt.Errorf("unexpected answer %q: %s", body, resp.Header.Get("X-akismet-debug-help"))
+}
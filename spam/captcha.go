@@ -0,0 +1,32 @@
+// Copyright 2021 Harran Ali <harran.m@gmail.com>. All rights reserved.
+// Use of this source code is governed by MIT-style
+// license that can be found in the LICENSE file.
+
+package spam
+
+import (
+	"context"
+	"errors"
+
+	"github.com/gocondor/gocondor/captcha"
+)
+
+// Captcha flags the submissions without a solved captcha
+type Captcha struct {
+	Verifier captcha.Verifier
+}
+
+// Name returns the name of the checker
+func (c Captcha) Name() string {
+	return c.Verifier.Provider.Name
+}
+
+// IsSpam verifies the captcha token posted in the provider's field, the kind of the
+// submission is the expected action
+func (c Captcha) IsSpam(ctx context.Context, s Submission) (bool, error) {
+	err := c.Verifier.Check(ctx, s.Form.Get(c.Verifier.Provider.Field), s.IP, s.Kind)
+	if errors.Is(err, captcha.ErrFailed) {
+		return true, nil
+	}
+	return false, err
+}
@@ -0,0 +1,24 @@
+// Copyright 2021 Harran Ali <harran.m@gmail.com>. All rights reserved.
+// Use of this source code is governed by MIT-style
+// license that can be found in the LICENSE file.
+
+package spam
+
+import (
+	"context"
+)
+
+// Honeypot flags the submissions filling a field hidden from humans, bots fill every field
+type Honeypot struct {
+	Field string
+}
+
+// Name returns the name of the checker
+func (h Honeypot) Name() string {
+	return "honeypot"
+}
+
+// IsSpam reports whether the hidden field was filled
+func (h Honeypot) IsSpam(ctx context.Context, s Submission) (bool, error) {
+	return s.Form.Get(h.Field) != "", nil
+}
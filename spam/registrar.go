@@ -0,0 +1,20 @@
+// Copyright 2021 Harran Ali <harran.m@gmail.com>. All rights reserved.
+// Use of this source code is governed by MIT-style
+// license that can be found in the LICENSE file.
+
+package spam
+
+import (
+	"os"
+)
+
+// RegisterCheckers registers the checkers run on the forms guarded by middlewares.SpamCheck
+func RegisterCheckers() {
+	if key := os.Getenv("AKISMET_KEY"); key != "" {
+		Register(Akismet{Key: key, Site: os.Getenv("APP_URL")})
+	}
+
+	// Register your checkers here
+	// Register(Honeypot{Field: "website"})
+	// Register(Captcha{Verifier: verifier}), routes guarded by middlewares.Captcha verify the token already
+}
@@ -0,0 +1,106 @@
+// Copyright 2021 Harran Ali <harran.m@gmail.com>. All rights reserved.
+// Use of this source code is governed by MIT-style
+// license that can be found in the LICENSE file.
+
+package spam
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Submission is a form submission to check
+type Submission struct {
+	// Kind is the kind of the form, e.g: "comment", "signup" or "contact-form"
+	Kind      string
+	IP        string
+	UserAgent string
+	Referrer  string
+	Author    string
+	Email     string
+	Content   string
+	// Form holds all the submitted fields, read by the honeypot and captcha checkers
+	Form url.Values
+}
+
+// Checker flags spam submissions
+type Checker interface {
+	Name() string
+	IsSpam(ctx context.Context, s Submission) (bool, error)
+}
+
+// FieldNames name the form fields holding the author, email and content of the submissions
+type FieldNames struct {
+	Author  string
+	Email   string
+	Content string
+}
+
+// Fields are the form fields FromRequest reads the author, email and content from
+var Fields = FieldNames{Author: "name", Email: "email", Content: "content"}
+
+// ErrSpam is returned when a checker flags the submission
+var ErrSpam = errors.New("the submission was flagged as spam")
+
+var mu sync.RWMutex
+var checkers []Checker
+
+// Register adds a checker run by Check
+func Register(c Checker) {
+	mu.Lock()
+	defer mu.Unlock()
+	checkers = append(checkers, c)
+}
+
+// Check runs the checkers in order, or the registered ones when none are given, and returns
+// ErrSpam once one flags the submission, the checkers failing to answer are logged and skipped
+// so an outage of a spam service doesn't block the forms
+func Check(ctx context.Context, s Submission, using ...Checker) error {
+	if len(using) == 0 {
+		mu.RLock()
+		using = append(using, checkers...)
+		mu.RUnlock()
+	}
+
+	for _, c := range using {
+		spam, err := c.IsSpam(ctx, s)
+		if err != nil {
+			log.Printf("spam check %s: %v", c.Name(), err)
+			continue
+		}
+		if spam {
+			return fmt.Errorf("%w by %s", ErrSpam, c.Name())
+		}
+	}
+	return nil
+}
+
+// FromRequest builds the submission of the form posted in the request
+func FromRequest(c *gin.Context, kind string) Submission {
+	// parses the urlencoded bodies as well
+	if err := c.Request.ParseMultipartForm(32 << 20); err != nil && !errors.Is(err, http.ErrNotMultipart) {
+		log.Printf("spam check: %v", err)
+	}
+	form := c.Request.PostForm
+	if form == nil {
+		form = url.Values{}
+	}
+
+	return Submission{
+		Kind:      kind,
+		IP:        c.ClientIP(),
+		UserAgent: c.Request.UserAgent(),
+		Referrer:  c.Request.Referer(),
+		Author:    form.Get(Fields.Author),
+		Email:     form.Get(Fields.Email),
+		Content:   form.Get(Fields.Content),
+		Form:      form,
+	}
+}
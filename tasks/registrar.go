@@ -0,0 +1,12 @@
+// Copyright 2021 Harran Ali <harran.m@gmail.com>. All rights reserved.
+// Use of this source code is governed by MIT-style
+// license that can be found in the LICENSE file.
+
+package tasks
+
+// RegisterTasks registers the tasks to run around the app's lifetime
+func RegisterTasks() {
+	// Register your tasks here, e.g:
+	// PreStart(Task{Name: "warm cache", Order: 10, Policy: Continue, Run: warmCache})
+	// PostStop(Task{Name: "flush queue", Timeout: 10 * time.Second, Run: flushQueue})
+}
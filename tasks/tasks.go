@@ -0,0 +1,105 @@
+// Copyright 2021 Harran Ali <harran.m@gmail.com>. All rights reserved.
+// Use of this source code is governed by MIT-style
+// license that can be found in the LICENSE file.
+
+package tasks
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"sort"
+	"syscall"
+	"time"
+)
+
+// Policy decides what happens when a task fails
+type Policy int
+
+const (
+	// Abort stops running the remaining tasks, a failing pre-start task stops the app
+	Abort Policy = iota
+	// Continue logs the error and moves on to the next task
+	Continue
+)
+
+// Task is a unit of work that runs before the app serves or after it stops
+type Task struct {
+	Name string
+	// Order sorts the tasks, lower runs first, tasks with the same order run in registration order
+	Order   int
+	Policy  Policy
+	Timeout time.Duration
+	Run     func(ctx context.Context) error
+}
+
+var preStart []Task
+var postStop []Task
+
+// PreStart registers a task to run before the app starts serving
+func PreStart(task Task) {
+	preStart = append(preStart, task)
+}
+
+// PostStop registers a task to run after the app receives a shutdown signal
+func PostStop(task Task) {
+	postStop = append(postStop, task)
+}
+
+// RunPreStart runs the pre-start tasks in order
+func RunPreStart() error {
+	return run("pre-start", preStart)
+}
+
+// RunPostStop runs the post-stop tasks in order
+func RunPostStop() error {
+	return run("post-stop", postStop)
+}
+
+// RunPostStopOnSignal runs the post-stop tasks when the process receives SIGINT or SIGTERM,
+// their result is sent on the returned channel so main can wait for them before returning,
+// a post-stop task stopping the servers lets the app's Run return, e.g:
+//
+//	done := tasks.RunPostStopOnSignal()
+//	app.Run(port)
+//	err := <-done
+func RunPostStopOnSignal() <-chan error {
+	done := make(chan error, 1)
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGINT, syscall.SIGTERM)
+	go func() {
+		<-signals
+		signal.Stop(signals)
+		done <- RunPostStop()
+	}()
+	return done
+}
+
+func run(stage string, tasks []Task) error {
+	sorted := make([]Task, len(tasks))
+	copy(sorted, tasks)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Order < sorted[j].Order
+	})
+
+	for _, task := range sorted {
+		ctx := context.Background()
+		cancel := func() {}
+		if task.Timeout > 0 {
+			ctx, cancel = context.WithTimeout(ctx, task.Timeout)
+		}
+		err := task.Run(ctx)
+		cancel()
+		if err == nil {
+			continue
+		}
+		err = fmt.Errorf("%s task %s: %w", stage, task.Name, err)
+		if task.Policy == Abort {
+			return err
+		}
+		log.Println(err)
+	}
+	return nil
+}
@@ -0,0 +1,11 @@
+// Copyright 2021 Harran Ali <harran.m@gmail.com>. All rights reserved.
+// Use of this source code is governed by MIT-style
+// license that can be found in the LICENSE file.
+
+package views
+
+// RegisterViews registers the app's derived tables
+func RegisterViews() {
+	// Register your views here, e.g:
+	// Register(View{Name: "post_stats", Query: "SELECT user_id, COUNT(*) AS posts FROM posts GROUP BY user_id", Sources: []string{"posts"}})
+}
@@ -0,0 +1,194 @@
+// Copyright 2021 Harran Ali <harran.m@gmail.com>. All rights reserved.
+// Use of this source code is governed by MIT-style
+// license that can be found in the LICENSE file.
+
+package views
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/gocondor/gocondor/models"
+	"gorm.io/gorm"
+)
+
+// View is a derived table rebuilt from a query, e.g:
+//
+//	views.Register(views.View{
+//		Name:    "post_stats",
+//		Query:   "SELECT user_id, COUNT(*) AS posts FROM posts GROUP BY user_id",
+//		Sources: []string{"posts"},
+//		Every:   time.Hour,
+//	})
+type View struct {
+	// Name is the name of the derived table
+	Name  string
+	Query string
+	// Sources are the tables the view is derived from, a captured change on them
+	// marks the view stale and refreshes it after Debounce
+	Sources  []string
+	Debounce time.Duration
+	// Every refreshes the view on an interval
+	Every time.Duration
+}
+
+// Status is the refresh state of a view
+type Status struct {
+	RefreshedAt     time.Time
+	RefreshDuration time.Duration
+	Stale           bool
+	StaleSince      time.Time
+	LastError       string
+}
+
+// ErrUnknownView is returned when refreshing a view that isn't registered
+var ErrUnknownView = errors.New("unknown view")
+
+// defaultDebounce groups the changes made in bursts into one refresh
+const defaultDebounce = time.Second
+
+type entry struct {
+	view    View
+	status  Status
+	pending bool
+	refresh sync.Mutex
+}
+
+var mu sync.Mutex
+var views = map[string]*entry{}
+var db *gorm.DB
+
+// Register registers a view
+func Register(v View) {
+	mu.Lock()
+	defer mu.Unlock()
+	if v.Debounce <= 0 {
+		v.Debounce = defaultDebounce
+	}
+	views[v.Name] = &entry{view: v}
+}
+
+// Refresh rebuilds a view in a transaction, readers see the old rows until it commits
+func Refresh(conn *gorm.DB, name string) error {
+	mu.Lock()
+	e, ok := views[name]
+	mu.Unlock()
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrUnknownView, name)
+	}
+
+	e.refresh.Lock()
+	defer e.refresh.Unlock()
+
+	started := time.Now()
+	err := conn.Transaction(func(tx *gorm.DB) error {
+		if !tx.Migrator().HasTable(e.view.Name) {
+			return tx.Exec("CREATE TABLE " + e.view.Name + " AS " + e.view.Query).Error
+		}
+		if err := tx.Exec("DELETE FROM " + e.view.Name).Error; err != nil {
+			return err
+		}
+		return tx.Exec("INSERT INTO " + e.view.Name + " " + e.view.Query).Error
+	})
+
+	mu.Lock()
+	defer mu.Unlock()
+	if err != nil {
+		e.status.LastError = err.Error()
+		return err
+	}
+	e.status = Status{RefreshedAt: started, RefreshDuration: time.Since(started)}
+	return nil
+}
+
+// RefreshAll rebuilds all views
+func RefreshAll(conn *gorm.DB) error {
+	for _, name := range Names() {
+		if err := Refresh(conn, name); err != nil {
+			return fmt.Errorf("view %s: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// Names returns the names of the registered views
+func Names() []string {
+	mu.Lock()
+	defer mu.Unlock()
+	names := make([]string, 0, len(views))
+	for name := range views {
+		names = append(names, name)
+	}
+	return names
+}
+
+// Statuses returns the refresh state of the views by name
+func Statuses() map[string]Status {
+	mu.Lock()
+	defer mu.Unlock()
+	statuses := make(map[string]Status, len(views))
+	for name, e := range views {
+		statuses[name] = e.status
+	}
+	return statuses
+}
+
+// Start refreshes the views on their interval and on the changes of their sources
+func Start(conn *gorm.DB) {
+	mu.Lock()
+	db = conn
+	for _, e := range views {
+		if e.view.Every > 0 {
+			go schedule(e.view)
+		}
+	}
+	mu.Unlock()
+
+	models.OnChange(markStale)
+}
+
+func schedule(v View) {
+	ticker := time.NewTicker(v.Every)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		if err := Refresh(db, v.Name); err != nil {
+			log.Printf("views: refreshing %s failed: %v", v.Name, err)
+		}
+	}
+}
+
+// markStale marks the views derived from the changed table stale and schedules their refresh
+func markStale(change models.Change) {
+	mu.Lock()
+	defer mu.Unlock()
+	for _, e := range views {
+		for _, source := range e.view.Sources {
+			if source != change.Table {
+				continue
+			}
+			if !e.status.Stale {
+				e.status.Stale = true
+				e.status.StaleSince = time.Now()
+			}
+			if !e.pending {
+				e.pending = true
+				// the change isn't committed yet, refresh once the burst is over
+				time.AfterFunc(e.view.Debounce, func(name string, e *entry) func() {
+					return func() {
+						mu.Lock()
+						e.pending = false
+						mu.Unlock()
+						if err := Refresh(db, name); err != nil {
+							log.Printf("views: refreshing %s failed: %v", name, err)
+						}
+					}
+				}(e.view.Name, e))
+			}
+			break
+		}
+	}
+}
@@ -0,0 +1,11 @@
+// Copyright 2021 Harran Ali <harran.m@gmail.com>. All rights reserved.
+// Use of this source code is governed by MIT-style
+// license that can be found in the LICENSE file.
+
+package warmup
+
+// RegisterWarmers registers the warmers to run on start
+func RegisterWarmers() {
+	// Register your warmers here, e.g:
+	// Register(ProductsCache{})
+}
@@ -0,0 +1,87 @@
+// Copyright 2021 Harran Ali <harran.m@gmail.com>. All rights reserved.
+// Use of this source code is governed by MIT-style
+// license that can be found in the LICENSE file.
+
+package warmup
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+)
+
+// Warmer fills a cache or opens connections ahead of the first requests
+type Warmer interface {
+	Name() string
+	Warm(ctx context.Context) error
+}
+
+var warmers []Warmer
+var ready = make(chan struct{})
+var once sync.Once
+
+// Register adds a warmer to run on start
+func Register(w Warmer) {
+	warmers = append(warmers, w)
+}
+
+// Start runs all warmers concurrently in the background, the app is marked ready
+// once they all return or the timeout passes, whichever comes first
+func Start(timeout time.Duration) {
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), timeout)
+		defer cancel()
+		Run(ctx)
+		markReady()
+	}()
+}
+
+// Run runs all warmers concurrently and waits for them or for the context to be done
+func Run(ctx context.Context) {
+	var wg sync.WaitGroup
+	for _, w := range warmers {
+		wg.Add(1)
+		go func(w Warmer) {
+			defer wg.Done()
+			started := time.Now()
+			if err := w.Warm(ctx); err != nil {
+				log.Printf("warmup %s: %v", w.Name(), err)
+				return
+			}
+			log.Printf("warmup %s: done in %s", w.Name(), time.Since(started))
+		}(w)
+	}
+
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-ctx.Done():
+		log.Println("warmup: timed out, serving with a partially warm cache")
+	}
+}
+
+// Ready reports whether warming up is over
+func Ready() bool {
+	select {
+	case <-ready:
+		return true
+	default:
+		return false
+	}
+}
+
+// Wait blocks until warming up is over
+func Wait() {
+	<-ready
+}
+
+func markReady() {
+	once.Do(func() {
+		close(ready)
+	})
+}
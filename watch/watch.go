@@ -0,0 +1,152 @@
+// Copyright 2021 Harran Ali <harran.m@gmail.com>. All rights reserved.
+// Use of this source code is governed by MIT-style
+// license that can be found in the LICENSE file.
+
+package watch
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"os/exec"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// ChildEnv is set in the env of the app spawned by the watcher, so it serves instead of watching
+const ChildEnv = "GOCONDOR_WATCH_CHILD"
+
+// how often the files are checked for changes
+const pollEvery = 500 * time.Millisecond
+
+// how long the spawned app gets to shut down before it's killed
+const stopTimeout = 10 * time.Second
+
+// the directories that aren't watched
+var skipDirs = map[string]bool{"vendor": true, "node_modules": true, "storage": true, "logs": true}
+
+// IsChild reports whether this process was spawned by the watcher
+func IsChild() bool {
+	return os.Getenv(ChildEnv) != ""
+}
+
+// Run builds and serves the app, and rebuilds and restarts it whenever a go file, go.mod
+// or .env changes, it returns once the watcher is interrupted
+func Run() error {
+	bin := filepath.Join(os.TempDir(), "gocondor-watch")
+	interrupted := make(chan os.Signal, 1)
+	signal.Notify(interrupted, os.Interrupt, syscall.SIGTERM)
+
+	var child *exec.Cmd
+	last := snapshot()
+	for {
+		if child == nil {
+			log.Println("watch: building")
+			if err := build(bin); err != nil {
+				log.Printf("watch: build failed, waiting for changes: %v", err)
+			} else {
+				child = start(bin)
+			}
+		}
+
+		select {
+		case <-interrupted:
+			stop(child)
+			return nil
+		case <-time.After(pollEvery):
+		}
+
+		current := snapshot()
+		if current == last {
+			continue
+		}
+		// wait for the editor to finish saving the files
+		for {
+			time.Sleep(pollEvery)
+			settled := snapshot()
+			if settled == current {
+				break
+			}
+			current = settled
+		}
+		last = current
+
+		log.Println("watch: changes detected, restarting")
+		stop(child)
+		child = nil
+	}
+}
+
+// build compiles the app to bin
+func build(bin string) error {
+	cmd := exec.Command("go", "build", "-o", bin, ".")
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	return cmd.Run()
+}
+
+// start spawns the built app, it returns nil when it can't be started
+func start(bin string) *exec.Cmd {
+	cmd := exec.Command(bin, os.Args[1:]...)
+	cmd.Env = append(os.Environ(), ChildEnv+"=1")
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Start(); err != nil {
+		log.Printf("watch: %v", err)
+		return nil
+	}
+	return cmd
+}
+
+// stop interrupts the spawned app so it shuts down gracefully, and kills it after stopTimeout
+func stop(cmd *exec.Cmd) {
+	if cmd == nil || cmd.Process == nil {
+		return
+	}
+
+	exited := make(chan struct{})
+	go func() {
+		cmd.Wait()
+		close(exited)
+	}()
+	// interrupting isn't supported on windows
+	if err := cmd.Process.Signal(os.Interrupt); err != nil {
+		cmd.Process.Kill()
+	}
+	select {
+	case <-exited:
+	case <-time.After(stopTimeout):
+		log.Println("watch: the app didn't stop in time, killing it")
+		cmd.Process.Kill()
+		<-exited
+	}
+}
+
+// snapshot sums up the watched files, it changes when a file is added, removed or modified
+func snapshot() string {
+	var latest time.Time
+	count := 0
+	filepath.Walk(".", func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return nil
+		}
+		if info.IsDir() {
+			if path != "." && (strings.HasPrefix(info.Name(), ".") || skipDirs[info.Name()]) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if strings.HasSuffix(path, ".go") || path == "go.mod" || path == ".env" {
+			count++
+			if info.ModTime().After(latest) {
+				latest = info.ModTime()
+			}
+		}
+		return nil
+	})
+	return fmt.Sprintf("%s/%d", latest, count)
+}